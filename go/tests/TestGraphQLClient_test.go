@@ -0,0 +1,619 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// TestGraphQLClient_test.go contains unit tests for the GraphQL client against
+// a local httptest server.
+
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/saichler/l8types/go/types/l8api"
+	"github.com/saichler/l8web/go/web/gclient"
+)
+
+func TestGraphQLClient_NestedAttribute(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":{"viewer":{"session":{"token":"abc"}}}}`))
+	}))
+	defer srv.Close()
+
+	gc, ok := createLocalGraphQLClient(t, srv.URL)
+	if !ok {
+		return
+	}
+
+	resp, err := gc.Query(`query { viewer { session { token } } }`, "", nil, "AuthToken", "viewer.session")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.(*l8api.AuthToken).Token != "abc" {
+		t.Fatalf("expected token 'abc', got %q", resp.(*l8api.AuthToken).Token)
+	}
+
+	_, err = gc.Query(`query { viewer { account { token } } }`, "", nil, "AuthToken", "viewer.account")
+	if err == nil || !strings.Contains(err.Error(), "'account'") {
+		t.Fatalf("expected error naming segment 'account', got %v", err)
+	}
+}
+
+func TestGraphQLClient_ProtoVariables(t *testing.T) {
+	var received map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		request := struct {
+			Variables map[string]interface{} `json:"variables"`
+		}{}
+		json.Unmarshal(body, &request)
+		received = request.Variables
+		w.Write([]byte(`{"data":{"session":{"token":"abc"}}}`))
+	}))
+	defer srv.Close()
+
+	gc, ok := createLocalGraphQLClient(t, srv.URL)
+	if !ok {
+		return
+	}
+
+	vars := &l8api.AuthToken{Token: "abc", NeedTfa: true}
+	_, err := gc.MutateProto(`mutation Renew($token: String!, $needTfa: Boolean) { session(token: $token, needTfa: $needTfa) { token } }`, "Renew",
+		vars, "AuthToken", "session")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if received["token"] != "abc" || received["needTfa"] != true {
+		t.Fatalf("expected camelCase variables token/needTfa, got %v", received)
+	}
+	if _, ok := received["need_tfa"]; ok {
+		t.Fatalf("unexpected proto field name in variables: %v", received)
+	}
+}
+
+func TestGraphQLClient_OperationName(t *testing.T) {
+	var received string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		request := map[string]interface{}{}
+		json.Unmarshal(body, &request)
+		name, ok := request["operationName"].(string)
+		if !ok {
+			w.Write([]byte(`{"errors":[{"message":"operationName required"}]}`))
+			return
+		}
+		received = name
+		w.Write([]byte(`{"data":{"session":{"token":"abc"}}}`))
+	}))
+	defer srv.Close()
+
+	gc, ok := createLocalGraphQLClient(t, srv.URL)
+	if !ok {
+		return
+	}
+
+	document := `query First { session { token } } query Second { session { token } }`
+	_, err := gc.Query(document, "Second", nil, "AuthToken", "session")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if received != "Second" {
+		t.Fatalf("expected operationName 'Second', got %q", received)
+	}
+
+	_, err = gc.Query(document, "", nil, "AuthToken", "session")
+	if err == nil {
+		t.Fatalf("expected operationName to be omitted when empty")
+	}
+}
+
+func TestGraphQLClient_QueryMethodGET(t *testing.T) {
+	var methods, operations []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		if r.Method == http.MethodGet {
+			operations = append(operations, r.URL.Query().Get("operationName"))
+			vars := map[string]interface{}{}
+			json.Unmarshal([]byte(r.URL.Query().Get("variables")), &vars)
+			if r.URL.Query().Get("query") == "" || vars["id"] != "u1" {
+				w.Write([]byte(`{"errors":[{"message":"missing query parameters"}]}`))
+				return
+			}
+		}
+		w.Write([]byte(`{"data":{"session":{"token":"abc"}}}`))
+	}))
+	defer srv.Close()
+
+	gc, ok := createLocalGraphQLClient(t, srv.URL)
+	if !ok {
+		return
+	}
+	gc.QueryMethod = "GET"
+
+	vars := map[string]interface{}{"id": "u1"}
+	if _, err := gc.Query(`query Get($id: ID!) { session(id: $id) { token } }`, "Get", vars, "AuthToken", "session"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := gc.Mutate(`mutation Renew { session { token } }`, "Renew", nil, "AuthToken", "session"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(methods, ",") != "GET,POST" {
+		t.Fatalf("expected query as GET and mutation as POST, got %v", methods)
+	}
+	if len(operations) != 1 || operations[0] != "Get" {
+		t.Fatalf("expected operationName 'Get' in URL, got %v", operations)
+	}
+}
+
+func TestGraphQLClient_Upload(t *testing.T) {
+	var operations, fileMap, content string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		operations = r.FormValue("operations")
+		fileMap = r.FormValue("map")
+		file, header, err := r.FormFile("0")
+		if err == nil {
+			data, _ := io.ReadAll(file)
+			content = header.Filename + ":" + string(data)
+		}
+		w.Write([]byte(`{"data":{"upload":{"token":"abc"}}}`))
+	}))
+	defer srv.Close()
+
+	gc, ok := createLocalGraphQLClient(t, srv.URL)
+	if !ok {
+		return
+	}
+
+	vars := map[string]interface{}{"input": map[string]interface{}{"name": "report"}}
+	files := map[string]io.Reader{"input.file": strings.NewReader("hello")}
+	resp, err := gc.Upload(`mutation ($input: UploadInput!) { upload(input: $input) { token } }`, vars, files, "AuthToken", "upload")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.(*l8api.AuthToken).Token != "abc" {
+		t.Fatalf("expected token 'abc', got %q", resp.(*l8api.AuthToken).Token)
+	}
+	if !strings.Contains(operations, `"input":{"file":null,"name":"report"}`) {
+		t.Fatalf("expected null file placeholder in operations, got %s", operations)
+	}
+	if fileMap != `{"0":["variables.input.file"]}` {
+		t.Fatalf("unexpected map part %s", fileMap)
+	}
+	if content != "input.file:hello" {
+		t.Fatalf("unexpected file part %q", content)
+	}
+
+	_, err = gc.Upload(`mutation { upload { token } }`, nil, map[string]io.Reader{"missing.file": strings.NewReader("x")}, "AuthToken", "upload")
+	if err == nil || !strings.Contains(err.Error(), "'file'") {
+		t.Fatalf("expected error naming segment 'file', got %v", err)
+	}
+}
+
+func TestGraphQLClient_ConnectionReuse(t *testing.T) {
+	var connections int32
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":{"session":{"token":"abc"}}}`))
+	}))
+	srv.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&connections, 1)
+		}
+	}
+	srv.Start()
+	defer srv.Close()
+
+	gc, ok := createLocalGraphQLClient(t, srv.URL)
+	if !ok {
+		return
+	}
+
+	for i := 0; i < 5; i++ {
+		if _, err := gc.Query(`query { session { token } }`, "", nil, "AuthToken", "session"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if n := atomic.LoadInt32(&connections); n != 1 {
+		t.Fatalf("expected sequential queries to reuse 1 connection, got %d", n)
+	}
+}
+
+func TestGraphQLClient_Transport(t *testing.T) {
+	gc, ok := createLocalGraphQLClient(t, "http://stub.local:80", func(config *gclient.GraphQLClientConfig) {
+		config.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			return stubResponse(r, http.StatusOK, `{"data":{"session":{"token":"abc"}}}`), nil
+		})
+	})
+	if !ok {
+		return
+	}
+
+	resp, err := gc.Query(`query { session { token } }`, "", nil, "AuthToken", "session")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.(*l8api.AuthToken).Token != "abc" {
+		t.Fatalf("unexpected response %v", resp)
+	}
+}
+
+func TestGraphQLClient_AuthPathNeedsExactMatch(t *testing.T) {
+	configure := func(authPath string) func(*gclient.GraphQLClientConfig) {
+		return func(config *gclient.GraphQLClientConfig) {
+			config.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+				return stubResponse(r, http.StatusOK, `{"data":{"session":{"token":"abc"}}}`), nil
+			})
+			config.Https = true
+			config.TokenRequired = true
+			config.AuthInfo = &gclient.GraphQLAuthInfo{AuthPath: authPath}
+		}
+	}
+	query := func(gc *gclient.GraphQLClient) func() {
+		return func() { gc.Query(`query { session { token } }`, "", nil, "AuthToken", "session") }
+	}
+
+	// An AuthPath merely ending with the endpoint doesn't exempt it.
+	gc, ok := createLocalGraphQLClient(t, "http://stub.local:80", configure("/api/v1/graphql"))
+	if !ok {
+		return
+	}
+	if !panics(query(gc)) {
+		t.Fatal("expected the endpoint to require a token")
+	}
+
+	gc, ok = createLocalGraphQLClient(t, "http://stub.local:80", configure("/graphql"))
+	if !ok {
+		return
+	}
+	if panics(query(gc)) {
+		t.Fatal("expected the auth path to need no token")
+	}
+}
+
+func TestGraphQLClient_Headers(t *testing.T) {
+	var headers http.Header
+	gc, ok := createLocalGraphQLClient(t, "http://stub.local:80", func(config *gclient.GraphQLClientConfig) {
+		config.Headers = map[string]string{"X-Tenant-ID": "acme", "Accept": "application/graphql-response+json"}
+		config.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			headers = r.Header
+			return stubResponse(r, http.StatusOK, `{"data":{"session":{"token":"abc"}}}`), nil
+		})
+	})
+	if !ok {
+		return
+	}
+
+	if _, err := gc.Query(`query { session { token } }`, "", nil, "AuthToken", "session"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if headers.Get("X-Tenant-ID") != "acme" {
+		t.Fatalf("expected the custom header, got %v", headers)
+	}
+	if accept := headers.Values("Accept"); len(accept) != 1 || accept[0] != "application/graphql-response+json" {
+		t.Fatalf("expected the custom Accept to replace the default, got %v", accept)
+	}
+	if headers.Get("User-Agent") != gclient.DefaultUserAgent {
+		t.Fatalf("expected the default User-Agent to be kept, got %q", headers.Get("User-Agent"))
+	}
+}
+
+func TestGraphQLClient_MaxResponseBytes(t *testing.T) {
+	body := `{"data":{"session":{"token":"` + strings.Repeat("a", 100) + `"}}}`
+	gc, ok := createLocalGraphQLClient(t, "http://stub.local:80", func(config *gclient.GraphQLClientConfig) {
+		config.MaxResponseBytes = int64(len(body)) - 1
+		config.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			return stubResponse(r, http.StatusOK, body), nil
+		})
+	})
+	if !ok {
+		return
+	}
+
+	_, err := gc.Query(`query { session { token } }`, "", nil, "AuthToken", "session")
+	if !errors.Is(err, gclient.ErrResponseTooLarge) {
+		t.Fatalf("expected ErrResponseTooLarge, got %v", err)
+	}
+	gc.MaxResponseBytes = int64(len(body))
+	if _, err = gc.Query(`query { session { token } }`, "", nil, "AuthToken", "session"); err != nil {
+		t.Fatalf("expected a response at the limit to be read, got %v", err)
+	}
+}
+
+func TestGraphQLClient_InsecureTLS(t *testing.T) {
+	config := &gclient.GraphQLClientConfig{Host: "127.0.0.1", Port: 443, Https: true}
+	if _, err := gclient.NewGraphQLClient(config, nil); !errors.Is(err, gclient.ErrInsecureTLS) {
+		t.Fatalf("expected ErrInsecureTLS without a CA, got %v", err)
+	}
+	config.AllowInsecure = true
+	if _, err := gclient.NewGraphQLClient(config, nil); err != nil {
+		t.Fatalf("expected AllowInsecure to opt into skipping verification, got %v", err)
+	}
+}
+
+func TestGraphQLClient_Cache(t *testing.T) {
+	var requests int32
+	gc, ok := createLocalGraphQLClient(t, "http://stub.local:80", func(config *gclient.GraphQLClientConfig) {
+		config.CacheTTL = time.Minute
+		config.CacheInvalidateOnMutate = true
+		config.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			n := atomic.AddInt32(&requests, 1)
+			return stubResponse(r, http.StatusOK, fmt.Sprintf(`{"data":{"session":{"token":"t%d"}}}`, n)), nil
+		})
+	})
+	if !ok {
+		return
+	}
+	query := `query Session($user: String!) { session(user: $user) { token } }`
+	get := func(user string) string {
+		resp, err := gc.Query(query, "", map[string]interface{}{"user": user}, "AuthToken", "session")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.(*l8api.AuthToken).Token
+	}
+
+	first := get("alice")
+	// Cached copies can't be modified through a returned message.
+	cached, _ := gc.Query(query, "", map[string]interface{}{"user": "alice"}, "AuthToken", "session")
+	cached.(*l8api.AuthToken).Token = "changed"
+	if get("alice") != first || atomic.LoadInt32(&requests) != 1 {
+		t.Fatalf("expected identical queries to be cached, got %d requests", requests)
+	}
+	if get("bob") == first || atomic.LoadInt32(&requests) != 2 {
+		t.Fatalf("expected other variables to miss the cache, got %d requests", requests)
+	}
+
+	if _, err := gc.Mutate(`mutation { session: renew { token } }`, "", nil, "AuthToken", "session"); err != nil {
+		t.Fatal(err)
+	}
+	if get("alice") == first || atomic.LoadInt32(&requests) != 4 {
+		t.Fatalf("expected a mutation to invalidate the cache, got %d requests", requests)
+	}
+}
+
+func TestGraphQLClient_Validate(t *testing.T) {
+	var extensions map[string]interface{}
+	gc, ok := createLocalGraphQLClient(t, "http://stub.local:80", func(config *gclient.GraphQLClientConfig) {
+		config.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			var body struct {
+				Extensions map[string]interface{} `json:"extensions"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			extensions = body.Extensions
+			return stubResponse(r, http.StatusOK, `{"errors":[{"message":"Cannot query field \"nme\" on type \"User\"."}]}`), nil
+		})
+	})
+	if !ok {
+		return
+	}
+
+	vars := map[string]interface{}{"id": "u1"}
+	valid := []string{
+		`query { users { id name } }`,
+		`{ users { id } }`,
+		`mutation Delete($id: ID!) { deleteUser(id: $id) { id } }`,
+		`mutation Create($id: ID!, $tags: [String!] = ["a"]) { createUser(input: {id: $id, tags: $tags, meta: {}}) { id } }`,
+		`query Q($id: ID!) { ...F user(id: $id) { note(text: "}{ \"quoted\"") } } # trailing }
+fragment F on Query { me { id } }`,
+	}
+	for _, query := range valid {
+		if err := gc.Validate(query, vars); err != nil {
+			t.Fatalf("expected %q to be valid, got %v", query, err)
+		}
+	}
+	if extensions != nil {
+		t.Fatal("expected client-side validation not to make a network call")
+	}
+
+	invalid := map[string]string{
+		"":                                        "empty query",
+		"  # just a comment":                      "only comments",
+		`query { users { id }`:                    "line 1, column 7: '{' is never closed",
+		`query { users { id ) }`:                  "expected '}'",
+		`query { users { } }`:                     "line 1, column 15: empty selection set",
+		`query {}`:                                "empty selection set",
+		`fragment F on User { id }`:               "no operation",
+		`query { user(name: "open) { id } }`:      "unterminated string",
+		`query { user(id: $id) { id } }`:          "variable $id is not declared",
+		`mutation D($name: String!) { d { id } }`: "required variable $name is not provided",
+		`users { id }`:                            "expected query, mutation",
+	}
+	for query, expected := range invalid {
+		err := gc.Validate(query, vars)
+		var validationErr *gclient.ValidationError
+		if !errors.As(err, &validationErr) || !strings.Contains(err.Error(), expected) {
+			t.Fatalf("expected %q to fail with %q, got %v", query, expected, err)
+		}
+	}
+
+	gc.ValidateExtension = "validateOnly"
+	err := gc.Validate(`query { users { nme } }`, nil)
+	if err == nil || !strings.Contains(err.Error(), `Cannot query field "nme"`) {
+		t.Fatalf("expected the server's validation errors, got %v", err)
+	}
+	if extensions["validateOnly"] != true {
+		t.Fatalf("expected the validation extension to be sent, got %v", extensions)
+	}
+}
+
+func TestGraphQLClient_RetryOnErrorCodes(t *testing.T) {
+	calls := 0
+	responses := []string{}
+	gc, ok := createLocalGraphQLClient(t, "http://stub.local:80", func(config *gclient.GraphQLClientConfig) {
+		config.RetryOnErrorCodes = []string{"THROTTLED", "GRAPHQL_VALIDATION_FAILED"}
+		config.ErrorRetryBackoff = time.Millisecond
+		config.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			body := responses[calls]
+			calls++
+			return stubResponse(r, http.StatusOK, body), nil
+		})
+	})
+	if !ok {
+		return
+	}
+
+	throttled := `{"errors":[{"message":"slow down","extensions":{"code":"THROTTLED"}}]}`
+	responses = []string{throttled, throttled, `{"data":{"session":{"token":"abc"}}}`}
+	resp, err := gc.Query(`query { session { token } }`, "", nil, "AuthToken", "session")
+	if err != nil || resp.(*l8api.AuthToken).Token != "abc" || calls != 3 {
+		t.Fatalf("expected success on the third attempt, got %v, %v after %d calls", resp, err, calls)
+	}
+
+	// Validation failures are permanent even when listed, and so are
+	// responses mixing a transient error with one of another code.
+	for _, body := range []string{
+		`{"errors":[{"message":"bad field","extensions":{"code":"GRAPHQL_VALIDATION_FAILED"}}]}`,
+		`{"errors":[{"message":"slow down","extensions":{"code":"THROTTLED"}},{"message":"boom"}]}`,
+	} {
+		calls, responses = 0, []string{body, body}
+		_, err = gc.Query(`query { session { token } }`, "", nil, "AuthToken", "session")
+		var gqlErrors gclient.GraphQLErrors
+		if !errors.As(err, &gqlErrors) || calls != 1 {
+			t.Fatalf("expected GraphQLErrors without a retry, got %v after %d calls", err, calls)
+		}
+	}
+}
+
+func TestGraphQLClient_Introspect(t *testing.T) {
+	body := `{"data":{"__schema":{"queryType":{"name":"Query"},"mutationType":null,"types":[
+		{"kind":"OBJECT","name":"Query","fields":[{"name":"users","args":[
+			{"name":"first","type":{"kind":"SCALAR","name":"Int"},"defaultValue":"10"}],
+			"type":{"kind":"NON_NULL","name":null,"ofType":{"kind":"LIST","name":null,"ofType":{"kind":"NON_NULL","name":null,"ofType":{"kind":"OBJECT","name":"User"}}}}}]},
+		{"kind":"OBJECT","name":"User","fields":[{"name":"id","args":[],"type":{"kind":"SCALAR","name":"ID"}}]}]}}}`
+	var query string
+	gc, ok := createLocalGraphQLClient(t, "http://stub.local:80", func(config *gclient.GraphQLClientConfig) {
+		config.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			request := &gclient.GraphQLRequest{}
+			json.NewDecoder(r.Body).Decode(request)
+			query = request.Query
+			return stubResponse(r, http.StatusOK, body), nil
+		})
+	})
+	if !ok {
+		return
+	}
+
+	if _, err := gc.Introspect(); !errors.Is(err, gclient.ErrIntrospectionDisabled) {
+		t.Fatalf("expected ErrIntrospectionDisabled, got %v", err)
+	}
+	if query != "" {
+		t.Fatal("expected no request while introspection is disabled")
+	}
+
+	gc.Introspection = true
+	schema, err := gc.Introspect()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(query, "__schema") || schema.QueryType.Name != "Query" || schema.MutationType != nil {
+		t.Fatalf("unexpected root types %+v for query %q", schema, query)
+	}
+	users := schema.Type("Query").Field("users")
+	if users == nil || users.Type.String() != "[User!]!" || users.Type.NamedType() != "User" {
+		t.Fatalf("unexpected users field %+v", users)
+	}
+	if users.Args[0].Name != "first" || *users.Args[0].DefaultValue != "10" {
+		t.Fatalf("unexpected users arguments %+v", users.Args)
+	}
+	if schema.Type("User").Field("id") == nil || schema.Type("Missing") != nil {
+		t.Fatal("unexpected type lookup results")
+	}
+
+	body = `{"errors":[{"message":"GraphQL introspection is not allowed","extensions":{"code":"GRAPHQL_VALIDATION_FAILED"}}]}`
+	if _, err = gc.Introspect(); !errors.Is(err, gclient.ErrIntrospectionUnavailable) || !strings.Contains(err.Error(), "not allowed") {
+		t.Fatalf("expected ErrIntrospectionUnavailable with the server's reason, got %v", err)
+	}
+}
+
+func TestGraphQLClient_Timeout(t *testing.T) {
+	hang := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		<-r.Context().Done()
+		return nil, r.Context().Err()
+	})
+	gc, ok := createLocalGraphQLClient(t, "http://stub.local:80", func(config *gclient.GraphQLClientConfig) {
+		config.Timeout = 50 * time.Millisecond
+		config.Transport = hang
+	})
+	if !ok {
+		return
+	}
+	start := time.Now()
+	if _, err := gc.Query(`query { session { token } }`, "", nil, "AuthToken", "session"); err == nil {
+		t.Fatal("expected the hung request to time out")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("expected the request to give up after Timeout, took %v", elapsed)
+	}
+
+	gc, ok = createLocalGraphQLClient(t, "http://stub.local:80", func(config *gclient.GraphQLClientConfig) {
+		config.Transport = hang
+	})
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := gc.MutateContext(ctx, `mutation { logout { token } }`, "", nil, "AuthToken", "logout"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the context deadline to abort the mutation, got %v", err)
+	}
+}
+
+func TestGraphQLClient_TokenStore(t *testing.T) {
+	store := newMapTokenStore()
+	calls := 0
+	gc, ok := createLocalGraphQLClient(t, "http://stub.local:80", func(config *gclient.GraphQLClientConfig) {
+		config.TokenRequired = true
+		config.TokenStore = store
+		config.CacheTTL = time.Minute
+		config.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			calls++
+			token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			return stubResponse(r, http.StatusOK, `{"data":{"session":{"token":"`+token+`"}}}`), nil
+		})
+	})
+	if !ok {
+		return
+	}
+
+	gc.ForTenant("acme").SetToken("acme-token")
+	gc.ForTenant("globex").SetToken("globex-token")
+	query := `query { session { token } }`
+	// The cache shared by tenant clients keeps their responses apart.
+	for _, tenant := range []string{"acme", "globex", "acme"} {
+		resp, err := gc.ForTenant(tenant).Query(query, "", nil, "AuthToken", "session")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.(*l8api.AuthToken).Token != tenant+"-token" {
+			t.Fatalf("expected the %s token, got %v", tenant, resp)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("expected the repeated acme query from the cache, got %d calls", calls)
+	}
+}
@@ -0,0 +1,56 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tests
+
+import (
+	"net/http"
+	"testing"
+
+	. "github.com/saichler/l8test/go/infra/t_resources"
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8types/go/types/l8api"
+	"github.com/saichler/l8web/go/web/client"
+	"github.com/saichler/l8web/go/web/server"
+	"github.com/saichler/l8web/go/web/webtest"
+)
+
+func TestHarness_RoundTrip(t *testing.T) {
+	resources, _ := CreateResources(VNET_PORT, 5, ifs.Info_Level)
+	resources.Registry().Register(&l8api.AuthToken{})
+
+	userAgent := ""
+	srv, restClient, cleanup := webtest.NewTestHarness(t, resources,
+		func(serverConfig *server.RestServerConfig, clientConfig *client.RestClientConfig) {
+			clientConfig.UserAgent = "harness-test"
+		})
+	defer cleanup()
+
+	srv.RegisterHandler("token", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.UserAgent()
+		w.Write([]byte(`{"token":"abc"}`))
+	}))
+
+	resp, err := restClient.GET("token", "AuthToken", "", "", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.(*l8api.AuthToken).Token != "abc" {
+		t.Fatalf("unexpected response %v", resp)
+	}
+	if userAgent != "harness-test" {
+		t.Fatalf("expected the client option to apply, got user agent %q", userAgent)
+	}
+}
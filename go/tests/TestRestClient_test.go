@@ -0,0 +1,990 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// TestRestClient_test.go contains unit tests for the REST client against a
+// local httptest server, without a VNet or service plugins.
+
+package tests
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saichler/l8types/go/types/l8api"
+	"github.com/saichler/l8web/go/web/client"
+)
+
+func TestRestClient_PostAs(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.Write([]byte(`{"token":"abc","needTfa":true}`))
+	}))
+	defer srv.Close()
+
+	rc, ok := createLocalRestClient(t, srv.URL)
+	if !ok {
+		return
+	}
+
+	token, err := client.PostAs[*l8api.AuthToken](rc, "/auth", "", "", &l8api.AuthUser{User: "admin"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token.Token != "abc" || !token.NeedTfa {
+		t.Fatalf("unexpected token: %v", token)
+	}
+}
+
+func TestRestClient_GetAsAttribute(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`"abc"`))
+	}))
+	defer srv.Close()
+
+	rc, ok := createLocalRestClient(t, srv.URL)
+	if !ok {
+		return
+	}
+
+	token, err := client.GetAs[*l8api.AuthToken](rc, "/token", "token", "", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token.Token != "abc" {
+		t.Fatalf("expected token 'abc', got %q", token.Token)
+	}
+}
+
+func TestRestClient_GetAsError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	rc, ok := createLocalRestClient(t, srv.URL)
+	if !ok {
+		return
+	}
+
+	_, err := client.GetAs[*l8api.AuthToken](rc, "/missing", "", "", nil)
+	if err == nil {
+		t.Fatal("expected error for 404 response")
+	}
+}
+
+func TestRestClient_NestedAttribute(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"session":{"current":"abc"}}`))
+	}))
+	defer srv.Close()
+
+	rc, ok := createLocalRestClient(t, srv.URL)
+	if !ok {
+		return
+	}
+
+	resp, err := rc.GET("/session", "AuthToken", "session.current.token", "", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.(*l8api.AuthToken).Token != "abc" {
+		t.Fatalf("expected token 'abc', got %q", resp.(*l8api.AuthToken).Token)
+	}
+
+	_, err = rc.GET("/session", "AuthToken", "session.previous.token", "", nil)
+	if err == nil || !strings.Contains(err.Error(), "'previous'") {
+		t.Fatalf("expected error naming segment 'previous', got %v", err)
+	}
+}
+
+func TestRestClient_DoBatch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{"token":"` + strings.TrimPrefix(r.URL.Path, "/") + `"}`))
+	}))
+	defer srv.Close()
+
+	rc, ok := createLocalRestClient(t, srv.URL)
+	if !ok {
+		return
+	}
+	rc.BatchWorkers = 2
+
+	reqs := []client.BatchRequest{
+		{Method: "GET", End: "/a", ResponseType: "AuthToken"},
+		{Method: "GET", End: "/fail", ResponseType: "AuthToken"},
+		{Method: "GET", End: "/b", ResponseType: "AuthToken"},
+		{Method: "GET", End: "/c", ResponseType: "AuthToken"},
+	}
+	results := rc.DoBatch(reqs)
+	if len(results) != len(reqs) {
+		t.Fatalf("expected %d results, got %d", len(reqs), len(results))
+	}
+	if results[1].Err == nil {
+		t.Fatal("expected error for failed request")
+	}
+	for i, expected := range map[int]string{0: "a", 2: "b", 3: "c"} {
+		if results[i].Err != nil {
+			t.Fatalf("unexpected error for request %d: %v", i, results[i].Err)
+		}
+		if results[i].Response.(*l8api.AuthToken).Token != expected {
+			t.Fatalf("expected token %q for request %d, got %q", expected, i, results[i].Response.(*l8api.AuthToken).Token)
+		}
+	}
+}
+
+func TestRestClient_UnmarshalError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"token":"abc","bogus":1}`))
+	}))
+	defer srv.Close()
+
+	rc, ok := createLocalRestClient(t, srv.URL)
+	if !ok {
+		return
+	}
+
+	_, err := rc.GET("/token", "AuthToken", "", "", nil)
+	var ue *client.UnmarshalError
+	if !errors.As(err, &ue) {
+		t.Fatalf("expected *client.UnmarshalError, got %v", err)
+	}
+	if !strings.HasSuffix(ue.TypeName, "AuthToken") {
+		t.Fatalf("expected type AuthToken, got %q", ue.TypeName)
+	}
+	if ue.Field != "bogus" {
+		t.Fatalf("expected field 'bogus', got %q", ue.Field)
+	}
+	if !strings.Contains(ue.Snippet, "bogus") {
+		t.Fatalf("expected snippet to contain 'bogus', got %q", ue.Snippet)
+	}
+}
+
+func TestRestClient_CustomAuthPath(t *testing.T) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Write([]byte(`{"token":"abc"}`))
+	}))
+	defer srv.Close()
+
+	cases := []struct {
+		prefix   string
+		authPath string
+		expected []string
+	}{
+		{"/api/v1/", "/api/v1/login", []string{"/api/v1/login", "/api/v1/users"}},
+		{"", "/login", []string{"/login", "/users"}},
+		{"/api/v1/", "/auth", []string{"/auth", "/api/v1/users"}},
+	}
+
+	for _, c := range cases {
+		paths = nil
+		rc, ok := createLocalRestClient(t, srv.URL)
+		if !ok {
+			return
+		}
+		rc.Prefix = c.prefix
+		rc.AuthInfo = &client.RestAuthInfo{
+			NeedAuth:   true,
+			BodyType:   "AuthUser",
+			UserField:  "User",
+			PassField:  "Pass",
+			RespType:   "AuthToken",
+			TokenField: "Token",
+			AuthPath:   c.authPath,
+		}
+		if err := rc.Auth("admin", "admin"); err != nil {
+			t.Fatalf("unexpected auth error: %v", err)
+		}
+		users := "users"
+		if c.prefix == "" {
+			users = "/users"
+		}
+		if _, err := rc.GET(users, "AuthToken", "", "", nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if strings.Join(paths, ",") != strings.Join(c.expected, ",") {
+			t.Fatalf("prefix %q auth %q: expected paths %v, got %v", c.prefix, c.authPath, c.expected, paths)
+		}
+	}
+}
+
+func TestRestClient_AuthPathNeedsExactMatch(t *testing.T) {
+	rc, ok := createLocalRestClient(t, "http://stub.local:80", func(config *client.RestClientConfig) {
+		config.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			return stubResponse(r, http.StatusOK, `{"token":"abc"}`), nil
+		})
+		config.Prefix = "/api/v1/"
+		config.TokenRequired = true
+		config.AuthInfo = &client.RestAuthInfo{AuthPath: "/api/v1/login"}
+	})
+	if !ok {
+		return
+	}
+	rc.Https = true
+
+	// Only the verbatim AuthPath is exempt from the token requirement.
+	for _, end := range []string{"", "/login", "login", "n"} {
+		if !panics(func() { rc.GET(end, "AuthToken", "", "", nil) }) {
+			t.Fatalf("expected %q to require a token", end)
+		}
+	}
+	if panics(func() { rc.POST("/api/v1/login", "AuthToken", "", "", nil) }) {
+		t.Fatal("expected the auth path to need no token")
+	}
+}
+
+func TestRestClient_TokenExpiryRefresh(t *testing.T) {
+	var exp int64
+	authCalls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/auth" {
+			authCalls++
+			payload := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"exp":%d}`, exp)))
+			w.Write([]byte(`{"token":"header.` + payload + `.signature"}`))
+			return
+		}
+		w.Write([]byte(`{"token":"abc"}`))
+	}))
+	defer srv.Close()
+
+	rc, ok := createLocalRestClient(t, srv.URL)
+	if !ok {
+		return
+	}
+	rc.AuthInfo = &client.RestAuthInfo{
+		NeedAuth:   true,
+		BodyType:   "AuthUser",
+		UserField:  "User",
+		PassField:  "Pass",
+		RespType:   "AuthToken",
+		TokenField: "Token",
+		AuthPath:   "/auth",
+	}
+
+	// A token expiring within TokenRefreshMargin is renewed before the next request
+	exp = time.Now().Add(10 * time.Second).Unix()
+	if err := rc.Auth("admin", "admin"); err != nil {
+		t.Fatalf("unexpected auth error: %v", err)
+	}
+	if rc.TokenExpiry().Unix() != exp {
+		t.Fatalf("expected expiry %d from JWT exp claim, got %d", exp, rc.TokenExpiry().Unix())
+	}
+	exp = time.Now().Add(time.Hour).Unix()
+	if _, err := rc.GET("/users", "AuthToken", "", "", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if authCalls != 2 || rc.TokenExpiry().Unix() != exp {
+		t.Fatalf("expected proactive re-auth, got %d auth calls and expiry %v", authCalls, rc.TokenExpiry())
+	}
+
+	// A token far from expiry is reused
+	if _, err := rc.GET("/users", "AuthToken", "", "", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if authCalls != 2 {
+		t.Fatalf("expected no re-auth for a valid token, got %d auth calls", authCalls)
+	}
+}
+
+func TestRestClient_ConcurrentSetToken(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"token":"abc"}`))
+	}))
+	defer srv.Close()
+
+	rc, ok := createLocalRestClient(t, srv.URL)
+	if !ok {
+		return
+	}
+	rc.TokenRequired = true
+
+	done := make(chan bool)
+	go func() {
+		for i := 0; i < 50; i++ {
+			rc.SetToken(fmt.Sprintf("token-%d", i))
+		}
+		done <- true
+	}()
+	for i := 0; i < 20; i++ {
+		if _, err := rc.GET("/users", "AuthToken", "", "", nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	<-done
+	if rc.Token() != "token-49" {
+		t.Fatalf("expected last token 'token-49', got %q", rc.Token())
+	}
+}
+
+func TestRestClient_UserAgent(t *testing.T) {
+	var agent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		agent = r.UserAgent()
+		w.Write([]byte(`{"token":"abc"}`))
+	}))
+	defer srv.Close()
+
+	rc, ok := createLocalRestClient(t, srv.URL)
+	if !ok {
+		return
+	}
+	if _, err := rc.GET("/users", "AuthToken", "", "", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if agent != client.DefaultUserAgent {
+		t.Fatalf("expected default User-Agent %q, got %q", client.DefaultUserAgent, agent)
+	}
+
+	rc.UserAgent = "inventory-sync/2.3"
+	if _, err := rc.GET("/users", "AuthToken", "", "", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if agent != "inventory-sync/2.3" {
+		t.Fatalf("expected configured User-Agent, got %q", agent)
+	}
+}
+
+func TestRestClient_CookieJar(t *testing.T) {
+	var cookies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie("bToken")
+		if err == nil {
+			cookies = append(cookies, cookie.Value)
+		} else {
+			cookies = append(cookies, "")
+		}
+		http.SetCookie(w, &http.Cookie{Name: "bToken", Value: "session", Path: "/", HttpOnly: true})
+		w.Write([]byte(`{"token":"abc"}`))
+	}))
+	defer srv.Close()
+
+	rc, ok := createLocalRestClient(t, srv.URL)
+	if !ok {
+		return
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := rc.GET("/users", "AuthToken", "", "", nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if strings.Join(cookies, ",") != "," {
+		t.Fatalf("expected no cookies without a jar, got %v", cookies)
+	}
+
+	cookies = nil
+	jar, _ := cookiejar.New(nil)
+	rc, ok = createLocalRestClient(t, srv.URL, func(config *client.RestClientConfig) {
+		config.CookieJar = jar
+	})
+	if !ok {
+		return
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := rc.GET("/users", "AuthToken", "", "", nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if strings.Join(cookies, ",") != ",session" {
+		t.Fatalf("expected the jar to resend the session cookie, got %v", cookies)
+	}
+}
+
+func TestRestClient_Transport(t *testing.T) {
+	rc, ok := createLocalRestClient(t, "http://stub.local:80", func(config *client.RestClientConfig) {
+		config.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			if r.URL.Host != "stub.local:80" || r.URL.Path != "/auth" {
+				return stubResponse(r, http.StatusNotFound, ""), nil
+			}
+			if r.Header.Get("Authorization") == "" {
+				return stubResponse(r, http.StatusUnauthorized, `{"error":{"code":401}}`), nil
+			}
+			return stubResponse(r, http.StatusOK, `{"token":"abc"}`), nil
+		})
+	})
+	if !ok {
+		return
+	}
+
+	_, err := rc.POST("/auth", "AuthToken", "", "", &l8api.AuthUser{User: "admin"})
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("expected the stubbed 401, got %v", err)
+	}
+
+	rc.TokenRequired = true
+	rc.SetToken("t1")
+	resp, err := rc.POST("/auth", "AuthToken", "", "", &l8api.AuthUser{User: "admin"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.(*l8api.AuthToken).Token != "abc" {
+		t.Fatalf("unexpected response %v", resp)
+	}
+}
+
+func TestRestClient_MaxResponseBytes(t *testing.T) {
+	body := `{"token":"` + strings.Repeat("a", 100) + `"}`
+	compressed := bytes.Buffer{}
+	gz := gzip.NewWriter(&compressed)
+	gz.Write([]byte(body))
+	gz.Close()
+
+	rc, ok := createLocalRestClient(t, "http://stub.local:80", func(config *client.RestClientConfig) {
+		config.MaxResponseBytes = int64(len(body))
+		config.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			switch r.URL.Path {
+			case "/gzip":
+				resp := stubResponse(r, http.StatusOK, compressed.String())
+				resp.Header.Set("Content-Encoding", "gzip")
+				return resp, nil
+			case "/large":
+				return stubResponse(r, http.StatusOK, body+" "), nil
+			}
+			return stubResponse(r, http.StatusOK, body), nil
+		})
+	})
+	if !ok {
+		return
+	}
+
+	for _, path := range []string{"/exact", "/gzip"} {
+		if _, err := rc.GET(path, "AuthToken", "", "", nil); err != nil {
+			t.Fatalf("expected %s at the limit to be read, got %v", path, err)
+		}
+	}
+
+	rc.MaxResponseBytes = int64(len(body)) - 1
+	for _, path := range []string{"/exact", "/gzip"} {
+		if _, err := rc.GET(path, "AuthToken", "", "", nil); !errors.Is(err, client.ErrResponseTooLarge) {
+			t.Fatalf("expected ErrResponseTooLarge for %s, got %v", path, err)
+		}
+	}
+
+	rc.MaxResponseBytes = -1
+	if _, err := rc.GET("/large", "AuthToken", "", "", nil); err != nil {
+		t.Fatalf("expected no limit, got %v", err)
+	}
+}
+
+func TestRestClient_NoContent(t *testing.T) {
+	rc, ok := createLocalRestClient(t, "http://stub.local:80", func(config *client.RestClientConfig) {
+		config.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			switch r.URL.Path {
+			case "/cached":
+				return stubResponse(r, http.StatusNotModified, ""), nil
+			case "/noisy":
+				// A body on a 204 is ignored rather than decoded.
+				return stubResponse(r, http.StatusNoContent, "not json"), nil
+			}
+			return stubResponse(r, http.StatusNoContent, ""), nil
+		})
+	})
+	if !ok {
+		return
+	}
+
+	for _, path := range []string{"/users", "/cached", "/noisy"} {
+		resp, err := rc.DELETE(path, "AuthToken", "", "", nil)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", path, err)
+		}
+		if resp.(*l8api.AuthToken).Token != "" {
+			t.Fatalf("%s: expected an empty response, got %v", path, resp)
+		}
+	}
+	token, err := client.DeleteAs[*l8api.AuthToken](rc, "/users", "", "", nil)
+	if err != nil || token == nil {
+		t.Fatalf("expected an empty typed response, got %v, %v", token, err)
+	}
+}
+
+func TestRestClient_Redirects(t *testing.T) {
+	authorization := map[string]string{}
+	rc, ok := createLocalRestClient(t, "http://stub.local:80", func(config *client.RestClientConfig) {
+		config.TokenRequired = true
+		config.Token = "secret"
+		config.MaxRedirects = 3
+		config.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			redirect := map[string]string{
+				"/same":  "/target",
+				"/other": "http://other.local:80/target",
+				"/loop":  "/loop",
+			}[r.URL.Path]
+			if redirect == "" {
+				authorization[r.URL.Host] = r.Header.Get("Authorization")
+				return stubResponse(r, http.StatusOK, `{"token":"abc"}`), nil
+			}
+			resp := stubResponse(r, http.StatusFound, "")
+			resp.Header.Set("Location", redirect)
+			return resp, nil
+		})
+	})
+	if !ok {
+		return
+	}
+
+	if _, err := rc.GET("/same", "AuthToken", "", "", nil); err == nil || !strings.Contains(err.Error(), "302") {
+		t.Fatalf("expected the redirect not to be followed by default, got %v", err)
+	}
+
+	rc.FollowRedirects = true
+	if _, err := rc.GET("/same", "AuthToken", "", "", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if authorization["stub.local:80"] != "Bearer secret" {
+		t.Fatalf("expected the token on a same host redirect, got %q", authorization["stub.local:80"])
+	}
+	if _, err := rc.GET("/other", "AuthToken", "", "", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if header, ok := authorization["other.local:80"]; !ok || header != "" {
+		t.Fatalf("expected the token to be dropped on a cross host redirect, got %q", header)
+	}
+	if _, err := rc.GET("/loop", "AuthToken", "", "", nil); err == nil || !strings.Contains(err.Error(), "3 redirects") {
+		t.Fatalf("expected the redirect loop to stop, got %v", err)
+	}
+}
+
+func TestRestClient_CircuitBreaker(t *testing.T) {
+	attempts := 0
+	status := http.StatusServiceUnavailable
+	rc, ok := createLocalRestClient(t, "http://stub.local:80", func(config *client.RestClientConfig) {
+		config.BreakerThreshold = 2
+		config.BreakerCooldown = 50 * time.Millisecond
+		config.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			attempts++
+			return stubResponse(r, status, `{"token":"abc"}`), nil
+		})
+	})
+	if !ok {
+		return
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := rc.GET("/users", "AuthToken", "", "", nil); err == nil || errors.Is(err, client.ErrCircuitOpen) {
+			t.Fatalf("expected the backend error, got %v", err)
+		}
+	}
+	if _, err := rc.GET("/users", "AuthToken", "", "", nil); !errors.Is(err, client.ErrCircuitOpen) || attempts != 2 {
+		t.Fatalf("expected an open breaker after 2 failures, got %v after %d attempts", err, attempts)
+	}
+
+	// A failed trial after the cooldown opens the breaker again.
+	time.Sleep(60 * time.Millisecond)
+	if _, err := rc.GET("/users", "AuthToken", "", "", nil); err == nil || errors.Is(err, client.ErrCircuitOpen) {
+		t.Fatalf("expected the trial request to reach the backend, got %v", err)
+	}
+	if _, err := rc.GET("/users", "AuthToken", "", "", nil); !errors.Is(err, client.ErrCircuitOpen) || attempts != 3 {
+		t.Fatalf("expected the breaker to reopen, got %v after %d attempts", err, attempts)
+	}
+
+	// A successful trial closes it.
+	status = http.StatusOK
+	time.Sleep(60 * time.Millisecond)
+	for i := 0; i < 2; i++ {
+		if _, err := rc.GET("/users", "AuthToken", "", "", nil); err != nil {
+			t.Fatalf("expected the breaker to close, got %v", err)
+		}
+	}
+	if attempts != 5 {
+		t.Fatalf("expected 5 attempts, got %d", attempts)
+	}
+}
+
+func TestRestClient_BulkDeleteNotRetried(t *testing.T) {
+	attempts := 0
+	rc, ok := createLocalRestClient(t, "http://stub.local:80", func(config *client.RestClientConfig) {
+		config.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			attempts++
+			return nil, errors.New("i/o timeout")
+		})
+	})
+	if !ok {
+		return
+	}
+	if _, err := rc.DELETE("/events", "", "", "olderThan=30d", nil); err == nil || attempts != 1 {
+		t.Fatalf("expected a single attempt for a bulk delete, got %v after %d attempts", err, attempts)
+	}
+}
+
+func TestRestClient_RetryOnStatus(t *testing.T) {
+	attempts := map[string]int{}
+	rc, ok := createLocalRestClient(t, "http://stub.local:80", func(config *client.RestClientConfig) {
+		config.RetryOnStatus = []int{http.StatusServiceUnavailable}
+		config.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			attempts[r.URL.Path]++
+			if r.Method == http.MethodDelete {
+				return stubResponse(r, http.StatusServiceUnavailable, ""), nil
+			}
+			switch r.URL.Path {
+			case "/flaky":
+				if attempts[r.URL.Path] < 3 {
+					resp := stubResponse(r, http.StatusServiceUnavailable, "")
+					resp.Header.Set("Retry-After", "0")
+					return resp, nil
+				}
+				return stubResponse(r, http.StatusOK, ""), nil
+			case "/down":
+				resp := stubResponse(r, http.StatusServiceUnavailable, "")
+				resp.Header.Set("Retry-After", "0")
+				return resp, nil
+			}
+			return stubResponse(r, http.StatusNotFound, ""), nil
+		})
+	})
+	if !ok {
+		return
+	}
+	if _, err := rc.GET("/flaky", "", "", "", nil); err != nil || attempts["/flaky"] != 3 {
+		t.Fatalf("expected success on the third attempt, got %v after %d attempts", err, attempts["/flaky"])
+	}
+	if _, err := rc.GET("/down", "", "", "", nil); err == nil || attempts["/down"] != 6 {
+		t.Fatalf("expected a failure after 6 attempts, got %v after %d attempts", err, attempts["/down"])
+	}
+	if _, err := rc.GET("/missing", "", "", "", nil); err == nil || attempts["/missing"] != 1 {
+		t.Fatalf("expected a 404 to fail without retry, got %v after %d attempts", err, attempts["/missing"])
+	}
+	if _, err := rc.DELETE("/events", "", "", "?olderThan=30d", nil); err == nil || attempts["/events"] != 1 {
+		t.Fatalf("expected a single attempt for a bulk delete, got %v after %d attempts", err, attempts["/events"])
+	}
+}
+
+func TestRestClient_Download(t *testing.T) {
+	body := strings.Repeat("0123456789", 1000)
+	compressed := bytes.Buffer{}
+	gz := gzip.NewWriter(&compressed)
+	gz.Write([]byte(body))
+	gz.Close()
+
+	rc, ok := createLocalRestClient(t, "http://stub.local:80", func(config *client.RestClientConfig) {
+		// Downloads are not bound by the response size limit.
+		config.MaxResponseBytes = 10
+		config.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			switch r.URL.Path {
+			case "/export":
+				return stubResponse(r, http.StatusOK, body), nil
+			case "/export.gz":
+				resp := stubResponse(r, http.StatusOK, compressed.String())
+				resp.Header.Set("Content-Encoding", "gzip")
+				return resp, nil
+			case "/empty":
+				return stubResponse(r, http.StatusNoContent, ""), nil
+			}
+			return stubResponse(r, http.StatusNotFound, "missing"), nil
+		})
+	})
+	if !ok {
+		return
+	}
+
+	for _, path := range []string{"/export", "/export.gz"} {
+		dst := bytes.Buffer{}
+		n, err := rc.Download(path, "", &dst)
+		if err != nil || n != int64(len(body)) || dst.String() != body {
+			t.Fatalf("expected %s to stream %d bytes, got %d bytes, %v", path, len(body), n, err)
+		}
+	}
+
+	dst := bytes.Buffer{}
+	if n, err := rc.Download("/empty", "", &dst); err != nil || n != 0 {
+		t.Fatalf("expected an empty download, got %d bytes, %v", n, err)
+	}
+	if _, err := rc.Download("/nothing", "", &dst); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Fatalf("expected a 404 error, got %v", err)
+	}
+}
+
+func TestRestClient_Upload(t *testing.T) {
+	payload := strings.Repeat("binary", 1000)
+	var gotType, gotBody string
+	var gotLength int64
+	rc, ok := createLocalRestClient(t, "http://stub.local:80", func(config *client.RestClientConfig) {
+		config.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			if r.URL.Path == "/fail" {
+				return stubResponse(r, http.StatusBadRequest, "bad file"), nil
+			}
+			data, _ := io.ReadAll(r.Body)
+			gotType, gotBody, gotLength = r.Header.Get("Content-Type"), string(data), r.ContentLength
+			return stubResponse(r, http.StatusOK, `{"token":"stored"}`), nil
+		})
+	})
+	if !ok {
+		return
+	}
+
+	reader := io.MultiReader(strings.NewReader(payload[:10]), strings.NewReader(payload[10:]))
+	resp, err := rc.Upload(http.MethodPost, "/files", reader, "", "AuthToken", "")
+	if err != nil {
+		t.Fatalf("upload failed: %v", err)
+	}
+	if gotBody != payload || gotType != "application/octet-stream" {
+		t.Fatalf("unexpected upload: %d bytes, type %q", len(gotBody), gotType)
+	}
+	if resp.(*l8api.AuthToken).Token != "stored" {
+		t.Fatalf("expected the response to be decoded, got %v", resp)
+	}
+
+	if _, err := rc.Upload(http.MethodPut, "/files", strings.NewReader(payload), "text/csv", "", ""); err != nil {
+		t.Fatalf("upload failed: %v", err)
+	}
+	if gotType != "text/csv" || gotLength != int64(len(payload)) {
+		t.Fatalf("expected a text/csv upload of %d bytes, got type %q, length %d", len(payload), gotType, gotLength)
+	}
+
+	if _, err := rc.Upload(http.MethodPost, "/fail", strings.NewReader(payload), "", "", ""); err == nil || !strings.Contains(err.Error(), "bad file") {
+		t.Fatalf("expected the 400 body in the error, got %v", err)
+	}
+}
+
+func TestRestClient_OnTrace(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"token":"abc"}`))
+	}))
+	defer srv.Close()
+
+	var traces []client.RequestTrace
+	rc, ok := createLocalRestClient(t, srv.URL, func(config *client.RestClientConfig) {
+		config.Https = true
+		config.AllowInsecure = true
+		config.OnTrace = func(trace client.RequestTrace) {
+			traces = append(traces, trace)
+		}
+	})
+	if !ok {
+		return
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := rc.GET("/token", "AuthToken", "", "", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(traces) != 2 {
+		t.Fatalf("expected a trace per request, got %d", len(traces))
+	}
+	first, second := traces[0], traces[1]
+	if first.Method != http.MethodGet || first.Status != http.StatusOK || first.Err != nil {
+		t.Fatalf("unexpected trace %+v", first)
+	}
+	if first.Reused || first.Connect <= 0 || first.TLSHandshake <= 0 || first.FirstByte <= 0 || first.Total < first.FirstByte {
+		t.Fatalf("expected the first request to connect and handshake, got %+v", first)
+	}
+	if !second.Reused || second.Connect != 0 || second.TLSHandshake != 0 {
+		t.Fatalf("expected the second request to reuse the connection, got %+v", second)
+	}
+}
+
+func TestRestClient_InsecureTLS(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	host, port, _ := net.SplitHostPort(srv.Listener.Addr().String())
+	portNum, _ := strconv.Atoi(port)
+	config := &client.RestClientConfig{Host: host, Port: portNum, Https: true, AuthInfo: &client.RestAuthInfo{}}
+	if _, err := client.NewRestClient(config, nil); !errors.Is(err, client.ErrInsecureTLS) {
+		t.Fatalf("expected ErrInsecureTLS without a CA, got %v", err)
+	}
+
+	config.CertFileName = filepath.Join(t.TempDir(), "ca.crt")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(config.CertFileName, caPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	rc, err := client.NewRestClient(config, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rc.GET("/verified", "", "", "", nil); err != nil {
+		t.Fatalf("expected the server certificate to verify against the CA, got %v", err)
+	}
+}
+
+func TestRestClient_TokenStore(t *testing.T) {
+	store := newMapTokenStore()
+	var authorization string
+	rc, ok := createLocalRestClient(t, "http://stub.local:80", func(config *client.RestClientConfig) {
+		config.TokenRequired = true
+		config.TokenStore = store
+		config.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			authorization = r.Header.Get("Authorization")
+			return stubResponse(r, http.StatusOK, ""), nil
+		})
+	})
+	if !ok {
+		return
+	}
+
+	acme, globex := rc.ForTenant("acme"), rc.ForTenant("globex")
+	acme.SetToken("acme-token")
+	globex.SetToken("globex-token")
+	if store.Get("acme") != "acme-token" || rc.Token() != "" || acme.Tenant() != "acme" {
+		t.Fatalf("expected the tokens in the store by tenant, got %v", store.tokens)
+	}
+	for _, tc := range []struct {
+		client   *client.RestClient
+		expected string
+	}{{acme, "Bearer acme-token"}, {globex, "Bearer globex-token"}, {rc.ForTenant("acme"), "Bearer acme-token"}} {
+		if _, err := tc.client.Do(http.MethodGet, "/users", "", "", "", nil, 1); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if authorization != tc.expected {
+			t.Fatalf("expected %q, got %q", tc.expected, authorization)
+		}
+	}
+}
+
+func TestRestClient_TFA(t *testing.T) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		switch r.URL.Path {
+		case "/auth":
+			w.Write([]byte(`{"needTfa":true,"setupTfa":true,"tokenHash":"h1"}`))
+		case "/tfaSetup":
+			w.Write([]byte(`{"secret":"S3CR3T","qr":"qr-data"}`))
+		case "/tfaSetupVerify", "/tfaVerify":
+			if strings.Contains(string(body), `"123456"`) {
+				w.Write([]byte(`{"ok":true,"token":"tfa-token"}`))
+				return
+			}
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer srv.Close()
+
+	rc, ok := createLocalRestClient(t, srv.URL)
+	if !ok {
+		return
+	}
+	rc.Prefix = "/api/"
+	rc.AuthInfo = &client.RestAuthInfo{
+		NeedAuth:   true,
+		BodyType:   "AuthUser",
+		UserField:  "User",
+		PassField:  "Pass",
+		RespType:   "AuthToken",
+		TokenField: "Token",
+		AuthPath:   "/auth",
+	}
+
+	err := rc.Auth("admin", "admin")
+	var tfa *client.TFARequiredError
+	if !errors.As(err, &tfa) || !tfa.Setup || tfa.User != "admin" || tfa.TokenHash != "h1" {
+		t.Fatalf("expected a TFA setup requirement, got %v", err)
+	}
+	if rc.Token() != "" {
+		t.Fatalf("expected no token before TFA, got %q", rc.Token())
+	}
+	setup, err := rc.TFASetup("admin")
+	if err != nil || setup.Secret != "S3CR3T" || setup.Qr != "qr-data" {
+		t.Fatalf("unexpected setup %v, %v", setup, err)
+	}
+	if err = rc.TFASetupVerify("admin", "000000"); !errors.Is(err, client.ErrTFAVerification) {
+		t.Fatalf("expected a rejected code, got %v", err)
+	}
+	if err = rc.TFAVerify("admin", "123456"); err != nil || rc.Token() != "tfa-token" {
+		t.Fatalf("expected the token after verification, got %q, %v", rc.Token(), err)
+	}
+	expected := "/auth,/tfaSetup,/tfaSetupVerify,/tfaVerify"
+	if strings.Join(paths, ",") != expected {
+		t.Fatalf("expected unprefixed TFA paths %s, got %v", expected, paths)
+	}
+}
+
+func TestRestClient_Register(t *testing.T) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		switch r.URL.Path {
+		case "/captcha":
+			w.Write([]byte(`{"captcha":"3 + 4"}`))
+		case "/register":
+			if !strings.Contains(string(body), `"7"`) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"error":{"code":401,"message":"invalid captcha"}}`))
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer srv.Close()
+
+	rc, ok := createLocalRestClient(t, srv.URL)
+	if !ok {
+		return
+	}
+	rc.Prefix = "/api/"
+
+	captcha, err := rc.GetCaptcha()
+	if err != nil || captcha != "3 + 4" {
+		t.Fatalf("unexpected captcha %q, %v", captcha, err)
+	}
+	err = rc.Register("alice", "secret", "8")
+	var regErr *client.RegistrationError
+	if !errors.As(err, &regErr) || regErr.Code != http.StatusUnauthorized || regErr.Message != "invalid captcha" {
+		t.Fatalf("expected a refused registration, got %v", err)
+	}
+	if err = rc.Register("alice", "secret", "7"); err != nil {
+		t.Fatalf("expected a registration, got %v", err)
+	}
+	expected := "/captcha,/register,/register"
+	if strings.Join(paths, ",") != expected {
+		t.Fatalf("expected unprefixed onboarding paths %s, got %v", expected, paths)
+	}
+}
+
+func TestRestClient_RegisterWithoutAuthInfo(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	// Onboarding runs before any auth exists, so it must not need AuthInfo.
+	rc, ok := createLocalRestClient(t, srv.URL, func(config *client.RestClientConfig) {
+		config.AuthInfo = nil
+	})
+	if !ok {
+		return
+	}
+	if err := rc.Register("alice", "secret", "7"); err != nil {
+		t.Fatalf("expected a registration without AuthInfo, got %v", err)
+	}
+}
@@ -91,7 +91,9 @@ func TestRestServer(t *testing.T) {
 
 	server.Target = serviceNic.Resources().SysConfig().LocalUuid
 
-	time.Sleep(time.Second)
+	if !waitForReady(t, svr) {
+		return
+	}
 
 	resp, err := restClient.POST("0/Tests", "TestProtoList", "", "", pb.(proto.Message))
 	if err != nil {
@@ -141,7 +143,9 @@ func TestRestServer2(t *testing.T) {
 		svr.Stop()
 	}()
 
-	time.Sleep(time.Second * 3)
+	if !waitForReady(t, svr) {
+		return
+	}
 
 	restClient, ok := createRestClient(t, pbList)
 	if !ok {
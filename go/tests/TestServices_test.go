@@ -0,0 +1,64 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// TestServices_test.go contains unit tests for the route list served by the
+// /services endpoint, without a VNet.
+
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/saichler/l8web/go/web/server"
+)
+
+func TestServices_ListsHandlers(t *testing.T) {
+	srv, err := server.NewRestServer(&server.RestServerConfig{
+		Prefix:      "/routes-test/",
+		CertDomain:  "cert",
+		CertPrivate: "key",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rs := srv.(*server.RestServer)
+	noop := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	rs.RegisterHandler("webhook/gitlab", noop)
+	rs.RegisterHandler("webhook/github", noop)
+
+	// /services marshals Routes as is.
+	byt, err := json.Marshal(server.Routes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	routes := []*server.RouteInfo{}
+	if err := json.Unmarshal(byt, &routes); err != nil {
+		t.Fatalf("invalid JSON %s: %v", byt, err)
+	}
+	paths := []string{}
+	for _, route := range routes {
+		if route.Path == "/routes-test/webhook/github" || route.Path == "/routes-test/webhook/gitlab" {
+			paths = append(paths, route.Path)
+			if route.ServiceName != "" || route.Auth {
+				t.Fatalf("unexpected service info for custom handler %+v", route)
+			}
+		}
+	}
+	if len(paths) != 2 || paths[0] != "/routes-test/webhook/github" {
+		t.Fatalf("expected both handlers sorted by path, got %v", paths)
+	}
+}
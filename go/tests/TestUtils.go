@@ -17,6 +17,10 @@
 //   - createWebServer: Creates a REST server with VNic for testing
 //   - createServiceNic: Creates a service VNic with plugin support
 //   - createRestClient: Creates a REST client configured for testing
+//   - createLocalRestClient: Creates a plain HTTP REST client for an httptest server
+//   - createLocalGraphQLClient: Creates a plain HTTP GraphQL client for an httptest server
+//   - roundTripFunc, stubResponse: In-process client transports without sockets
+//   - mapTokenStore: In-memory TokenStore for multi-tenant clients
 //   - PushPlugin: Loads a plugin file into a VNic
 
 package tests
@@ -24,7 +28,13 @@ package tests
 import (
 	"encoding/base64"
 	"github.com/saichler/l8utils/go/utils/ipsegment"
+	"io"
+	"net/http"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -36,6 +46,7 @@ import (
 	"github.com/saichler/l8types/go/types/l8health"
 	"github.com/saichler/l8types/go/types/l8web"
 	"github.com/saichler/l8web/go/web/client"
+	"github.com/saichler/l8web/go/web/gclient"
 	"github.com/saichler/l8web/go/web/server"
 )
 
@@ -78,6 +89,20 @@ func createWebServer(t *testing.T) (ifs.IVNic, ifs.IWebServer, bool) {
 	return webNic, srv, true
 }
 
+// waitForReady polls the server's Ready until it has discovered the overlay's
+// web services, instead of sleeping for a fixed time.
+func waitForReady(t *testing.T, svr ifs.IWebServer) bool {
+	rs := svr.(*server.RestServer)
+	for i := 0; i < 100; i++ {
+		if rs.Ready() {
+			return true
+		}
+		time.Sleep(time.Millisecond * 100)
+	}
+	Log.Fail(t, "Server did not become ready: ", rs.ReadyChecks())
+	return false
+}
+
 func createServiceNic(t *testing.T) (ifs.IVNic, bool) {
 	resources, _ := CreateResources(VNET_PORT, 2, ifs.Info_Level)
 	serviceNic := vnic.NewVirtualNetworkInterface(resources, nil)
@@ -107,6 +132,7 @@ func createRestClient2(t *testing.T, pb interface{}, prefix string) (*client.Res
 		Host:          ipsegment.MachineIP,
 		Port:          8080,
 		Https:         true,
+		AllowInsecure: true,
 		TokenRequired: true,
 		CertDomain:    domain,
 		CertPrivate:   private,
@@ -133,6 +159,104 @@ func createRestClient2(t *testing.T, pb interface{}, prefix string) (*client.Res
 	return restClient, true
 }
 
+func createLocalRestClient(t *testing.T, serverURL string, configure ...func(*client.RestClientConfig)) (*client.RestClient, bool) {
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		Log.Fail(t, err)
+		return nil, false
+	}
+	port, _ := strconv.Atoi(u.Port())
+	resources, _ := CreateResources(VNET_PORT, 4, ifs.Info_Level)
+	resources.Registry().Register(&l8api.AuthToken{})
+	resources.Registry().Register(&l8api.AuthUser{})
+	clientConfig := &client.RestClientConfig{
+		Host:     u.Hostname(),
+		Port:     port,
+		AuthInfo: &client.RestAuthInfo{},
+	}
+	for _, c := range configure {
+		c(clientConfig)
+	}
+	restClient, err := client.NewRestClient(clientConfig, resources)
+	if err != nil {
+		Log.Fail(t, err)
+		return nil, false
+	}
+	return restClient, true
+}
+
+func createLocalGraphQLClient(t *testing.T, serverURL string, configure ...func(*gclient.GraphQLClientConfig)) (*gclient.GraphQLClient, bool) {
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		Log.Fail(t, err)
+		return nil, false
+	}
+	port, _ := strconv.Atoi(u.Port())
+	resources, _ := CreateResources(VNET_PORT, 4, ifs.Info_Level)
+	resources.Registry().Register(&l8api.AuthToken{})
+	clientConfig := &gclient.GraphQLClientConfig{
+		Host: u.Hostname(),
+		Port: port,
+	}
+	for _, c := range configure {
+		c(clientConfig)
+	}
+	graphQLClient, err := gclient.NewGraphQLClient(clientConfig, resources)
+	if err != nil {
+		Log.Fail(t, err)
+		return nil, false
+	}
+	return graphQLClient, true
+}
+
+// roundTripFunc is an http.RoundTripper that answers requests in-process,
+// for client tests that don't open sockets.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// stubResponse builds a response with the given status and body.
+func stubResponse(r *http.Request, status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Status:     strconv.Itoa(status) + " " + http.StatusText(status),
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    r,
+	}
+}
+
+// panics reports whether call panics, e.g. on the clients' missing token guard.
+func panics(call func()) (panicked bool) {
+	defer func() { panicked = recover() != nil }()
+	call()
+	return false
+}
+
+// mapTokenStore is an in-memory client TokenStore.
+type mapTokenStore struct {
+	mtx    sync.Mutex
+	tokens map[string]string
+}
+
+func newMapTokenStore() *mapTokenStore {
+	return &mapTokenStore{tokens: map[string]string{}}
+}
+
+func (s *mapTokenStore) Get(key string) string {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.tokens[key]
+}
+
+func (s *mapTokenStore) Set(key, token string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.tokens[key] = token
+}
+
 func PushPlugin(nic ifs.IVNic, name string) error {
 	data, err := os.ReadFile(name)
 	if err != nil {
@@ -21,9 +21,13 @@
 //   - HTTP/HTTPS with TLS certificate verification or InsecureSkipVerify
 //   - Bearer token authentication with automatic token refresh via Auth()
 //   - API key authentication via custom headers (X-USER-ID, X-API-KEY)
-//   - GZIP response decompression
+//   - GZIP response decompression, with response bodies capped at MaxResponseBytes
 //   - Automatic retry on timeout (up to 5 attempts with 5-second backoff)
 //   - Protocol Buffer serialization via protojson
+//   - Type-safe generic helpers (GetAs, PostAs, ...) returning concrete messages
+//   - Concurrent batch execution with bounded parallelism via DoBatch()
+//   - Opt-in redirect following that drops credentials on cross-host redirects
+//   - Optional per-host circuit breaker that fails fast while a backend is down
 //
 // Example usage:
 //
@@ -31,6 +35,7 @@
 //	    Host:  "api.example.com",
 //	    Port:  443,
 //	    Https: true,
+//	    CertFileName: "/etc/ssl/ca.crt",
 //	    TokenRequired: true,
 //	}
 //	client, _ := NewRestClient(config, resources)
@@ -42,16 +47,22 @@ import (
 	"bytes"
 	"compress/gzip"
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	nethttp "net/http"
+	"os"
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8web/go/web/internal/unmarshal"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
 )
@@ -63,75 +74,237 @@ type RestClient struct {
 	RestClientConfig                 // Embedded configuration
 	httpClient       *nethttp.Client // Underlying HTTP client with TLS config
 	resources        ifs.IResources  // Layer 8 resources for type registry access
+	tokenMtx         sync.RWMutex    // Guards token, tokenExpiry and the stored credentials
+	token            string          // Current bearer token, see Token() and SetToken()
+	tokenExpiry      time.Time       // When token expires, zero if unknown
+	authUser         string          // Credentials of the last successful Auth, used to refresh the token
+	authPass         string
+	breaker          *circuitBreaker // Per host circuit breaker, see BreakerThreshold
+	tenant           string          // TokenStore key of the token, see ForTenant
 }
 
 // RestClientConfig contains configuration options for creating a REST client.
 type RestClientConfig struct {
-	Host          string // Target server hostname (e.g., "api.example.com")
-	Prefix        string // URL prefix for all requests (e.g., "/api/v1/")
-	Port          int    // Target server port
-	Https         bool   // Enable HTTPS connections
-	TokenRequired bool   // Require bearer token for requests
-	Token         string // Current bearer token (set by Auth() or manually)
+	Host          string     // Target server hostname (e.g., "api.example.com")
+	Prefix        string     // URL prefix for all requests (e.g., "/api/v1/")
+	Port          int        // Target server port
+	Https         bool       // Enable HTTPS connections
+	TokenRequired bool       // Require bearer token for requests
+	Token         string     // Initial bearer token, unused with a TokenStore; afterwards use RestClient.Token() and SetToken()
+	TokenExpiry   time.Time  // When the initial Token expires, zero if unknown
+	TokenStore    TokenStore // Optional store keeping tokens per tenant key instead of in the client, see ForTenant
 	CertDomain    string
 	CertPrivate   string
 	CertPublic    string
-	AuthInfo      *RestAuthInfo // Authentication configuration
+	CertFileName  string            // Path to CA certificate file for TLS verification
+	AllowInsecure bool              // Skip server certificate verification over HTTPS when CertFileName is not set
+	AuthInfo      *RestAuthInfo     // Authentication configuration
+	BatchWorkers  int               // Max concurrent requests in DoBatch (default: DefaultBatchWorkers)
+	UserAgent     string            // User-Agent header sent on every request (default: DefaultUserAgent)
+	CookieJar     nethttp.CookieJar // Optional jar that stores cookies set by the server (e.g., bToken) and resends them; nil keeps the client stateless
+
+	// MaxResponseBytes caps the size of a response body, after gzip decompression.
+	// Larger responses fail with ErrResponseTooLarge instead of being read into
+	// memory. Zero means DefaultMaxResponseBytes, a negative value means no limit.
+	MaxResponseBytes int64
+
+	// Transport replaces the built-in HTTP transport, e.g. with a stub
+	// RoundTripper in unit tests. When set, the TLS and connection pool
+	// settings are ignored.
+	Transport nethttp.RoundTripper
+
+	// BreakerThreshold enables a per-host circuit breaker: after this many
+	// consecutive failures (transport errors or 5xx statuses), requests to the
+	// host fail with ErrCircuitOpen for BreakerCooldown (default:
+	// DefaultBreakerCooldown), then a single trial request decides whether it
+	// closes again. Zero (the default) disables the breaker.
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+
+	// FollowRedirects makes the client follow 3xx redirects, up to MaxRedirects
+	// (default: DefaultMaxRedirects) in a row. A redirect to another host or
+	// scheme drops the Authorization, Cookie, X-API-KEY and X-USER-ID headers.
+	// When off (the default), a redirect fails like any other non-2xx status.
+	FollowRedirects bool
+	MaxRedirects    int
+
+	// RetryOnStatus lists the response status codes (e.g., 429, 502, 503) that
+	// are retried like a timeout, up to 5 times. The client waits for the
+	// response's Retry-After seconds, or 5 seconds without one. Statuses not
+	// listed, such as 400 or 404, fail right away. Empty (the default) retries
+	// on timeouts only.
+	RetryOnStatus []int
+
+	// OnTrace, if set, receives the DNS, connect, TLS handshake and
+	// time-to-first-byte timings of every request attempt, see RequestTrace.
+	// It is called on the requesting goroutine, so concurrently from DoBatch.
+	OnTrace func(RequestTrace)
+
+	MaxIdleConns        int           // Max idle keep-alive connections across all hosts (default: DefaultMaxIdleConns)
+	MaxIdleConnsPerHost int           // Max idle keep-alive connections per host (default: DefaultMaxIdleConnsPerHost)
+	IdleConnTimeout     time.Duration // How long an idle connection is kept for reuse (default: DefaultIdleConnTimeout)
 }
 
+// DefaultUserAgent identifies Layer 8 clients in backend access logs when
+// RestClientConfig.UserAgent is not set.
+const DefaultUserAgent = "l8web-client/1.0"
+
+// DefaultMaxResponseBytes is the response body size limit used when
+// RestClientConfig.MaxResponseBytes is not set.
+const DefaultMaxResponseBytes = 64 << 20
+
+// ErrResponseTooLarge is returned when a response body exceeds MaxResponseBytes.
+var ErrResponseTooLarge = errors.New("response body exceeds MaxResponseBytes")
+
+// ErrInsecureTLS is returned by NewRestClient for an HTTPS client with no
+// CertFileName, unless AllowInsecure opts into skipping verification.
+var ErrInsecureTLS = errors.New("HTTPS without a CA certificate: set CertFileName, or AllowInsecure to skip server certificate verification")
+
+const (
+	// DefaultMaxIdleConns is the default pool size of idle connections across all hosts.
+	DefaultMaxIdleConns = 100
+	// DefaultMaxIdleConnsPerHost is the default pool size of idle connections per host.
+	DefaultMaxIdleConnsPerHost = 10
+	// DefaultIdleConnTimeout is the default time an idle connection is kept for reuse.
+	DefaultIdleConnTimeout = 90 * time.Second
+)
+
 // RestAuthInfo contains authentication configuration for the REST client.
 // Supports two modes: bearer token authentication and API key authentication.
 type RestAuthInfo struct {
-	NeedAuth   bool   // Enable bearer token authentication flow
-	BodyType   string // Protocol Buffer type name for auth request body
-	UserField  string // Field name for username in auth request
-	PassField  string // Field name for password in auth request
-	RespType   string // Protocol Buffer type name for auth response
-	TokenField string // Field name containing token in auth response
-	AuthPath   string // Endpoint path for authentication (e.g., "/auth")
-	IsAPIKey   bool   // Use API key authentication instead of bearer token
-	ApiUser    string // API user ID (sent as X-USER-ID header)
-	ApiKey     string // API key (sent as X-API-KEY header)
+	NeedAuth    bool   // Enable bearer token authentication flow
+	BodyType    string // Protocol Buffer type name for auth request body
+	UserField   string // Field name for username in auth request
+	PassField   string // Field name for password in auth request
+	RespType    string // Protocol Buffer type name for auth response
+	TokenField  string // Field name containing token in auth response
+	AuthPath    string // Full endpoint path for authentication, never prefixed (e.g., "/auth", "/api/v1/login")
+	IsAPIKey    bool   // Use API key authentication instead of bearer token
+	ApiUser     string // API user ID (sent as X-USER-ID header)
+	ApiKey      string // API key (sent as X-API-KEY header)
+	ExpiryField string // Optional field in auth response holding the token expiry, as seconds until expiry (expires_in), Unix seconds or RFC 3339
 }
 
+// TokenRefreshMargin is how long before the token expiry the client proactively
+// re-authenticates on the next request.
+const TokenRefreshMargin = 30 * time.Second
+
 // NewRestClient creates a new REST client with the provided configuration.
 // For HTTPS connections, it configures TLS:
-//   - If CertDomain is provided, it uses CertPublic as the CA certificate for verification
-//   - Otherwise, it uses InsecureSkipVerify (suitable for self-signed certs)
+//   - If CertFileName is provided, it uses that CA certificate for verification
+//   - Otherwise, if AllowInsecure is set, it uses InsecureSkipVerify (suitable
+//     for self-signed certs) and logs a warning, as the connection is open to
+//     man-in-the-middle attacks
+//   - Otherwise, it fails with ErrInsecureTLS
+//
+// Both HTTP and HTTPS share a keep-alive transport tuned by MaxIdleConns,
+// MaxIdleConnsPerHost and IdleConnTimeout.
+//
+// Cookies are ignored unless CookieJar is set, for example to keep the HttpOnly
+// bToken session cookie set by the server's /auth endpoint:
+//
+//	jar, _ := cookiejar.New(nil)
+//	config.CookieJar = jar
+//
+// If Transport is set, it is used as is instead of the built-in transport.
+//
+// Redirects are only followed if FollowRedirects is set, and never carry the
+// bearer token or API key to another host (see checkRedirect).
 func NewRestClient(config *RestClientConfig, resources ifs.IResources) (*RestClient, error) {
 	rc := &RestClient{}
 	rc.CertDomain = config.CertDomain
 	rc.CertPrivate = config.CertPrivate
 	rc.CertPublic = config.CertPublic
+	rc.CertFileName = config.CertFileName
+	rc.AllowInsecure = config.AllowInsecure
 	rc.Host = config.Host
 	rc.Https = config.Https
 	rc.AuthInfo = config.AuthInfo
 	rc.Prefix = config.Prefix
 	rc.Port = config.Port
 	rc.TokenRequired = config.TokenRequired
-	rc.Token = config.Token
+	rc.token = config.Token
+	rc.tokenExpiry = config.TokenExpiry
+	rc.TokenStore = config.TokenStore
+	rc.BatchWorkers = config.BatchWorkers
+	rc.UserAgent = config.UserAgent
+	if rc.UserAgent == "" {
+		rc.UserAgent = DefaultUserAgent
+	}
+	rc.MaxResponseBytes = config.MaxResponseBytes
+	rc.MaxIdleConns = config.MaxIdleConns
+	rc.MaxIdleConnsPerHost = config.MaxIdleConnsPerHost
+	rc.IdleConnTimeout = config.IdleConnTimeout
+	rc.CookieJar = config.CookieJar
+	rc.Transport = config.Transport
+	rc.FollowRedirects = config.FollowRedirects
+	rc.MaxRedirects = config.MaxRedirects
+	rc.RetryOnStatus = config.RetryOnStatus
+	rc.OnTrace = config.OnTrace
+	rc.BreakerThreshold = config.BreakerThreshold
+	rc.BreakerCooldown = config.BreakerCooldown
+	rc.breaker = newCircuitBreaker(rc.BreakerThreshold, rc.BreakerCooldown)
 	rc.resources = resources
 
-	if !rc.Https {
-		rc.httpClient = &nethttp.Client{}
-	} else {
-		rc.httpClient = &nethttp.Client{
-			Transport: &nethttp.Transport{
-				TLSClientConfig: &tls.Config{
-					InsecureSkipVerify: true,
-					ServerName:         rc.Host,
-				},
-			},
-		}
+	if rc.Transport != nil {
+		rc.httpClient = &nethttp.Client{Transport: rc.Transport, Jar: rc.CookieJar, CheckRedirect: rc.checkRedirect}
+		return rc, nil
+	}
 
+	transport := rc.newTransport()
+	if rc.Https {
+		if rc.CertFileName != "" {
+			caCert, err := os.ReadFile(rc.CertFileName)
+			if err != nil {
+				return nil, err
+			}
+			caCertPool := x509.NewCertPool()
+			caCertPool.AppendCertsFromPEM(caCert)
+			transport.TLSClientConfig = &tls.Config{
+				RootCAs:    caCertPool,
+				ServerName: rc.Host,
+			}
+		} else {
+			if !rc.AllowInsecure {
+				return nil, ErrInsecureTLS
+			}
+			if resources != nil {
+				resources.Logger().Warning("REST client to ", rc.Host, " skips TLS certificate verification (AllowInsecure)")
+			}
+			transport.TLSClientConfig = &tls.Config{
+				InsecureSkipVerify: true,
+				ServerName:         rc.Host,
+			}
+		}
 	}
+	rc.httpClient = &nethttp.Client{Transport: transport, Jar: rc.CookieJar, CheckRedirect: rc.checkRedirect}
 
 	return rc, nil
 }
 
+// newTransport creates the keep-alive transport used for both HTTP and HTTPS,
+// starting from Go's default transport (proxy from environment, dial timeouts)
+// and applying the configured idle connection pool settings.
+func (rc *RestClient) newTransport() *nethttp.Transport {
+	transport := nethttp.DefaultTransport.(*nethttp.Transport).Clone()
+	transport.MaxIdleConns = DefaultMaxIdleConns
+	if rc.MaxIdleConns > 0 {
+		transport.MaxIdleConns = rc.MaxIdleConns
+	}
+	transport.MaxIdleConnsPerHost = DefaultMaxIdleConnsPerHost
+	if rc.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = rc.MaxIdleConnsPerHost
+	}
+	transport.IdleConnTimeout = DefaultIdleConnTimeout
+	if rc.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = rc.IdleConnTimeout
+	}
+	return transport
+}
+
 // buildURL constructs the full URL for a request, combining the host, port,
-// prefix, endpoint, and any query variables. The prefix is not added for
-// the /auth endpoint to support authentication at different paths.
+// prefix, endpoint, and any query variables. The configured AuthInfo.AuthPath
+// is a full path and is used verbatim; every other endpoint gets the prefix.
 func (rc *RestClient) buildURL(end, vars string) string {
 	url := bytes.Buffer{}
 	url.WriteString("http")
@@ -142,7 +315,7 @@ func (rc *RestClient) buildURL(end, vars string) string {
 	url.WriteString(rc.Host)
 	url.WriteString(":")
 	url.WriteString(strconv.Itoa(rc.Port))
-	if rc.Prefix != "" && end != "/auth" {
+	if rc.Prefix != "" && !rc.isAuthPath(end) {
 		url.WriteString(rc.Prefix)
 	}
 	url.WriteString(end)
@@ -152,9 +325,8 @@ func (rc *RestClient) buildURL(end, vars string) string {
 }
 
 // request creates an HTTP request with proper headers and authentication.
-// It marshals the Protocol Buffer body to JSON, sets Authorization header
-// if a token is available, and adds API key headers if configured.
-// Panics if TokenRequired is true but no token is available for non-auth endpoints.
+// It marshals the Protocol Buffer body to JSON, unless vars are set, and
+// builds the request with newRequest.
 func (rc *RestClient) request(method, end, vars string, pbBody proto.Message) (*nethttp.Request, error) {
 	var body []byte
 	var err error
@@ -164,39 +336,58 @@ func (rc *RestClient) request(method, end, vars string, pbBody proto.Message) (*
 			return nil, err
 		}
 	}
+	return rc.newRequest(method, end, vars, bytes.NewReader(body), "application/json")
+}
+
+// newRequest creates an HTTP request to end with the given body and content
+// type. It sets the Authorization header if a token is available, and adds API
+// key headers if configured. Panics if TokenRequired is true but no token is
+// available for non-auth endpoints.
+func (rc *RestClient) newRequest(method, end, vars string, body io.Reader, contentType string) (*nethttp.Request, error) {
 	url := rc.buildURL(end, vars)
-	request, err := nethttp.NewRequest(method, url, bytes.NewReader([]byte(body)))
+	request, err := nethttp.NewRequest(method, url, body)
 	if err != nil {
 		return nil, err
 	}
 
-	if rc.TokenRequired && rc.Token == "" && rc.Https && !rc.isAuthPath(end) {
+	token := rc.Token()
+	if rc.TokenRequired && token == "" && rc.Https && !rc.isAuthPath(end) {
 		panic("No token with secure connection!")
 	}
 
-	if rc.TokenRequired && rc.Token != "" {
-		request.Header.Set("Authorization", "Bearer "+rc.Token)
+	if rc.TokenRequired && token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
 	}
-	request.Header.Add("content-type", "application/json")
+	request.Header.Add("content-type", contentType)
 	request.Header.Add("Accept", "application/json, text/plain, */*")
+	request.Header.Set("User-Agent", rc.UserAgent)
 	request.Header.Add("Access-Control-Allow-Origin", "*")
-	if rc.AuthInfo.IsAPIKey {
+	if rc.AuthInfo != nil && rc.AuthInfo.IsAPIKey {
 		request.Header.Add("X-USER-ID", rc.AuthInfo.ApiUser)
 		request.Header.Add("X-API-KEY", rc.AuthInfo.ApiKey)
 	}
 	return request, nil
 }
 
-// isAuthPath checks if the endpoint is the configured authentication path.
-// Used to skip token requirements for the auth endpoint itself.
+// isAuthPath checks if the endpoint is exactly the configured authentication
+// path, or one of the publicPaths. These endpoints need no token and buildURL
+// uses them as-is without the prefix.
 func (rc *RestClient) isAuthPath(end string) bool {
-	if rc.AuthInfo == nil {
-		return false
-	}
-	if strings.HasSuffix(rc.AuthInfo.AuthPath, end) {
+	if publicPaths[end] {
 		return true
 	}
-	return false
+	return rc.AuthInfo != nil && rc.AuthInfo.AuthPath != "" && end == rc.AuthInfo.AuthPath
+}
+
+// publicPaths are the server's built-in endpoints called before a token
+// exists: TFA, CAPTCHA and registration. Like AuthInfo.AuthPath, they are
+// never prefixed and need no token.
+var publicPaths = map[string]bool{
+	TFASetupPath:       true,
+	TFASetupVerifyPath: true,
+	TFAVerifyPath:      true,
+	CaptchaPath:        true,
+	RegisterPath:       true,
 }
 
 // is200 checks if an HTTP status string represents a successful response (2xx).
@@ -213,6 +404,34 @@ func is200(status string) (bool, error) {
 	return false, nil
 }
 
+// retryable reports whether a request may be retried after a timeout or a
+// RetryOnStatus status. A DELETE with query parameters is a bulk delete by
+// filter: it may already have run, and a relative filter (e.g. olderThan=30d)
+// matches more on a retry.
+func retryable(method, vars string) bool {
+	return method != nethttp.MethodDelete || vars == ""
+}
+
+// retryOnStatus reports whether status is listed in RetryOnStatus.
+func (rc *RestClient) retryOnStatus(status int) bool {
+	for _, code := range rc.RetryOnStatus {
+		if code == status {
+			return true
+		}
+	}
+	return false
+}
+
+// retryDelay returns how long to wait before retrying response: its
+// Retry-After header, in seconds, or 5 seconds when missing or not a number.
+func retryDelay(response *nethttp.Response) time.Duration {
+	seconds, err := strconv.Atoi(response.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return time.Second * 5
+	}
+	return time.Second * time.Duration(seconds)
+}
+
 // isTimeout checks if an error indicates a timeout or connection issue.
 // If so, it sleeps for 5 seconds before returning true to enable retry.
 // Detects: "connection reset by peer", "timeout", "connection timed out".
@@ -229,11 +448,21 @@ func isTimeout(err error) bool {
 // Auth performs authentication against the configured AuthPath endpoint.
 // It creates a credentials message using reflection based on AuthInfo configuration,
 // sends it to the server, and extracts the bearer token from the response.
-// The token is stored in rc.Token for use in subsequent requests.
+// The token is stored in the client, or in its tenant's TokenStore entry (see
+// Token()), for use in subsequent requests.
+//
+// The token expiry is read from AuthInfo.ExpiryField if set, otherwise from the
+// "exp" claim when the token is a JWT. When an expiry is known, the credentials
+// are kept in memory and the client re-authenticates on the first request made
+// within TokenRefreshMargin of the expiry, rather than waiting for a 401.
 //
 // Requires AuthInfo to be configured with: BodyType, UserField, PassField,
 // RespType, TokenField, and AuthPath.
 //
+// If the response reports that the user must confirm a TFA code (NeedTfa, as in
+// l8api.AuthToken), no token is stored and a *TFARequiredError is returned;
+// complete the login with TFAVerify, or TFASetup and TFASetupVerify.
+//
 // Returns nil if NeedAuth is false or if authentication succeeds.
 func (rc *RestClient) Auth(user, pass string) error {
 	if rc.AuthInfo == nil || !rc.AuthInfo.NeedAuth {
@@ -260,6 +489,9 @@ func (rc *RestClient) Auth(user, pass string) error {
 	}
 
 	tokenVal := reflect.ValueOf(token).Elem()
+	if tfa := tfaRequired(tokenVal, user); tfa != nil {
+		return tfa
+	}
 	if !tokenVal.FieldByName(rc.AuthInfo.TokenField).CanSet() {
 		return errors.New("invalid token field name")
 	}
@@ -269,50 +501,198 @@ func (rc *RestClient) Auth(user, pass string) error {
 		return errors.New("invalid token field value, should be string")
 	}
 
-	rc.Token = t
+	expiry := rc.parseTokenExpiry(tokenVal, t)
+	rc.tokenMtx.Lock()
+	defer rc.tokenMtx.Unlock()
+	if rc.TokenStore != nil {
+		rc.TokenStore.Set(rc.tenant, t)
+	} else {
+		rc.token = t
+	}
+	rc.tokenExpiry = expiry
+	rc.authUser = user
+	rc.authPass = pass
 	return nil
 }
 
+// Token returns the current bearer token, read from the TokenStore entry of
+// the client's tenant if a store is configured. Safe for concurrent use.
+func (rc *RestClient) Token() string {
+	if rc.TokenStore != nil {
+		return rc.TokenStore.Get(rc.tenant)
+	}
+	rc.tokenMtx.RLock()
+	defer rc.tokenMtx.RUnlock()
+	return rc.token
+}
+
+// SetToken replaces the bearer token, e.g. with one obtained out of band, in
+// the TokenStore entry of the client's tenant if a store is configured.
+// The expiry is reset to unknown, so the token is not refreshed automatically.
+// Safe for concurrent use with in-flight requests.
+func (rc *RestClient) SetToken(token string) {
+	rc.tokenMtx.Lock()
+	defer rc.tokenMtx.Unlock()
+	if rc.TokenStore != nil {
+		rc.TokenStore.Set(rc.tenant, token)
+	} else {
+		rc.token = token
+	}
+	rc.tokenExpiry = time.Time{}
+}
+
+// TokenExpiry returns when the current token expires, set by Auth() from
+// AuthInfo.ExpiryField or the JWT "exp" claim. Zero if unknown.
+func (rc *RestClient) TokenExpiry() time.Time {
+	rc.tokenMtx.RLock()
+	defer rc.tokenMtx.RUnlock()
+	return rc.tokenExpiry
+}
+
+// parseTokenExpiry determines when the token returned by Auth expires, from the
+// configured ExpiryField or, failing that, the JWT "exp" claim. Returns the
+// zero time if the expiry is unknown.
+func (rc *RestClient) parseTokenExpiry(tokenVal reflect.Value, token string) time.Time {
+	if rc.AuthInfo.ExpiryField != "" {
+		field := tokenVal.FieldByName(rc.AuthInfo.ExpiryField)
+		switch field.Kind() {
+		case reflect.Int, reflect.Int32, reflect.Int64:
+			return expiryFromSeconds(field.Int())
+		case reflect.Uint32, reflect.Uint64:
+			return expiryFromSeconds(int64(field.Uint()))
+		case reflect.String:
+			if seconds, err := strconv.ParseInt(field.String(), 10, 64); err == nil {
+				return expiryFromSeconds(seconds)
+			}
+			if expiry, err := time.Parse(time.RFC3339, field.String()); err == nil {
+				return expiry
+			}
+		}
+	}
+	return jwtExpiry(token)
+}
+
+// expiryFromSeconds interprets an expiry value as a relative lifetime in
+// seconds (expires_in) when it is small, or as absolute Unix seconds otherwise.
+func expiryFromSeconds(seconds int64) time.Time {
+	if seconds <= 0 {
+		return time.Time{}
+	}
+	// Anything before 2001-09-09 as Unix time is taken to be a lifetime
+	if seconds < 1000000000 {
+		return time.Now().Add(time.Duration(seconds) * time.Second)
+	}
+	return time.Unix(seconds, 0)
+}
+
+// jwtExpiry returns the "exp" claim of a JWT without verifying its signature,
+// or the zero time if the token is not a JWT or has no exp claim.
+func jwtExpiry(token string) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}
+	}
+	claims := struct {
+		Exp int64 `json:"exp"`
+	}{}
+	if json.Unmarshal(payload, &claims) != nil || claims.Exp <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(claims.Exp, 0)
+}
+
+// refreshToken re-authenticates with the stored credentials when the token is
+// within TokenRefreshMargin of its expiry. Requests to the auth path itself
+// never trigger a refresh.
+func (rc *RestClient) refreshToken(end string) error {
+	rc.tokenMtx.RLock()
+	expiry, user, pass := rc.tokenExpiry, rc.authUser, rc.authPass
+	rc.tokenMtx.RUnlock()
+	if expiry.IsZero() || user == "" || rc.isAuthPath(end) {
+		return nil
+	}
+	if time.Now().Add(TokenRefreshMargin).Before(expiry) {
+		return nil
+	}
+	return rc.Auth(user, pass)
+}
+
 // Do executes an HTTP request and returns the response as a Protocol Buffer message.
 //
 // Parameters:
 //   - method: HTTP method (GET, POST, PUT, PATCH, DELETE)
 //   - end: Endpoint path (e.g., "/users")
 //   - responseType: Protocol Buffer type name for deserializing response
-//   - responseAttribute: Optional attribute name to wrap response JSON (for nested responses)
+//   - responseAttribute: Optional attribute name to wrap response JSON (for nested responses),
+//     may be a dotted path (see unmarshalResponse)
 //   - vars: Query string to append to URL
 //   - pbBody: Request body as Protocol Buffer (marshaled to JSON)
 //   - tryCount: Current retry attempt (starts at 1, max 5)
 //
-// Handles GZIP response decompression automatically. Retries on timeout errors
-// up to 5 times with 5-second backoff. Returns error for non-2xx responses.
+// Handles GZIP response decompression automatically. Retries on timeout errors,
+// and on the statuses in RetryOnStatus, up to 5 times with 5-second backoff
+// (or the response's Retry-After), except for a DELETE with vars, which the
+// server treats as a bulk delete by filter. Returns error for non-2xx responses other
+// than 304 Not Modified. A 204 or 304 response, or an empty body, yields an empty
+// message of responseType and no error.
 func (rc *RestClient) Do(method, end, responseType, responseAttribute, vars string, pbBody proto.Message, tryCount int) (proto.Message, error) {
+	jsonBytes, err := rc.execute(method, end, vars, pbBody, tryCount)
+	if err != nil {
+		return nil, err
+	}
+	return rc.decode(jsonBytes, responseType, responseAttribute)
+}
 
-	request, err := rc.request(method, end, vars, pbBody)
+// decode unmarshals a response body into a new message of responseType, see
+// unmarshalResponse. An empty responseType yields a nil message.
+func (rc *RestClient) decode(jsonBytes []byte, responseType, responseAttribute string) (proto.Message, error) {
+	if responseType == "" {
+		return nil, nil
+	}
+
+	info, err := rc.resources.Registry().Info(responseType)
+	if err != nil {
+		return nil, err
+	}
+	_interface, err := info.NewInstance()
 	if err != nil {
 		return nil, err
 	}
 
-	//Execute the request
-	response, err := rc.httpClient.Do(request)
+	responsePb := _interface.(proto.Message)
+	err = unmarshalResponse(jsonBytes, responseAttribute, responsePb)
+	return responsePb, err
+}
+
+// execute sends the request and returns the raw (decompressed) response body.
+// It returns an error for non-2xx responses, including the response body in the
+// error message. 204 No Content and 304 Not Modified responses succeed with a
+// nil body.
+func (rc *RestClient) execute(method, end, vars string, pbBody proto.Message, tryCount int) ([]byte, error) {
+	response, err := rc.send(method, end, vars, pbBody, tryCount)
 	if err != nil {
-		if isTimeout(err) {
-			if tryCount <= 5 {
-				return rc.Do(method, end, responseType, responseAttribute, vars, pbBody, tryCount+1)
-			}
-		}
 		return nil, err
 	}
+	return rc.readResponse(method, response)
+}
 
-	var jsonBytes []byte
+// readResponse reads and closes the response body, returning an error for a
+// non-2xx response and a nil body for 204 No Content and 304 Not Modified.
+func (rc *RestClient) readResponse(method string, response *nethttp.Response) ([]byte, error) {
+	// Closing the body returns the connection to the keep-alive pool
+	defer response.Body.Close()
 
-	switch response.Header.Get("Content-Encoding") {
-	case "gzip":
-		reader, _ := gzip.NewReader(response.Body)
-		jsonBytes, _ = io.ReadAll(reader)
-		defer reader.Close()
-	default:
-		jsonBytes, _ = io.ReadAll(response.Body)
+	// Neither carries a body to decode, whatever the server sent.
+	if response.StatusCode == nethttp.StatusNoContent || response.StatusCode == nethttp.StatusNotModified {
+		return nil, nil
+	}
+	jsonBytes, err := readBody(response, rc.MaxResponseBytes)
+	if err != nil {
+		return nil, err
 	}
 	ok, err := is200(response.Status)
 	if err != nil {
@@ -321,35 +701,148 @@ func (rc *RestClient) Do(method, end, responseType, responseAttribute, vars stri
 	if !ok {
 		return nil, errors.New(method + " failed with status " + response.Status + ":" + string(jsonBytes))
 	}
+	return jsonBytes, nil
+}
 
-	if responseType == "" {
+// send builds and sends the request and returns the response, whose body the
+// caller must close. It retries on timeout errors and RetryOnStatus statuses
+// up to 5 times, unless the host's circuit breaker opens.
+func (rc *RestClient) send(method, end, vars string, pbBody proto.Message, tryCount int) (*nethttp.Response, error) {
+	err := rc.refreshToken(end)
+	if err != nil {
+		return nil, err
+	}
+	request, err := rc.request(method, end, vars, pbBody)
+	if err != nil {
 		return nil, err
 	}
 
-	info, err := rc.resources.Registry().Info(responseType)
+	response, err := rc.roundTrip(request)
 	if err != nil {
+		if retryable(method, vars) && isTimeout(err) {
+			if tryCount <= 5 {
+				return rc.send(method, end, vars, pbBody, tryCount+1)
+			}
+		}
 		return nil, err
 	}
-	_interface, err := info.NewInstance()
+
+	if tryCount <= 5 && retryable(method, vars) && rc.retryOnStatus(response.StatusCode) {
+		// Drain the body so the connection goes back to the keep-alive pool
+		io.Copy(io.Discard, response.Body)
+		response.Body.Close()
+		time.Sleep(retryDelay(response))
+		return rc.send(method, end, vars, pbBody, tryCount+1)
+	}
+	return response, nil
+}
+
+// roundTrip sends request once through the host's circuit breaker, tracing
+// it for OnTrace if set.
+func (rc *RestClient) roundTrip(request *nethttp.Request) (*nethttp.Response, error) {
+	host := request.URL.Host
+	err := rc.breaker.allow(host)
 	if err != nil {
 		return nil, err
 	}
 
-	responsePb := _interface.(proto.Message)
+	var tracer *requestTracer
+	if rc.OnTrace != nil {
+		request, tracer = traced(request)
+	}
+
+	//Execute the request
+	response, err := rc.httpClient.Do(request)
+	rc.breaker.record(host, err != nil || response.StatusCode >= nethttp.StatusInternalServerError)
+	if tracer != nil {
+		rc.OnTrace(tracer.finish(response, err))
+	}
+	return response, err
+}
+
+// readBody reads the response body, decompressing GZIP if needed, and fails
+// with ErrResponseTooLarge once more than limit bytes are read. A zero limit
+// means DefaultMaxResponseBytes and a negative one no limit.
+func readBody(response *nethttp.Response, limit int64) ([]byte, error) {
+	var reader io.Reader = response.Body
+	if response.Header.Get("Content-Encoding") == "gzip" {
+		gzReader, err := gzip.NewReader(response.Body)
+		if err != nil {
+			return nil, err
+		}
+		defer gzReader.Close()
+		reader = gzReader
+	}
+	if limit == 0 {
+		limit = DefaultMaxResponseBytes
+	}
+	if limit < 0 {
+		return io.ReadAll(reader)
+	}
+	// Read one byte past the limit to tell a body of exactly limit bytes
+	// from a larger one.
+	data, err := io.ReadAll(io.LimitReader(reader, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%w (%d bytes)", ErrResponseTooLarge, limit)
+	}
+	return data, nil
+}
+
+// unmarshalResponse unmarshals the response JSON into responsePb. If
+// responseAttribute is set, the JSON is first wrapped as {"<attribute>": <json>}
+// so a bare list or value can be decoded into a wrapper message field.
+// Unmarshal failures are returned as *UnmarshalError.
+//
+// responseAttribute may be a dotted path (e.g., "viewer.projects"). The leading
+// segments are walked into the response JSON to locate the value, and the last
+// segment names the field it is wrapped under, so "viewer.projects" decodes
+// response.viewer into the "projects" field of responsePb.
+//
+// An empty body, e.g. from a 204 No Content response, leaves responsePb empty.
+func unmarshalResponse(jsonBytes []byte, responseAttribute string, responsePb proto.Message) error {
+	if len(bytes.TrimSpace(jsonBytes)) == 0 {
+		return nil
+	}
 	if responseAttribute != "" {
+		segments := strings.Split(responseAttribute, ".")
+		value, err := walkJSON(jsonBytes, segments[:len(segments)-1], responseAttribute)
+		if err != nil {
+			return err
+		}
 		buff := bytes.Buffer{}
 		buff.WriteString("{\"")
-		buff.WriteString(responseAttribute)
+		buff.WriteString(segments[len(segments)-1])
 		buff.WriteString("\": ")
-		buff.Write(jsonBytes)
+		buff.Write(value)
 		buff.WriteString("}")
 		jsonBytes = buff.Bytes()
 	}
-	err = protojson.Unmarshal(jsonBytes, responsePb)
+	err := protojson.Unmarshal(jsonBytes, responsePb)
 	if err != nil {
-		fmt.Println(string(jsonBytes))
+		return unmarshal.NewError(jsonBytes, responsePb, err)
 	}
-	return responsePb, err
+	return nil
+}
+
+// walkJSON descends into nested JSON objects following the given segments and
+// returns the value found at the end. The error names the first segment that
+// is missing or whose parent is not an object.
+func walkJSON(jsonBytes []byte, segments []string, path string) ([]byte, error) {
+	for _, segment := range segments {
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(jsonBytes, &obj); err != nil {
+			return nil, errors.New("response attribute '" + path + "': cannot read segment '" + segment + "', parent is not an object")
+		}
+		value, ok := obj[segment]
+		if !ok {
+			return nil, errors.New("response attribute '" + path + "': segment '" + segment + "' not found in response")
+		}
+		jsonBytes = value
+	}
+	return jsonBytes, nil
 }
 
 // GET performs an HTTP GET request. Convenience wrapper for Do().
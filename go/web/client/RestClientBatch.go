@@ -0,0 +1,83 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// RestClientBatch.go provides concurrent execution of independent requests
+// against the same backend with bounded parallelism.
+
+package client
+
+import (
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// DefaultBatchWorkers is the number of concurrent workers used by DoBatch
+// when RestClientConfig.BatchWorkers is not set.
+const DefaultBatchWorkers = 8
+
+// BatchRequest describes a single request in a DoBatch call. The fields
+// mirror the parameters of Do.
+type BatchRequest struct {
+	Method            string        // HTTP method (GET, POST, PUT, PATCH, DELETE)
+	End               string        // Endpoint path (e.g., "/users")
+	ResponseType      string        // Protocol Buffer type name for deserializing the response
+	ResponseAttribute string        // Optional attribute name to wrap response JSON
+	Vars              string        // Query string to append to URL
+	Body              proto.Message // Request body (marshaled to JSON)
+}
+
+// BatchResult holds the outcome of a single BatchRequest.
+type BatchResult struct {
+	Response proto.Message // Decoded response, nil on error or empty ResponseType
+	Err      error         // Error for this request only
+}
+
+// DoBatch executes the requests concurrently through a pool of BatchWorkers
+// workers and returns one result per request, in the same order as reqs.
+// Each request goes through Do, so it shares the client's transport, token
+// and retry logic. A failed request is reported in its BatchResult and does
+// not abort the rest of the batch.
+func (rc *RestClient) DoBatch(reqs []BatchRequest) []BatchResult {
+	results := make([]BatchResult, len(reqs))
+	workers := rc.BatchWorkers
+	if workers <= 0 {
+		workers = DefaultBatchWorkers
+	}
+	if workers > len(reqs) {
+		workers = len(reqs)
+	}
+
+	indexes := make(chan int)
+	wg := sync.WaitGroup{}
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				req := reqs[index]
+				resp, err := rc.Do(req.Method, req.End, req.ResponseType, req.ResponseAttribute, req.Vars, req.Body, 1)
+				results[index] = BatchResult{Response: resp, Err: err}
+			}
+		}()
+	}
+
+	for index := range reqs {
+		indexes <- index
+	}
+	close(indexes)
+	wg.Wait()
+	return results
+}
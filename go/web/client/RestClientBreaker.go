@@ -0,0 +1,106 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// RestClientBreaker.go implements an optional per-host circuit breaker for the
+// RestClient, so requests to a backend that is down fail fast instead of each
+// going through the timeout and retry cycle.
+//
+// The breaker of a host opens after BreakerThreshold consecutive failures (a
+// transport error or a 5xx status). While open, requests fail immediately with
+// ErrCircuitOpen. Once BreakerCooldown has passed, a single trial request is let
+// through (half-open): its success closes the breaker, its failure opens it for
+// another cooldown.
+
+package client
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// DefaultBreakerCooldown is how long an open breaker fails requests when
+// RestClientConfig.BreakerCooldown is not set.
+const DefaultBreakerCooldown = 30 * time.Second
+
+// ErrCircuitOpen is returned for requests to a host whose breaker is open.
+var ErrCircuitOpen = errors.New("circuit breaker open: backend is failing, request not sent")
+
+// circuitBreaker tracks consecutive failures per host.
+type circuitBreaker struct {
+	threshold int                      // Consecutive failures that open a host's breaker, 0 disables the breaker
+	cooldown  time.Duration            // How long an open breaker fails requests before a trial
+	mtx       sync.Mutex               // Guards hosts
+	hosts     map[string]*breakerState // Breaker state per host:port
+}
+
+// breakerState is the breaker of a single host.
+type breakerState struct {
+	failures  int       // Consecutive failures
+	openUntil time.Time // End of the cooldown, once failures reached the threshold
+	probing   bool      // A half-open trial request is in flight
+}
+
+// newCircuitBreaker creates a breaker that opens after threshold consecutive
+// failures for cooldown, or DefaultBreakerCooldown if cooldown is not positive.
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	if cooldown <= 0 {
+		cooldown = DefaultBreakerCooldown
+	}
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown, hosts: map[string]*breakerState{}}
+}
+
+// allow returns ErrCircuitOpen if a request to host must not be sent. After the
+// cooldown it lets one trial request through until its result is recorded. A
+// nil breaker allows every request.
+func (cb *circuitBreaker) allow(host string) error {
+	if cb == nil || cb.threshold <= 0 {
+		return nil
+	}
+	cb.mtx.Lock()
+	defer cb.mtx.Unlock()
+	state, ok := cb.hosts[host]
+	if !ok || state.failures < cb.threshold {
+		return nil
+	}
+	if state.probing || time.Now().Before(state.openUntil) {
+		return ErrCircuitOpen
+	}
+	state.probing = true
+	return nil
+}
+
+// record registers the outcome of a request to host.
+func (cb *circuitBreaker) record(host string, failed bool) {
+	if cb == nil || cb.threshold <= 0 {
+		return
+	}
+	cb.mtx.Lock()
+	defer cb.mtx.Unlock()
+	if !failed {
+		delete(cb.hosts, host)
+		return
+	}
+	state, ok := cb.hosts[host]
+	if !ok {
+		state = &breakerState{}
+		cb.hosts[host] = state
+	}
+	state.failures++
+	state.probing = false
+	if state.failures >= cb.threshold {
+		state.openUntil = time.Now().Add(cb.cooldown)
+	}
+}
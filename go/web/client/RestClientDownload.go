@@ -0,0 +1,68 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// RestClientDownload.go streams large or binary responses to an io.Writer.
+//
+// Unlike Do, which reads the whole body into memory to unmarshal it, Download
+// copies the body to the destination as it arrives, so exports and reports of
+// hundreds of megabytes do not need to fit in memory. MaxResponseBytes does not
+// apply to it.
+
+package client
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	nethttp "net/http"
+)
+
+// Download sends a GET request to end and streams the response body to dst,
+// decompressing GZIP on the fly. It returns the number of bytes written to dst.
+// The request is authenticated and retried like Do. A 204 No Content response
+// writes nothing, and a non-2xx response fails with its body in the error.
+//
+// If the copy fails part way, the bytes already written to dst are counted in
+// the returned size, and dst is left for the caller to discard.
+func (rc *RestClient) Download(end, vars string, dst io.Writer) (int64, error) {
+	response, err := rc.send(nethttp.MethodGet, end, vars, nil, 1)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == nethttp.StatusNoContent {
+		return 0, nil
+	}
+	ok, err := is200(response.Status)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		body, _ := readBody(response, rc.MaxResponseBytes)
+		return 0, errors.New("GET failed with status " + response.Status + ":" + string(body))
+	}
+
+	var reader io.Reader = response.Body
+	if response.Header.Get("Content-Encoding") == "gzip" {
+		gzReader, err := gzip.NewReader(response.Body)
+		if err != nil {
+			return 0, err
+		}
+		defer gzReader.Close()
+		reader = gzReader
+	}
+	return io.Copy(dst, reader)
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// RestClientRedirect.go decides whether the RestClient follows HTTP redirects.
+//
+// Redirects are not followed unless RestClientConfig.FollowRedirects is set;
+// the 3xx response is then returned as an error like any other non-2xx status.
+// When they are followed, a redirect to another host or scheme drops the
+// credentials the client sends (the bearer token and the API key headers), so
+// a redirect can't leak them to a server the client was not configured for.
+
+package client
+
+import (
+	"errors"
+	nethttp "net/http"
+	"strconv"
+)
+
+// DefaultMaxRedirects is the number of redirects followed in a row when
+// RestClientConfig.MaxRedirects is not set.
+const DefaultMaxRedirects = 10
+
+// credentialHeaders are removed from a request redirected to another host or scheme.
+var credentialHeaders = []string{"Authorization", "Cookie", "X-API-KEY", "X-USER-ID"}
+
+// checkRedirect is the nethttp.Client CheckRedirect policy of the client.
+func (rc *RestClient) checkRedirect(req *nethttp.Request, via []*nethttp.Request) error {
+	if !rc.FollowRedirects {
+		return nethttp.ErrUseLastResponse
+	}
+	max := rc.MaxRedirects
+	if max <= 0 {
+		max = DefaultMaxRedirects
+	}
+	if len(via) > max {
+		return errors.New("stopped after " + strconv.Itoa(max) + " redirects")
+	}
+	original := via[0].URL
+	if req.URL.Host != original.Host || req.URL.Scheme != original.Scheme {
+		for _, header := range credentialHeaders {
+			req.Header.Del(header)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// RestClientRegister.go implements the client side of the server's
+// self-service onboarding: fetching a CAPTCHA challenge and registering a new
+// user with its answer.
+//
+// Example usage:
+//
+//	captcha, _ := client.GetCaptcha() // show it to the user
+//	err := client.Register("alice", "secret", answer)
+//	var regErr *client.RegistrationError
+//	if errors.As(err, &regErr) {
+//	    fmt.Println("registration refused:", regErr.Message)
+//	}
+
+package client
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/saichler/l8types/go/types/l8api"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// Onboarding endpoints of the Layer 8 server. Like AuthInfo.AuthPath, they are
+// never prefixed.
+const (
+	CaptchaPath  = "/captcha"
+	RegisterPath = "/register"
+)
+
+// RegistrationError is returned by Register when the server refuses the
+// registration, e.g. for a wrong CAPTCHA answer or an existing user (401), or
+// because registration is disabled (404).
+type RegistrationError struct {
+	Code    int    // HTTP status code
+	Message string // Reason given by the server
+}
+
+func (e *RegistrationError) Error() string {
+	return "registration failed with status " + strconv.Itoa(e.Code) + ": " + e.Message
+}
+
+// GetCaptcha fetches a CAPTCHA challenge from the server, to be answered in
+// Register.
+func (rc *RestClient) GetCaptcha() (string, error) {
+	jsonBytes, err := rc.execute("GET", CaptchaPath, "", nil, 5)
+	if err != nil {
+		return "", err
+	}
+	captcha := &l8api.Captcha{}
+	if err = protojson.Unmarshal(jsonBytes, captcha); err != nil {
+		return "", err
+	}
+	return captcha.Captcha, nil
+}
+
+// Register creates the user with the given password, proving it is not a bot
+// with the answer to a challenge from GetCaptcha. It does not log the user
+// in; call Auth afterwards. A refusal is returned as *RegistrationError.
+func (rc *RestClient) Register(user, pass, captcha string) error {
+	response, err := rc.send("POST", RegisterPath, "", &l8api.AuthUser{User: user, Pass: pass, Captcha: captcha}, 5)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode >= 200 && response.StatusCode <= 299 {
+		return nil
+	}
+	body, err := readBody(response, rc.MaxResponseBytes)
+	if err != nil {
+		return err
+	}
+	return &RegistrationError{Code: response.StatusCode, Message: errorMessage(body)}
+}
+
+// errorMessage returns the message of a server error envelope
+// ({"error": {"code": 401, "message": "..."}}), or the body itself if it is
+// not one.
+func errorMessage(body []byte) string {
+	var envelope struct {
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(body, &envelope) == nil && envelope.Error != nil {
+		return envelope.Error.Message
+	}
+	return strings.TrimSpace(string(body))
+}
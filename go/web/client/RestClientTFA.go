@@ -0,0 +1,136 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// RestClientTFA.go drives the Two-Factor Authentication flow of a Layer 8
+// server from the client side. When the credentials are accepted but the user
+// must confirm a TOTP code, Auth returns a *TFARequiredError instead of
+// storing a token:
+//
+//	err := client.Auth(user, pass)
+//	var tfa *client.TFARequiredError
+//	if errors.As(err, &tfa) {
+//	    if tfa.Setup {
+//	        setup, _ := client.TFASetup(user) // show setup.Qr to the user
+//	        err = client.TFASetupVerify(user, promptForCode())
+//	    } else {
+//	        err = client.TFAVerify(user, promptForCode())
+//	    }
+//	}
+//
+// A successful verification stores the bearer token like Auth does.
+
+package client
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/saichler/l8types/go/types/l8api"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// TFA endpoints of the Layer 8 server. Like AuthInfo.AuthPath, they are
+// never prefixed.
+const (
+	TFASetupPath       = "/tfaSetup"
+	TFASetupVerifyPath = "/tfaSetupVerify"
+	TFAVerifyPath      = "/tfaVerify"
+)
+
+// ErrTFAVerification is returned by TFAVerify and TFASetupVerify when the
+// server does not accept the code.
+var ErrTFAVerification = errors.New("TFA code was not accepted")
+
+// TFARequiredError is returned by Auth when the server accepted the
+// credentials but requires a TFA code before it issues a token.
+type TFARequiredError struct {
+	User      string // User that authenticated, to pass to the TFA methods
+	Setup     bool   // The user has no TFA set up yet: call TFASetup, then TFASetupVerify
+	TokenHash string // Hash identifying the pending login, as returned by the server
+}
+
+func (e *TFARequiredError) Error() string {
+	if e.Setup {
+		return "TFA setup required for user " + e.User
+	}
+	return "TFA verification required for user " + e.User
+}
+
+// tfaRequired reads the NeedTfa, SetupTfa and TokenHash fields of an auth
+// response, such as l8api.AuthToken, and returns the TFARequiredError they
+// describe, or nil if the response has no NeedTfa field or it is false.
+func tfaRequired(tokenVal reflect.Value, user string) *TFARequiredError {
+	needTfa := tokenVal.FieldByName("NeedTfa")
+	if !needTfa.IsValid() || needTfa.Kind() != reflect.Bool || !needTfa.Bool() {
+		return nil
+	}
+	tfa := &TFARequiredError{User: user}
+	if setupTfa := tokenVal.FieldByName("SetupTfa"); setupTfa.IsValid() && setupTfa.Kind() == reflect.Bool {
+		tfa.Setup = setupTfa.Bool()
+	}
+	if tokenHash := tokenVal.FieldByName("TokenHash"); tokenHash.IsValid() && tokenHash.Kind() == reflect.String {
+		tfa.TokenHash = tokenHash.String()
+	}
+	return tfa
+}
+
+// TFASetup starts the TFA setup of user, after Auth returned a
+// TFARequiredError with Setup set. The response holds the secret and the QR
+// code to enroll in an authenticator app; confirm with TFASetupVerify.
+func (rc *RestClient) TFASetup(user string) (*l8api.L8TFASetupR, error) {
+	response := &l8api.L8TFASetupR{}
+	err := rc.postTFA(TFASetupPath, &l8api.L8TFASetup{UserId: user}, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// TFASetupVerify confirms the TFA setup of user with a code from the
+// authenticator app and stores the bearer token the server then issues.
+func (rc *RestClient) TFASetupVerify(user, code string) error {
+	return rc.verifyTFA(TFASetupVerifyPath, user, code)
+}
+
+// TFAVerify completes a login that Auth reported as needing TFA, with a code
+// from the user's authenticator app, and stores the bearer token.
+func (rc *RestClient) TFAVerify(user, code string) error {
+	return rc.verifyTFA(TFAVerifyPath, user, code)
+}
+
+// verifyTFA posts a code to a verification endpoint and stores the token of
+// an accepted one. The token's expiry is unknown, so it is not refreshed.
+func (rc *RestClient) verifyTFA(end, user, code string) error {
+	response := &l8api.L8TFAVerifyR{}
+	err := rc.postTFA(end, &l8api.L8TFAVerify{UserId: user, Code: code}, response)
+	if err != nil {
+		return err
+	}
+	if !response.Ok {
+		return ErrTFAVerification
+	}
+	rc.SetToken(response.Token)
+	return nil
+}
+
+// postTFA posts body to a TFA endpoint and decodes the reply into response.
+func (rc *RestClient) postTFA(end string, body, response proto.Message) error {
+	jsonBytes, err := rc.execute("POST", end, "", body, 5)
+	if err != nil {
+		return err
+	}
+	return protojson.Unmarshal(jsonBytes, response)
+}
@@ -0,0 +1,63 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// RestClientTenant.go lets one client hold bearer tokens for many tenants.
+// Tokens are kept in a TokenStore under a tenant key instead of in the client,
+// and ForTenant returns a lightweight client bound to one key that shares the
+// connections, configuration and circuit breaker of its parent.
+//
+// Example usage:
+//
+//	config.TokenStore = store // e.g. backed by a map, Redis or a vault
+//	client, _ := NewRestClient(config, resources)
+//	acme := client.ForTenant("acme")
+//	if err := acme.Auth(user, pass); err != nil {
+//	    return err
+//	}
+//	response, _ := acme.Do("GET", "/users", "UserList", "", "", nil, 1)
+
+package client
+
+// TokenStore holds bearer tokens by tenant key. It must be safe for
+// concurrent use. Get returns an empty string for a key with no token.
+type TokenStore interface {
+	Get(key string) string
+	Set(key, token string)
+}
+
+// ForTenant returns a client whose Token, SetToken and Auth read and write
+// the TokenStore entry of key; requests it sends carry that tenant's token.
+// It shares its parent's connection pool, configuration and circuit breaker,
+// so it is cheap to create per request. The token expiry and the credentials
+// used to refresh it are kept by the returned client, though: keep it around
+// for tenants whose token should be refreshed before it expires. Without a
+// TokenStore, the returned client keeps its own token.
+//
+// With a CookieJar, cookies set for one tenant are sent for all of them.
+func (rc *RestClient) ForTenant(key string) *RestClient {
+	return &RestClient{
+		RestClientConfig: rc.RestClientConfig,
+		httpClient:       rc.httpClient,
+		resources:        rc.resources,
+		breaker:          rc.breaker,
+		tenant:           key,
+	}
+}
+
+// Tenant returns the tenant key the client's token is stored under, empty
+// for a client not obtained from ForTenant.
+func (rc *RestClient) Tenant() string {
+	return rc.tenant
+}
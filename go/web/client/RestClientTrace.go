@@ -0,0 +1,130 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// RestClientTrace.go breaks the latency of RestClient requests down by phase.
+//
+// When RestClientConfig.OnTrace is set, every request attempt is traced with
+// net/http/httptrace and OnTrace receives a RequestTrace once the response
+// headers arrive or the attempt fails. It tells whether a slow request spent
+// its time resolving the host, connecting, in the TLS handshake or waiting for
+// the server. The trace hooks into the request context, so it works with the
+// built-in transport and with any RestClientConfig.Transport that dials through
+// the standard library.
+
+package client
+
+import (
+	"crypto/tls"
+	nethttp "net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// RequestTrace holds the phase timings of a single request attempt. A phase
+// that did not happen, e.g. the DNS lookup and connect on a reused connection,
+// is zero.
+type RequestTrace struct {
+	Method       string        // HTTP method of the request
+	URL          string        // Full request URL
+	Status       int           // Response status code, 0 if the attempt failed
+	Err          error         // Transport error of a failed attempt
+	Reused       bool          // The request went over a pooled keep-alive connection
+	DNS          time.Duration // Host name resolution
+	Connect      time.Duration // TCP connect
+	TLSHandshake time.Duration // TLS handshake
+	FirstByte    time.Duration // From sending the request to the first response byte (server processing)
+	Total        time.Duration // From the start of the attempt to the response headers
+}
+
+// requestTracer collects the timings of one attempt. The httptrace hooks may
+// run on the transport's dialing goroutines, hence the mutex.
+type requestTracer struct {
+	mtx          sync.Mutex
+	trace        RequestTrace
+	start        time.Time
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+	wroteRequest time.Time
+}
+
+// traced returns request with a client trace attached to its context, and
+// the tracer collecting its timings.
+func traced(request *nethttp.Request) (*nethttp.Request, *requestTracer) {
+	tracer := &requestTracer{start: time.Now()}
+	tracer.trace.Method = request.Method
+	tracer.trace.URL = request.URL.String()
+	clientTrace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			tracer.mark(&tracer.dnsStart)
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			tracer.since(&tracer.dnsStart, &tracer.trace.DNS)
+		},
+		ConnectStart: func(string, string) {
+			tracer.mark(&tracer.connectStart)
+		},
+		ConnectDone: func(string, string, error) {
+			tracer.since(&tracer.connectStart, &tracer.trace.Connect)
+		},
+		TLSHandshakeStart: func() {
+			tracer.mark(&tracer.tlsStart)
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			tracer.since(&tracer.tlsStart, &tracer.trace.TLSHandshake)
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			tracer.mtx.Lock()
+			tracer.trace.Reused = info.Reused
+			tracer.mtx.Unlock()
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			tracer.mark(&tracer.wroteRequest)
+		},
+		GotFirstResponseByte: func() {
+			tracer.since(&tracer.wroteRequest, &tracer.trace.FirstByte)
+		},
+	}
+	return request.WithContext(httptrace.WithClientTrace(request.Context(), clientTrace)), tracer
+}
+
+// mark records the current time in at.
+func (t *requestTracer) mark(at *time.Time) {
+	t.mtx.Lock()
+	*at = time.Now()
+	t.mtx.Unlock()
+}
+
+// since stores the time elapsed from start in phase, unless start was never marked.
+func (t *requestTracer) since(start *time.Time, phase *time.Duration) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	if !start.IsZero() {
+		*phase = time.Since(*start)
+	}
+}
+
+// finish completes the trace with the outcome of the attempt.
+func (t *requestTracer) finish(response *nethttp.Response, err error) RequestTrace {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.trace.Total = time.Since(t.start)
+	t.trace.Err = err
+	if response != nil {
+		t.trace.Status = response.StatusCode
+	}
+	return t.trace
+}
@@ -0,0 +1,72 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// RestClientTyped.go provides generic, type-safe variants of the RestClient
+// request methods. They decode the response directly into the requested
+// Protocol Buffer type, so callers don't need a registry type name or
+// reflection to read response fields.
+//
+// Example usage:
+//
+//	list, err := client.GetAs[*mypb.UserList](rc, "/users", "", "", nil)
+//	if err == nil {
+//	    fmt.Println(len(list.List))
+//	}
+
+package client
+
+import (
+	"google.golang.org/protobuf/proto"
+)
+
+// DoAs executes an HTTP request and decodes the response into a new message of
+// type T. T must be a pointer to a generated Protocol Buffer message (e.g.,
+// *mypb.UserList). It shares the transport, authentication and retry behavior
+// of Do, but doesn't require the response type to be registered.
+func DoAs[T proto.Message](rc *RestClient, method, end, responseAttribute, vars string, pbBody proto.Message) (T, error) {
+	var response T
+	jsonBytes, err := rc.execute(method, end, vars, pbBody, 1)
+	if err != nil {
+		return response, err
+	}
+	response = response.ProtoReflect().New().Interface().(T)
+	err = unmarshalResponse(jsonBytes, responseAttribute, response)
+	return response, err
+}
+
+// GetAs performs an HTTP GET request and returns the response as type T.
+func GetAs[T proto.Message](rc *RestClient, end, responseAttribute, vars string, pbBody proto.Message) (T, error) {
+	return DoAs[T](rc, "GET", end, responseAttribute, vars, pbBody)
+}
+
+// PostAs performs an HTTP POST request and returns the response as type T.
+func PostAs[T proto.Message](rc *RestClient, end, responseAttribute, vars string, pbBody proto.Message) (T, error) {
+	return DoAs[T](rc, "POST", end, responseAttribute, vars, pbBody)
+}
+
+// PutAs performs an HTTP PUT request and returns the response as type T.
+func PutAs[T proto.Message](rc *RestClient, end, responseAttribute, vars string, pbBody proto.Message) (T, error) {
+	return DoAs[T](rc, "PUT", end, responseAttribute, vars, pbBody)
+}
+
+// PatchAs performs an HTTP PATCH request and returns the response as type T.
+func PatchAs[T proto.Message](rc *RestClient, end, responseAttribute, vars string, pbBody proto.Message) (T, error) {
+	return DoAs[T](rc, "PATCH", end, responseAttribute, vars, pbBody)
+}
+
+// DeleteAs performs an HTTP DELETE request and returns the response as type T.
+func DeleteAs[T proto.Message](rc *RestClient, end, responseAttribute, vars string, pbBody proto.Message) (T, error) {
+	return DoAs[T](rc, "DELETE", end, responseAttribute, vars, pbBody)
+}
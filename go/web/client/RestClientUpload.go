@@ -0,0 +1,58 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// RestClientUpload.go streams large request bodies from an io.Reader.
+//
+// Do marshals a Protocol Buffer body into memory before sending it. Upload
+// sends whatever the reader yields as it is read instead, so large files and
+// multipart payloads do not need to be materialized first. Since a reader can
+// only be read once, an upload is never retried.
+
+package client
+
+import (
+	"io"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Upload sends body to end with the given method (usually POST or PUT) and
+// content type, streaming it from the reader, and decodes the response like
+// Do. The request is authenticated like any other; body is closed after the
+// request if it is an io.ReadCloser. The Content-Length is set when body is a
+// *bytes.Buffer, *bytes.Reader or *strings.Reader, otherwise the body is sent
+// chunked. An empty contentType means "application/octet-stream".
+func (rc *RestClient) Upload(method, end string, body io.Reader, contentType, responseType, responseAttribute string) (proto.Message, error) {
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	err := rc.refreshToken(end)
+	if err != nil {
+		return nil, err
+	}
+	request, err := rc.newRequest(method, end, "", body, contentType)
+	if err != nil {
+		return nil, err
+	}
+	response, err := rc.roundTrip(request)
+	if err != nil {
+		return nil, err
+	}
+	jsonBytes, err := rc.readResponse(method, response)
+	if err != nil {
+		return nil, err
+	}
+	return rc.decode(jsonBytes, responseType, responseAttribute)
+}
@@ -23,8 +23,11 @@
 //   - Automatic GraphQL error parsing and reporting
 //   - HTTP/HTTPS with TLS certificate verification
 //   - Bearer token and API key authentication
-//   - GZIP response decompression
+//   - GZIP response decompression, with response bodies capped at MaxResponseBytes
 //   - Automatic retry on timeout (up to 5 attempts with 5-second backoff)
+//   - Optional per-request Timeout, and context cancellation via the *Context methods
+//   - Optional retry with exponential backoff on transient GraphQL error codes
+//   - Optional schema introspection, see Introspect
 //   - Protocol Buffer response mapping via protojson
 //
 // Example usage:
@@ -33,16 +36,18 @@
 //	    Host:     "api.example.com",
 //	    Port:     443,
 //	    Https:    true,
+//	    CertFileName: "/etc/ssl/ca.crt",
 //	    Endpoint: "/graphql",
 //	}
 //	client, _ := NewGraphQLClient(config, resources)
 //	query := `query { users { id name } }`
-//	response, _ := client.Query(query, nil, "UserList", "users")
+//	response, _ := client.Query(query, "", nil, "UserList", "users")
 package gclient
 
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/json"
@@ -50,13 +55,16 @@ import (
 	"fmt"
 	"io"
 	nethttp "net/http"
+	neturl "net/url"
 	"os"
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8web/go/web/internal/unmarshal"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
 )
@@ -68,6 +76,10 @@ type GraphQLClient struct {
 	GraphQLClientConfig                // Embedded configuration
 	httpClient          *nethttp.Client // Underlying HTTP client with TLS config
 	resources           ifs.IResources  // Layer 8 resources for type registry access
+	tokenMtx            sync.RWMutex    // Guards token
+	token               string          // Current bearer token, see Token() and SetToken()
+	cache               *responseCache  // Query response cache, nil unless CacheTTL is set
+	tenant              string          // TokenStore key of the token, see ForTenant
 }
 
 // GraphQLClientConfig contains configuration options for creating a GraphQL client.
@@ -77,12 +89,93 @@ type GraphQLClientConfig struct {
 	Port          int              // Target server port
 	Https         bool             // Enable HTTPS connections
 	TokenRequired bool             // Require bearer token for requests
-	Token         string           // Current bearer token (set by Auth() or manually)
+	Token         string           // Initial bearer token, unused with a TokenStore; afterwards use GraphQLClient.Token() and SetToken()
+	TokenStore    TokenStore       // Optional store keeping tokens per tenant key instead of in the client, see ForTenant
 	CertFileName  string           // Path to CA certificate file for TLS verification
+	AllowInsecure bool             // Skip server certificate verification over HTTPS when CertFileName is not set
 	AuthInfo      *GraphQLAuthInfo // Authentication configuration
 	Endpoint      string           // GraphQL endpoint path (default: "/graphql")
+	Debug         bool             // Print each request URL to stdout (default: off)
+	QueryMethod   string           // HTTP method for Query/QueryProto: "POST" (default) or "GET"; mutations always use POST
+	UserAgent     string           // User-Agent header sent on every request (default: DefaultUserAgent)
+	Timeout       time.Duration    // Max duration of each HTTP request, reading the response included (0 = no limit)
+
+	// Headers are sent on every request (e.g., X-Tenant-ID or tracing
+	// headers). They are set after the client's own headers, so a header
+	// named here replaces the default one.
+	Headers map[string]string
+
+	// RetryOnErrorCodes lists the GraphQL error extension codes (e.g.,
+	// "THROTTLED", "INTERNAL_SERVER_ERROR") that mark a failed operation as
+	// transient: it is retried up to 5 times, waiting ErrorRetryBackoff
+	// (default: DefaultErrorRetryBackoff) before the first retry and twice as
+	// long before each next one. A response is retried only if all its errors
+	// carry a listed code. Codes of errors in the request itself, such as
+	// GRAPHQL_VALIDATION_FAILED, are never retried. Mutations are retried too,
+	// so only list codes that mean the operation did not run.
+	RetryOnErrorCodes []string
+	ErrorRetryBackoff time.Duration
+
+	// Introspection allows Introspect to query the server's schema. It is off
+	// by default, as many production servers disable introspection.
+	Introspection bool
+
+	// ValidateExtension names a request extension that asks the backend to
+	// validate an operation without executing it (e.g., "validateOnly"). When
+	// set, Validate sends the operation with the extension set to true after
+	// the client-side check passes. Leave empty, the default, unless the
+	// backend honors it: Validate then never makes a network call.
+	ValidateExtension string
+
+	// MaxResponseBytes caps the size of a response body, after gzip decompression.
+	// Larger responses fail with ErrResponseTooLarge instead of being read into
+	// memory. Zero means DefaultMaxResponseBytes, a negative value means no limit.
+	MaxResponseBytes int64
+
+	// Transport replaces the built-in HTTP transport, e.g. with a stub
+	// RoundTripper in unit tests. When set, CertFileName and the connection
+	// pool settings are ignored.
+	Transport nethttp.RoundTripper
+
+	// CacheTTL enables an in-memory cache of Query and QueryProto responses:
+	// an identical query (same text, operation, variables and response type)
+	// within CacheTTL is answered from the cache. Zero (the default) disables
+	// it. CacheMaxEntries bounds the cache (default: DefaultCacheMaxEntries).
+	// With CacheInvalidateOnMutate, a successful Mutate or MutateProto empties
+	// the cache; see also InvalidateCache.
+	CacheTTL                time.Duration
+	CacheMaxEntries         int
+	CacheInvalidateOnMutate bool
+
+	MaxIdleConns        int           // Max idle keep-alive connections across all hosts (default: DefaultMaxIdleConns)
+	MaxIdleConnsPerHost int           // Max idle keep-alive connections per host (default: DefaultMaxIdleConnsPerHost)
+	IdleConnTimeout     time.Duration // How long an idle connection is kept for reuse (default: DefaultIdleConnTimeout)
 }
 
+// DefaultUserAgent identifies Layer 8 clients in backend access logs when
+// GraphQLClientConfig.UserAgent is not set.
+const DefaultUserAgent = "l8web-client/1.0"
+
+// DefaultMaxResponseBytes is the response body size limit used when
+// GraphQLClientConfig.MaxResponseBytes is not set.
+const DefaultMaxResponseBytes = 64 << 20
+
+// ErrResponseTooLarge is returned when a response body exceeds MaxResponseBytes.
+var ErrResponseTooLarge = errors.New("response body exceeds MaxResponseBytes")
+
+// ErrInsecureTLS is returned by NewGraphQLClient for an HTTPS client with no
+// CertFileName, unless AllowInsecure opts into skipping verification.
+var ErrInsecureTLS = errors.New("HTTPS without a CA certificate: set CertFileName, or AllowInsecure to skip server certificate verification")
+
+const (
+	// DefaultMaxIdleConns is the default pool size of idle connections across all hosts.
+	DefaultMaxIdleConns = 100
+	// DefaultMaxIdleConnsPerHost is the default pool size of idle connections per host.
+	DefaultMaxIdleConnsPerHost = 10
+	// DefaultIdleConnTimeout is the default time an idle connection is kept for reuse.
+	DefaultIdleConnTimeout = 90 * time.Second
+)
+
 // GraphQLAuthInfo contains authentication configuration for the GraphQL client.
 // Supports two modes: bearer token authentication and API key authentication.
 type GraphQLAuthInfo struct {
@@ -92,16 +185,19 @@ type GraphQLAuthInfo struct {
 	PassField  string // Field name for password in auth request
 	RespType   string // Protocol Buffer type name for auth response
 	TokenField string // Field name containing token in auth response
-	AuthPath   string // Endpoint path for authentication (e.g., "/auth")
+	AuthPath   string // Full endpoint path for authentication, never prefixed (e.g., "/auth", "/api/v1/login")
 	IsAPIKey   bool   // Use API key authentication instead of bearer token
 	ApiUser    string // API user ID (sent as X-USER-ID header)
 	ApiKey     string // API key (sent as X-API-KEY header)
 }
 
-// GraphQLRequest represents a GraphQL operation request with query and optional variables.
+// GraphQLRequest represents a GraphQL operation request with query, optional
+// operation name and optional variables.
 type GraphQLRequest struct {
-	Query     string                 `json:"query"`               // GraphQL query or mutation string
-	Variables map[string]interface{} `json:"variables,omitempty"` // Optional variables for the query
+	Query         string                 `json:"query"`                   // GraphQL query or mutation string
+	OperationName string                 `json:"operationName,omitempty"` // Named operation to run when the query holds several
+	Variables     map[string]interface{} `json:"variables,omitempty"`     // Optional variables for the query
+	Extensions    map[string]interface{} `json:"extensions,omitempty"`    // Optional protocol extensions, see ValidateExtension
 }
 
 // GraphQLResponse represents the standard GraphQL response structure with data and errors.
@@ -118,6 +214,22 @@ type GraphQLError struct {
 	Extensions map[string]interface{} `json:"extensions,omitempty"` // Additional error metadata
 }
 
+// GraphQLErrors is the error returned for a response carrying GraphQL errors.
+// Use errors.As to inspect the individual errors and their extensions.
+type GraphQLErrors []GraphQLError
+
+// Error joins the error messages.
+func (e GraphQLErrors) Error() string {
+	errMsg := "GraphQL errors: "
+	for i, gqlErr := range e {
+		if i > 0 {
+			errMsg += "; "
+		}
+		errMsg += gqlErr.Message
+	}
+	return errMsg
+}
+
 // GraphQLErrorLocation represents the line and column in the query where an error occurred.
 type GraphQLErrorLocation struct {
 	Line   int `json:"line"`   // Line number (1-indexed)
@@ -127,29 +239,66 @@ type GraphQLErrorLocation struct {
 // NewGraphQLClient creates a new GraphQL client with the provided configuration.
 // For HTTPS connections, it configures TLS:
 //   - If CertFileName is provided, it uses that CA certificate for verification
-//   - Otherwise, it uses InsecureSkipVerify (suitable for self-signed certs)
+//   - Otherwise, if AllowInsecure is set, it uses InsecureSkipVerify (suitable
+//     for self-signed certs) and logs a warning, as the connection is open to
+//     man-in-the-middle attacks
+//   - Otherwise, it fails with ErrInsecureTLS
+//
+// Both HTTP and HTTPS share a keep-alive transport tuned by MaxIdleConns,
+// MaxIdleConnsPerHost and IdleConnTimeout, so sequential queries to the same
+// host reuse connections.
+//
+// If Transport is set, it is used as is instead of the built-in transport.
+// Either way, each request is bounded by Timeout, if set.
 //
 // If Endpoint is not specified, it defaults to "/graphql".
-// Returns an error if the certificate file cannot be read.
+// Returns an error if the certificate file cannot be read, or ErrInsecureTLS.
 func NewGraphQLClient(config *GraphQLClientConfig, resources ifs.IResources) (*GraphQLClient, error) {
 	gc := &GraphQLClient{}
 	gc.CertFileName = config.CertFileName
+	gc.AllowInsecure = config.AllowInsecure
 	gc.Host = config.Host
 	gc.Https = config.Https
 	gc.AuthInfo = config.AuthInfo
 	gc.Prefix = config.Prefix
 	gc.Port = config.Port
 	gc.TokenRequired = config.TokenRequired
-	gc.Token = config.Token
+	gc.token = config.Token
+	gc.TokenStore = config.TokenStore
 	gc.resources = resources
 	gc.Endpoint = config.Endpoint
+	gc.Debug = config.Debug
+	gc.QueryMethod = config.QueryMethod
+	gc.UserAgent = config.UserAgent
+	gc.Timeout = config.Timeout
+	gc.Headers = config.Headers
+	gc.ValidateExtension = config.ValidateExtension
+	gc.RetryOnErrorCodes = config.RetryOnErrorCodes
+	gc.ErrorRetryBackoff = config.ErrorRetryBackoff
+	gc.Introspection = config.Introspection
+	if gc.UserAgent == "" {
+		gc.UserAgent = DefaultUserAgent
+	}
+	gc.MaxResponseBytes = config.MaxResponseBytes
+	gc.MaxIdleConns = config.MaxIdleConns
+	gc.MaxIdleConnsPerHost = config.MaxIdleConnsPerHost
+	gc.IdleConnTimeout = config.IdleConnTimeout
+	gc.Transport = config.Transport
+	gc.CacheTTL = config.CacheTTL
+	gc.CacheMaxEntries = config.CacheMaxEntries
+	gc.CacheInvalidateOnMutate = config.CacheInvalidateOnMutate
+	gc.cache = newResponseCache(gc.CacheTTL, gc.CacheMaxEntries)
 	if gc.Endpoint == "" {
 		gc.Endpoint = "/graphql"
 	}
 
-	if !gc.Https {
-		gc.httpClient = &nethttp.Client{}
-	} else {
+	if gc.Transport != nil {
+		gc.httpClient = &nethttp.Client{Transport: gc.Transport, Timeout: gc.Timeout}
+		return gc, nil
+	}
+
+	transport := gc.newTransport()
+	if gc.Https {
 		if gc.CertFileName != "" {
 			caCert, err := os.ReadFile(gc.CertFileName)
 			if err != nil {
@@ -157,32 +306,53 @@ func NewGraphQLClient(config *GraphQLClientConfig, resources ifs.IResources) (*G
 			}
 			caCertPool := x509.NewCertPool()
 			caCertPool.AppendCertsFromPEM(caCert)
-			gc.httpClient = &nethttp.Client{
-				Transport: &nethttp.Transport{
-					TLSClientConfig: &tls.Config{
-						RootCAs:    caCertPool,
-						ClientAuth: tls.NoClientCert,
-						ServerName: gc.Host,
-					},
-				},
+			transport.TLSClientConfig = &tls.Config{
+				RootCAs:    caCertPool,
+				ClientAuth: tls.NoClientCert,
+				ServerName: gc.Host,
 			}
 		} else {
-			gc.httpClient = &nethttp.Client{
-				Transport: &nethttp.Transport{
-					TLSClientConfig: &tls.Config{
-						InsecureSkipVerify: true,
-						ServerName:         gc.Host,
-					},
-				},
+			if !gc.AllowInsecure {
+				return nil, ErrInsecureTLS
+			}
+			if resources != nil {
+				resources.Logger().Warning("GraphQL client to ", gc.Host, " skips TLS certificate verification (AllowInsecure)")
+			}
+			transport.TLSClientConfig = &tls.Config{
+				InsecureSkipVerify: true,
+				ServerName:         gc.Host,
 			}
 		}
 	}
+	gc.httpClient = &nethttp.Client{Transport: transport, Timeout: gc.Timeout}
 
 	return gc, nil
 }
 
+// newTransport creates the keep-alive transport used for both HTTP and HTTPS,
+// starting from Go's default transport (proxy from environment, dial timeouts)
+// and applying the configured idle connection pool settings.
+func (gc *GraphQLClient) newTransport() *nethttp.Transport {
+	transport := nethttp.DefaultTransport.(*nethttp.Transport).Clone()
+	transport.MaxIdleConns = DefaultMaxIdleConns
+	if gc.MaxIdleConns > 0 {
+		transport.MaxIdleConns = gc.MaxIdleConns
+	}
+	transport.MaxIdleConnsPerHost = DefaultMaxIdleConnsPerHost
+	if gc.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = gc.MaxIdleConnsPerHost
+	}
+	transport.IdleConnTimeout = DefaultIdleConnTimeout
+	if gc.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = gc.IdleConnTimeout
+	}
+	return transport
+}
+
 // buildURL constructs the full URL for a GraphQL request, combining the host,
-// port, prefix, and endpoint. The prefix is not added for the /auth endpoint.
+// port, prefix, and endpoint. The configured AuthInfo.AuthPath is a full path
+// and is used verbatim; every other endpoint gets the prefix. The URL is
+// printed only when Debug is enabled.
 func (gc *GraphQLClient) buildURL(end string) string {
 	url := bytes.Buffer{}
 	url.WriteString("http")
@@ -193,57 +363,87 @@ func (gc *GraphQLClient) buildURL(end string) string {
 	url.WriteString(gc.Host)
 	url.WriteString(":")
 	url.WriteString(strconv.Itoa(gc.Port))
-	if gc.Prefix != "" && end != "/auth" {
+	if gc.Prefix != "" && !gc.isAuthPath(end) {
 		url.WriteString(gc.Prefix)
 	}
 	url.WriteString(end)
-	fmt.Println("GraphQL Client URL:", url.String())
+	if gc.Debug {
+		fmt.Println("GraphQL Client URL:", url.String())
+	}
 	return url.String()
 }
 
-// request creates an HTTP POST request for a GraphQL operation with proper headers.
-// It marshals the GraphQL request to JSON, sets Authorization header if a token
-// is available, and adds API key headers if configured.
+// request creates an HTTP request for a GraphQL operation with proper headers.
+// For POST it marshals the GraphQL request to JSON as the body; for GET it
+// URL-encodes query, operationName and variables as query parameters.
+// It sets Authorization header if a token is available, and adds API key
+// headers if configured.
 // Panics if TokenRequired is true but no token is available for non-auth endpoints.
-func (gc *GraphQLClient) request(end string, gqlRequest *GraphQLRequest) (*nethttp.Request, error) {
-	body, err := json.Marshal(gqlRequest)
-	if err != nil {
-		return nil, err
-	}
-
+func (gc *GraphQLClient) request(method, end string, gqlRequest *GraphQLRequest) (*nethttp.Request, error) {
+	var request *nethttp.Request
 	url := gc.buildURL(end)
-	request, err := nethttp.NewRequest("POST", url, bytes.NewReader(body))
-	if err != nil {
-		return nil, err
+	if method == nethttp.MethodGet {
+		params := neturl.Values{}
+		params.Set("query", gqlRequest.Query)
+		if gqlRequest.OperationName != "" {
+			params.Set("operationName", gqlRequest.OperationName)
+		}
+		if gqlRequest.Variables != nil {
+			vars, err := json.Marshal(gqlRequest.Variables)
+			if err != nil {
+				return nil, err
+			}
+			params.Set("variables", string(vars))
+		}
+		req, err := nethttp.NewRequest(method, url+"?"+params.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		request = req
+	} else {
+		body, err := json.Marshal(gqlRequest)
+		if err != nil {
+			return nil, err
+		}
+		req, err := nethttp.NewRequest(nethttp.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Add("content-type", "application/json")
+		request = req
 	}
+	gc.setHeaders(request, end)
+	return request, nil
+}
 
-	if gc.TokenRequired && gc.Token == "" && gc.Https && !gc.isAuthPath(end) {
+// setHeaders adds the Authorization, Accept, User-Agent and API key headers shared by all
+// GraphQL requests, then the configured Headers.
+// Panics if TokenRequired is true but no token is available for non-auth endpoints.
+func (gc *GraphQLClient) setHeaders(request *nethttp.Request, end string) {
+	token := gc.Token()
+	if gc.TokenRequired && token == "" && gc.Https && !gc.isAuthPath(end) {
 		panic("No token with secure connection!")
 	}
 
-	if gc.TokenRequired && gc.Token != "" {
-		request.Header.Set("Authorization", "Bearer "+gc.Token)
+	if gc.TokenRequired && token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
 	}
-	request.Header.Add("content-type", "application/json")
 	request.Header.Add("Accept", "application/json, text/plain, */*")
+	request.Header.Set("User-Agent", gc.UserAgent)
 	request.Header.Add("Access-Control-Allow-Origin", "*")
 	if gc.AuthInfo != nil && gc.AuthInfo.IsAPIKey {
 		request.Header.Add("X-USER-ID", gc.AuthInfo.ApiUser)
 		request.Header.Add("X-API-KEY", gc.AuthInfo.ApiKey)
 	}
-	return request, nil
+	for name, value := range gc.Headers {
+		request.Header.Set(name, value)
+	}
 }
 
-// isAuthPath checks if the endpoint is the configured authentication path.
-// Used to skip token requirements for the auth endpoint itself.
+// isAuthPath checks if the endpoint is exactly the configured authentication
+// path. It needs no token and buildURL uses it as-is without the prefix.
 func (gc *GraphQLClient) isAuthPath(end string) bool {
-	if gc.AuthInfo == nil {
-		return false
-	}
-	if strings.HasSuffix(gc.AuthInfo.AuthPath, end) {
-		return true
-	}
-	return false
+	return gc.AuthInfo != nil && gc.AuthInfo.AuthPath != "" && end == gc.AuthInfo.AuthPath
 }
 
 // is200 checks if an HTTP status string represents a successful response (2xx).
@@ -276,7 +476,8 @@ func isTimeout(err error) bool {
 // Auth performs authentication using a GraphQL login mutation.
 // It constructs a login mutation based on AuthInfo configuration, executes it,
 // and extracts the bearer token from the response. The token is stored in
-// gc.Token for use in subsequent requests.
+// the client, or in its tenant's TokenStore entry (see Token()), for use in
+// subsequent requests.
 //
 // The generated mutation format is:
 // mutation { login(input: { user: "...", pass: "..." }) { token } }
@@ -310,7 +511,7 @@ func (gc *GraphQLClient) Auth(user, pass string) error {
 		pass,
 		strings.ToLower(gc.AuthInfo.TokenField[:1])+gc.AuthInfo.TokenField[1:])
 
-	token, err := gc.Execute(authQuery, nil, gc.AuthInfo.RespType, gc.AuthInfo.TokenField, 5)
+	token, err := gc.Execute(authQuery, "", nil, gc.AuthInfo.RespType, gc.AuthInfo.TokenField, 5)
 	if err != nil {
 		return err
 	}
@@ -325,52 +526,150 @@ func (gc *GraphQLClient) Auth(user, pass string) error {
 		return errors.New("invalid token field value, should be string")
 	}
 
-	gc.Token = t
+	gc.SetToken(t)
 	return nil
 }
 
+// Token returns the current bearer token, read from the TokenStore entry of
+// the client's tenant if a store is configured. Safe for concurrent use.
+func (gc *GraphQLClient) Token() string {
+	if gc.TokenStore != nil {
+		return gc.TokenStore.Get(gc.tenant)
+	}
+	gc.tokenMtx.RLock()
+	defer gc.tokenMtx.RUnlock()
+	return gc.token
+}
+
+// SetToken replaces the bearer token, in the TokenStore entry of the client's
+// tenant if a store is configured. Safe for concurrent use with in-flight requests.
+func (gc *GraphQLClient) SetToken(token string) {
+	if gc.TokenStore != nil {
+		gc.TokenStore.Set(gc.tenant, token)
+		return
+	}
+	gc.tokenMtx.Lock()
+	defer gc.tokenMtx.Unlock()
+	gc.token = token
+}
+
 // Execute sends a GraphQL query or mutation and returns the response as a Protocol Buffer.
 //
 // Parameters:
 //   - query: GraphQL query or mutation string
+//   - operationName: Operation to run when query contains several named operations (empty for single-operation documents)
 //   - variables: Optional map of variables for parameterized queries
 //   - responseType: Protocol Buffer type name for deserializing the response
-//   - responseAttribute: Field name to extract from the "data" object (e.g., "users" for data.users)
+//   - responseAttribute: Field name to extract from the "data" object (e.g., "users" for data.users),
+//     or a dotted path for deeper nesting (e.g., "viewer.projects" for data.viewer.projects)
 //   - tryCount: Current retry attempt (starts at 1, max 5)
 //
-// Handles GZIP response decompression automatically. Parses GraphQL errors and returns
-// them as Go errors. Retries on timeout errors up to 5 times with 5-second backoff.
-func (gc *GraphQLClient) Execute(query string, variables map[string]interface{}, responseType, responseAttribute string, tryCount int) (proto.Message, error) {
+// The request is always sent as POST. Handles GZIP response decompression automatically.
+// Parses GraphQL errors and returns them as GraphQLErrors. Data that doesn't match
+// responseType is reported as *UnmarshalError. Retries on timeout errors up to
+// 5 times with 5-second backoff, and on the GraphQL error codes listed in
+// RetryOnErrorCodes.
+func (gc *GraphQLClient) Execute(query, operationName string, variables map[string]interface{}, responseType, responseAttribute string, tryCount int) (proto.Message, error) {
+	return gc.ExecuteContext(context.Background(), query, operationName, variables, responseType, responseAttribute, tryCount)
+}
+
+// ExecuteContext is Execute bound to ctx: canceling ctx, or reaching its
+// deadline, aborts the request in flight and any further retry.
+func (gc *GraphQLClient) ExecuteContext(ctx context.Context, query, operationName string, variables map[string]interface{}, responseType, responseAttribute string, tryCount int) (proto.Message, error) {
+	return gc.execute(ctx, nethttp.MethodPost, query, operationName, variables, responseType, responseAttribute, tryCount)
+}
+
+// queryMethod returns the HTTP method used for read-only queries, GET when
+// QueryMethod is configured as such and POST otherwise.
+func (gc *GraphQLClient) queryMethod() string {
+	if strings.EqualFold(gc.QueryMethod, nethttp.MethodGet) {
+		return nethttp.MethodGet
+	}
+	return nethttp.MethodPost
+}
+
+// execute sends a GraphQL operation with the given HTTP method and decodes the
+// response. It backs Execute, Query and Mutate.
+func (gc *GraphQLClient) execute(ctx context.Context, method, query, operationName string, variables map[string]interface{}, responseType, responseAttribute string, tryCount int) (proto.Message, error) {
 	gqlRequest := &GraphQLRequest{
-		Query:     query,
-		Variables: variables,
+		Query:         query,
+		OperationName: operationName,
+		Variables:     variables,
 	}
 
-	request, err := gc.request(gc.Endpoint, gqlRequest)
+	request, err := gc.request(method, gc.Endpoint, gqlRequest)
 	if err != nil {
 		return nil, err
 	}
 
 	// Execute the request
-	response, err := gc.httpClient.Do(request)
+	response, err := gc.httpClient.Do(request.WithContext(ctx))
 	if err != nil {
-		if isTimeout(err) {
+		if ctx.Err() == nil && isTimeout(err) {
 			if tryCount <= 5 {
-				return gc.Execute(query, variables, responseType, responseAttribute, tryCount+1)
+				return gc.execute(ctx, method, query, operationName, variables, responseType, responseAttribute, tryCount+1)
 			}
 		}
 		return nil, err
 	}
+	message, err := gc.readResponse(response, responseType, responseAttribute)
+	var gqlErrors GraphQLErrors
+	if errors.As(err, &gqlErrors) && tryCount <= 5 && gc.retryOnErrors(gqlErrors) {
+		if sleepErr := sleepContext(ctx, gc.errorRetryDelay(tryCount)); sleepErr != nil {
+			return nil, sleepErr
+		}
+		return gc.execute(ctx, method, query, operationName, variables, responseType, responseAttribute, tryCount+1)
+	}
+	return message, err
+}
+
+// readResponse reads a GraphQL HTTP response, decompressing GZIP if needed,
+// checks the status and GraphQL errors, and maps the data to responseType.
+func (gc *GraphQLClient) readResponse(response *nethttp.Response, responseType, responseAttribute string) (proto.Message, error) {
+	dataBytes, err := gc.readData(response)
+	if err != nil {
+		return nil, err
+	}
+
+	if responseType == "" {
+		return nil, nil
+	}
+
+	info, err := gc.resources.Registry().Info(responseType)
+	if err != nil {
+		return nil, err
+	}
+	_interface, err := info.NewInstance()
+	if err != nil {
+		return nil, err
+	}
+
+	responsePb := _interface.(proto.Message)
+
+	if responseAttribute != "" {
+		// Extract nested field from data, following dotted paths (e.g., "viewer.projects")
+		dataBytes, err = extractAttribute(dataBytes, responseAttribute)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = protojson.Unmarshal(dataBytes, responsePb)
+	if err != nil {
+		return responsePb, unmarshal.NewError(dataBytes, responsePb, err)
+	}
+	return responsePb, nil
+}
 
-	var jsonBytes []byte
+// readData reads a GraphQL HTTP response, decompressing GZIP if needed, and
+// returns its data once the status and GraphQL errors are checked.
+func (gc *GraphQLClient) readData(response *nethttp.Response) (json.RawMessage, error) {
+	// Closing the body returns the connection to the keep-alive pool
+	defer response.Body.Close()
 
-	switch response.Header.Get("Content-Encoding") {
-	case "gzip":
-		reader, _ := gzip.NewReader(response.Body)
-		jsonBytes, _ = io.ReadAll(reader)
-		defer reader.Close()
-	default:
-		jsonBytes, _ = io.ReadAll(response.Body)
+	jsonBytes, err := readBody(response, gc.MaxResponseBytes)
+	if err != nil {
+		return nil, err
 	}
 
 	ok, err := is200(response.Status)
@@ -390,75 +689,144 @@ func (gc *GraphQLClient) Execute(query string, variables map[string]interface{},
 
 	// Check for GraphQL errors
 	if len(gqlResponse.Errors) > 0 {
-		errMsg := "GraphQL errors: "
-		for i, gqlErr := range gqlResponse.Errors {
-			if i > 0 {
-				errMsg += "; "
-			}
-			errMsg += gqlErr.Message
-		}
-		return nil, errors.New(errMsg)
+		return nil, GraphQLErrors(gqlResponse.Errors)
 	}
+	return gqlResponse.Data, nil
+}
 
-	if responseType == "" {
-		return nil, nil
+// readBody reads the response body, decompressing GZIP if needed, and fails
+// with ErrResponseTooLarge once more than limit bytes are read. A zero limit
+// means DefaultMaxResponseBytes and a negative one no limit.
+func readBody(response *nethttp.Response, limit int64) ([]byte, error) {
+	var reader io.Reader = response.Body
+	if response.Header.Get("Content-Encoding") == "gzip" {
+		gzReader, err := gzip.NewReader(response.Body)
+		if err != nil {
+			return nil, err
+		}
+		defer gzReader.Close()
+		reader = gzReader
 	}
-
-	info, err := gc.resources.Registry().Info(responseType)
-	if err != nil {
-		return nil, err
+	if limit == 0 {
+		limit = DefaultMaxResponseBytes
 	}
-	_interface, err := info.NewInstance()
+	if limit < 0 {
+		return io.ReadAll(reader)
+	}
+	// Read one byte past the limit to tell a body of exactly limit bytes
+	// from a larger one.
+	data, err := io.ReadAll(io.LimitReader(reader, limit+1))
 	if err != nil {
 		return nil, err
 	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%w (%d bytes)", ErrResponseTooLarge, limit)
+	}
+	return data, nil
+}
 
-	responsePb := _interface.(proto.Message)
-
-	// Extract the data field
-	dataBytes := gqlResponse.Data
-	if responseAttribute != "" {
-		// Extract nested field from data
+// extractAttribute walks the "data" JSON following a dotted attribute path
+// (e.g., "viewer.projects" for data.viewer.projects) and returns the nested value.
+// The error names the first segment that is missing or whose parent is not an object.
+func extractAttribute(data json.RawMessage, responseAttribute string) (json.RawMessage, error) {
+	for _, segment := range strings.Split(responseAttribute, ".") {
 		var dataMap map[string]json.RawMessage
-		err = json.Unmarshal(dataBytes, &dataMap)
-		if err != nil {
-			return nil, err
+		if err := json.Unmarshal(data, &dataMap); err != nil {
+			return nil, errors.New("response attribute '" + responseAttribute + "': cannot read segment '" + segment + "', parent is not an object")
 		}
-		if attrData, ok := dataMap[responseAttribute]; ok {
-			dataBytes = attrData
-		} else {
-			return nil, errors.New("response attribute '" + responseAttribute + "' not found in GraphQL response")
+		attrData, ok := dataMap[segment]
+		if !ok {
+			return nil, errors.New("response attribute '" + responseAttribute + "': segment '" + segment + "' not found in GraphQL response")
 		}
+		data = attrData
 	}
-
-	err = protojson.Unmarshal(dataBytes, responsePb)
-	if err != nil {
-		fmt.Println(string(dataBytes))
-	}
-	return responsePb, err
+	return data, nil
 }
 
 // Query executes a GraphQL query and returns the response as a Protocol Buffer.
-// Convenience wrapper for Execute() that starts with tryCount=1.
+// Convenience wrapper for Execute() that starts with tryCount=1. The query is
+// sent as GET when QueryMethod is "GET", which lets CDNs cache public reads.
+// With CacheTTL set, a response is reused for identical queries until it expires.
 //
 // Example:
 //
 //	query := `query GetUsers($limit: Int!) { users(limit: $limit) { id name } }`
 //	vars := map[string]interface{}{"limit": 10}
-//	response, _ := client.Query(query, vars, "UserList", "users")
-func (gc *GraphQLClient) Query(query string, variables map[string]interface{}, responseType, responseAttribute string) (proto.Message, error) {
-	return gc.Execute(query, variables, responseType, responseAttribute, 1)
+//	response, _ := client.Query(query, "GetUsers", vars, "UserList", "users")
+func (gc *GraphQLClient) Query(query, operationName string, variables map[string]interface{}, responseType, responseAttribute string) (proto.Message, error) {
+	return gc.query(context.Background(), query, operationName, variables, responseType, responseAttribute)
+}
+
+// QueryContext is Query bound to ctx, see ExecuteContext.
+func (gc *GraphQLClient) QueryContext(ctx context.Context, query, operationName string, variables map[string]interface{}, responseType, responseAttribute string) (proto.Message, error) {
+	return gc.query(ctx, query, operationName, variables, responseType, responseAttribute)
 }
 
 // Mutate executes a GraphQL mutation and returns the response as a Protocol Buffer.
 // Convenience wrapper for Execute() that starts with tryCount=1.
-// Semantically identical to Query() but named for clarity when performing mutations.
+// Identical to Query() except that mutations are always sent as POST.
 //
 // Example:
 //
 //	mutation := `mutation CreateUser($input: UserInput!) { createUser(input: $input) { id } }`
 //	vars := map[string]interface{}{"input": map[string]interface{}{"name": "John"}}
-//	response, _ := client.Mutate(mutation, vars, "User", "createUser")
-func (gc *GraphQLClient) Mutate(mutation string, variables map[string]interface{}, responseType, responseAttribute string) (proto.Message, error) {
-	return gc.Execute(mutation, variables, responseType, responseAttribute, 1)
+//	response, _ := client.Mutate(mutation, "CreateUser", vars, "User", "createUser")
+func (gc *GraphQLClient) Mutate(mutation, operationName string, variables map[string]interface{}, responseType, responseAttribute string) (proto.Message, error) {
+	return gc.mutate(context.Background(), mutation, operationName, variables, responseType, responseAttribute)
+}
+
+// MutateContext is Mutate bound to ctx, see ExecuteContext.
+func (gc *GraphQLClient) MutateContext(ctx context.Context, mutation, operationName string, variables map[string]interface{}, responseType, responseAttribute string) (proto.Message, error) {
+	return gc.mutate(ctx, mutation, operationName, variables, responseType, responseAttribute)
+}
+
+// QueryProto executes a GraphQL query whose variables are taken from a typed
+// Protocol Buffer message instead of a hand-built map. Each top-level field of
+// variables becomes a GraphQL variable, named in lowerCamelCase as protojson
+// emits it (e.g., user_id becomes $userId), matching how response fields are mapped.
+// Like Query, it honors QueryMethod.
+//
+// Example:
+//
+//	query := `query GetUser($userId: ID!) { user(id: $userId) { id name } }`
+//	response, _ := client.QueryProto(query, "GetUser", &mypb.UserFilter{UserId: "u1"}, "User", "user")
+func (gc *GraphQLClient) QueryProto(query, operationName string, variables proto.Message, responseType, responseAttribute string) (proto.Message, error) {
+	vars, err := protoToVariables(variables)
+	if err != nil {
+		return nil, err
+	}
+	return gc.query(context.Background(), query, operationName, vars, responseType, responseAttribute)
+}
+
+// MutateProto executes a GraphQL mutation whose variables are taken from a
+// typed Protocol Buffer message. See QueryProto for how fields are named.
+//
+// Example:
+//
+//	mutation := `mutation CreateUser($name: String!, $email: String!) { createUser(name: $name, email: $email) { id } }`
+//	response, _ := client.MutateProto(mutation, "CreateUser", &mypb.UserInput{Name: "John", Email: "j@x.com"}, "User", "createUser")
+func (gc *GraphQLClient) MutateProto(mutation, operationName string, variables proto.Message, responseType, responseAttribute string) (proto.Message, error) {
+	vars, err := protoToVariables(variables)
+	if err != nil {
+		return nil, err
+	}
+	return gc.mutate(context.Background(), mutation, operationName, vars, responseType, responseAttribute)
+}
+
+// protoToVariables converts a Protocol Buffer message into a GraphQL variables
+// object using protojson's lowerCamelCase field names. Returns nil for a nil message.
+func protoToVariables(variables proto.Message) (map[string]interface{}, error) {
+	if variables == nil {
+		return nil, nil
+	}
+	jsonBytes, err := protojson.Marshal(variables)
+	if err != nil {
+		return nil, err
+	}
+	vars := map[string]interface{}{}
+	err = json.Unmarshal(jsonBytes, &vars)
+	if err != nil {
+		return nil, err
+	}
+	return vars, nil
 }
@@ -0,0 +1,176 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// GraphQLClientCache.go caches query responses in memory, so repeated
+// identical reads within GraphQLClientConfig.CacheTTL skip the network.
+//
+// Entries are keyed by a SHA-256 hash of the tenant (see ForTenant), query
+// text, operation name, variables and the requested response type and
+// attribute. Only Query and QueryProto use the cache; Execute, Mutate and
+// MutateProto always go to the server, and with CacheInvalidateOnMutate a
+// successful mutation empties it. Cached messages are cloned in and out, so
+// callers may modify what they get.
+
+package gclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// DefaultCacheMaxEntries is the number of responses kept when
+// GraphQLClientConfig.CacheMaxEntries is not set.
+const DefaultCacheMaxEntries = 1000
+
+// responseCache is a TTL cache of decoded query responses.
+type responseCache struct {
+	ttl        time.Duration
+	maxEntries int
+	mtx        sync.Mutex // Guards entries
+	entries    map[string]*cacheEntry
+}
+
+// cacheEntry is a cached response and when it was stored.
+type cacheEntry struct {
+	message proto.Message
+	stored  time.Time
+}
+
+// newResponseCache creates a cache keeping up to maxEntries responses, or
+// DefaultCacheMaxEntries if not positive, for ttl. It returns nil, a cache
+// that never hits, when ttl is not positive.
+func newResponseCache(ttl time.Duration, maxEntries int) *responseCache {
+	if ttl <= 0 {
+		return nil
+	}
+	if maxEntries <= 0 {
+		maxEntries = DefaultCacheMaxEntries
+	}
+	return &responseCache{ttl: ttl, maxEntries: maxEntries, entries: map[string]*cacheEntry{}}
+}
+
+// cacheKey hashes everything a query response depends on. json.Marshal sorts
+// map keys, so equal variables always hash the same.
+func cacheKey(tenant, query, operationName string, variables map[string]interface{}, responseType, responseAttribute string) (string, error) {
+	data, err := json.Marshal([]interface{}{tenant, query, operationName, variables, responseType, responseAttribute})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// get returns a copy of the response cached under key, if it has not expired.
+func (c *responseCache) get(key string) (proto.Message, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Since(entry.stored) > c.ttl {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return proto.Clone(entry.message), true
+}
+
+// put caches a copy of message under key. When the cache is full, expired
+// entries are dropped first, then the oldest one.
+func (c *responseCache) put(key string, message proto.Message) {
+	if c == nil || message == nil {
+		return
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.maxEntries {
+		c.evict()
+	}
+	c.entries[key] = &cacheEntry{message: proto.Clone(message), stored: time.Now()}
+}
+
+// evict drops the expired entries, or the oldest entry if none has expired.
+// Must be called with mtx held.
+func (c *responseCache) evict() {
+	oldestKey := ""
+	var oldest time.Time
+	for key, entry := range c.entries {
+		if time.Since(entry.stored) > c.ttl {
+			delete(c.entries, key)
+			continue
+		}
+		if oldestKey == "" || entry.stored.Before(oldest) {
+			oldestKey, oldest = key, entry.stored
+		}
+	}
+	if len(c.entries) >= c.maxEntries {
+		delete(c.entries, oldestKey)
+	}
+}
+
+// clear drops every cached response.
+func (c *responseCache) clear() {
+	if c == nil {
+		return
+	}
+	c.mtx.Lock()
+	c.entries = map[string]*cacheEntry{}
+	c.mtx.Unlock()
+}
+
+// InvalidateCache drops every cached query response, e.g. after a change made
+// outside this client. It is a no-op when caching is disabled.
+func (gc *GraphQLClient) InvalidateCache() {
+	gc.cache.clear()
+}
+
+// query runs a read-only query through the cache: a cached response is
+// returned without a round trip, and a successful response is cached.
+func (gc *GraphQLClient) query(ctx context.Context, query, operationName string, variables map[string]interface{}, responseType, responseAttribute string) (proto.Message, error) {
+	if gc.cache == nil {
+		return gc.execute(ctx, gc.queryMethod(), query, operationName, variables, responseType, responseAttribute, 1)
+	}
+	key, err := cacheKey(gc.tenant, query, operationName, variables, responseType, responseAttribute)
+	if err != nil {
+		return nil, err
+	}
+	if message, ok := gc.cache.get(key); ok {
+		return message, nil
+	}
+	message, err := gc.execute(ctx, gc.queryMethod(), query, operationName, variables, responseType, responseAttribute, 1)
+	if err == nil {
+		gc.cache.put(key, message)
+	}
+	return message, err
+}
+
+// mutate executes a mutation, emptying the cache after a successful one when
+// CacheInvalidateOnMutate is set.
+func (gc *GraphQLClient) mutate(ctx context.Context, mutation, operationName string, variables map[string]interface{}, responseType, responseAttribute string) (proto.Message, error) {
+	message, err := gc.ExecuteContext(ctx, mutation, operationName, variables, responseType, responseAttribute, 1)
+	if err == nil && gc.CacheInvalidateOnMutate {
+		gc.cache.clear()
+	}
+	return message, err
+}
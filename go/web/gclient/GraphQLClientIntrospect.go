@@ -0,0 +1,229 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// GraphQLClientIntrospect.go fetches the server's schema with the standard
+// GraphQL introspection query, for tooling that checks operations or
+// generates typed helpers. Many production servers disable introspection, so
+// the client only sends it when GraphQLClientConfig.Introspection is set.
+//
+// Example usage:
+//
+//	schema, err := client.Introspect()
+//	if err != nil {
+//	    return err
+//	}
+//	if user := schema.Type("User"); user != nil && user.Field("email") == nil {
+//	    return errors.New("server has no User.email")
+//	}
+
+package gclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	nethttp "net/http"
+)
+
+// ErrIntrospectionDisabled is returned by Introspect when the client is not
+// configured with Introspection.
+var ErrIntrospectionDisabled = errors.New("GraphQL introspection is disabled: set Introspection in GraphQLClientConfig")
+
+// ErrIntrospectionUnavailable is returned, wrapping the server's reason, when
+// the server rejects the introspection query or answers it without a schema,
+// as servers with introspection disabled do.
+var ErrIntrospectionUnavailable = errors.New("GraphQL introspection is not available on the server")
+
+// introspectionQuery is the standard introspection query, without directives.
+// TypeRef unwraps up to seven levels of List and NonNull wrappers.
+const introspectionQuery = `query IntrospectionQuery {
+  __schema {
+    queryType { name }
+    mutationType { name }
+    subscriptionType { name }
+    types { ...FullType }
+  }
+}
+
+fragment FullType on __Type {
+  kind
+  name
+  description
+  fields(includeDeprecated: true) {
+    name
+    description
+    args { ...InputValue }
+    type { ...TypeRef }
+    isDeprecated
+    deprecationReason
+  }
+  inputFields { ...InputValue }
+  interfaces { ...TypeRef }
+  enumValues(includeDeprecated: true) {
+    name
+    description
+    isDeprecated
+    deprecationReason
+  }
+  possibleTypes { ...TypeRef }
+}
+
+fragment InputValue on __InputValue {
+  name
+  description
+  type { ...TypeRef }
+  defaultValue
+}
+
+fragment TypeRef on __Type {
+  kind
+  name
+  ofType { kind name ofType { kind name ofType { kind name ofType { kind name
+    ofType { kind name ofType { kind name ofType { kind name } } } } } } }
+}`
+
+// Schema is a GraphQL schema as reported by introspection.
+type Schema struct {
+	QueryType        *TypeRef     `json:"queryType"`        // Root query type
+	MutationType     *TypeRef     `json:"mutationType"`     // Root mutation type, nil if none
+	SubscriptionType *TypeRef     `json:"subscriptionType"` // Root subscription type, nil if none
+	Types            []SchemaType `json:"types"`            // All named types, built-in ones included
+}
+
+// SchemaType is a named type of a schema. Which fields are set depends on Kind:
+// Fields and Interfaces for OBJECT and INTERFACE, InputFields for
+// INPUT_OBJECT, EnumValues for ENUM and PossibleTypes for INTERFACE and UNION.
+type SchemaType struct {
+	Kind          string             `json:"kind"` // OBJECT, SCALAR, ENUM, INPUT_OBJECT, INTERFACE or UNION
+	Name          string             `json:"name"`
+	Description   string             `json:"description"`
+	Fields        []SchemaField      `json:"fields"`
+	InputFields   []SchemaInputValue `json:"inputFields"`
+	Interfaces    []TypeRef          `json:"interfaces"`
+	EnumValues    []SchemaEnumValue  `json:"enumValues"`
+	PossibleTypes []TypeRef          `json:"possibleTypes"`
+}
+
+// SchemaField is a field of an object or interface type.
+type SchemaField struct {
+	Name              string             `json:"name"`
+	Description       string             `json:"description"`
+	Args              []SchemaInputValue `json:"args"`
+	Type              TypeRef            `json:"type"`
+	IsDeprecated      bool               `json:"isDeprecated"`
+	DeprecationReason string             `json:"deprecationReason"`
+}
+
+// SchemaInputValue is a field argument or an input object field.
+type SchemaInputValue struct {
+	Name         string  `json:"name"`
+	Description  string  `json:"description"`
+	Type         TypeRef `json:"type"`
+	DefaultValue *string `json:"defaultValue"` // GraphQL literal of the default, nil if none
+}
+
+// SchemaEnumValue is a value of an enum type.
+type SchemaEnumValue struct {
+	Name              string `json:"name"`
+	Description       string `json:"description"`
+	IsDeprecated      bool   `json:"isDeprecated"`
+	DeprecationReason string `json:"deprecationReason"`
+}
+
+// TypeRef references a type, possibly wrapped: a LIST or NON_NULL reference
+// has no name and wraps OfType.
+type TypeRef struct {
+	Kind   string   `json:"kind"`
+	Name   string   `json:"name"`
+	OfType *TypeRef `json:"ofType"`
+}
+
+// String renders the reference in GraphQL notation, e.g. "[User!]!".
+func (t *TypeRef) String() string {
+	switch {
+	case t.Kind == "NON_NULL" && t.OfType != nil:
+		return t.OfType.String() + "!"
+	case t.Kind == "LIST" && t.OfType != nil:
+		return "[" + t.OfType.String() + "]"
+	}
+	return t.Name
+}
+
+// NamedType returns the name of the type under any LIST and NON_NULL wrappers.
+func (t *TypeRef) NamedType() string {
+	for t.OfType != nil && t.Name == "" {
+		t = t.OfType
+	}
+	return t.Name
+}
+
+// Type returns the named type, or nil if the schema has none by that name.
+func (s *Schema) Type(name string) *SchemaType {
+	for i := range s.Types {
+		if s.Types[i].Name == name {
+			return &s.Types[i]
+		}
+	}
+	return nil
+}
+
+// Field returns the named field of the type, or nil if it has none.
+func (t *SchemaType) Field(name string) *SchemaField {
+	for i := range t.Fields {
+		if t.Fields[i].Name == name {
+			return &t.Fields[i]
+		}
+	}
+	return nil
+}
+
+// Introspect sends the standard introspection query and returns the server's
+// schema. It fails with ErrIntrospectionDisabled unless Introspection is
+// set, and with an error wrapping ErrIntrospectionUnavailable when the server
+// refuses to introspect.
+func (gc *GraphQLClient) Introspect() (*Schema, error) {
+	if !gc.Introspection {
+		return nil, ErrIntrospectionDisabled
+	}
+
+	gqlRequest := &GraphQLRequest{Query: introspectionQuery, OperationName: "IntrospectionQuery"}
+	request, err := gc.request(nethttp.MethodPost, gc.Endpoint, gqlRequest)
+	if err != nil {
+		return nil, err
+	}
+	response, err := gc.httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	data, err := gc.readData(response)
+	var gqlErrors GraphQLErrors
+	if errors.As(err, &gqlErrors) {
+		return nil, fmt.Errorf("%w: %v", ErrIntrospectionUnavailable, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Schema *Schema `json:"__schema"`
+	}
+	if err = json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	if result.Schema == nil {
+		return nil, fmt.Errorf("%w: the response holds no schema", ErrIntrospectionUnavailable)
+	}
+	return result.Schema, nil
+}
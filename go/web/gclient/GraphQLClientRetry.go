@@ -0,0 +1,104 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// GraphQLClientRetry.go retries operations that a server failed with a
+// transient GraphQL error, such as a THROTTLED or INTERNAL_SERVER_ERROR code
+// in the error's extensions, rather than with a network error. The codes to
+// retry are opted into with GraphQLClientConfig.RetryOnErrorCodes; errors
+// caused by the request itself are never retried.
+
+package gclient
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultErrorRetryBackoff is the delay before the first retry of a
+// transient GraphQL error when GraphQLClientConfig.ErrorRetryBackoff is not
+// set. It doubles on each further retry.
+const DefaultErrorRetryBackoff = 500 * time.Millisecond
+
+// permanentErrorCodes are the extension codes of errors in the request
+// itself: retrying cannot succeed, so they are never retried, even if listed
+// in RetryOnErrorCodes.
+var permanentErrorCodes = map[string]bool{
+	"GRAPHQL_PARSE_FAILED":          true,
+	"GRAPHQL_VALIDATION_FAILED":     true,
+	"BAD_USER_INPUT":                true,
+	"PERSISTED_QUERY_NOT_SUPPORTED": true,
+	"OPERATION_RESOLUTION_FAILURE":  true,
+	"BAD_REQUEST":                   true,
+	"UNAUTHENTICATED":               true,
+	"FORBIDDEN":                     true,
+}
+
+// Code returns the error's extension code (extensions.code), or an empty
+// string if it has none.
+func (e GraphQLError) Code() string {
+	code, _ := e.Extensions["code"].(string)
+	return code
+}
+
+// retryOnErrors reports whether a response failed with errs should be retried:
+// every error must carry a code listed in RetryOnErrorCodes, and none may be
+// permanent.
+func (gc *GraphQLClient) retryOnErrors(errs GraphQLErrors) bool {
+	if len(errs) == 0 || len(gc.RetryOnErrorCodes) == 0 {
+		return false
+	}
+	for _, gqlErr := range errs {
+		code := gqlErr.Code()
+		if permanentErrorCodes[code] || !gc.retriesCode(code) {
+			return false
+		}
+	}
+	return true
+}
+
+// retriesCode reports whether code is listed in RetryOnErrorCodes.
+func (gc *GraphQLClient) retriesCode(code string) bool {
+	for _, retried := range gc.RetryOnErrorCodes {
+		if retried == code {
+			return true
+		}
+	}
+	return false
+}
+
+// errorRetryDelay returns how long to wait before the retry following attempt
+// tryCount (starting at 1): ErrorRetryBackoff, doubled for each earlier retry.
+func (gc *GraphQLClient) errorRetryDelay(tryCount int) time.Duration {
+	backoff := gc.ErrorRetryBackoff
+	if backoff <= 0 {
+		backoff = DefaultErrorRetryBackoff
+	}
+	if tryCount > 1 {
+		backoff <<= tryCount - 1
+	}
+	return backoff
+}
+
+// sleepContext waits for d, or returns ctx's error as soon as it is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
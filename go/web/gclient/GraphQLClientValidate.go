@@ -0,0 +1,337 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// GraphQLClientValidate.go implements a dry run for GraphQL operations: a
+// lightweight client-side syntax check that catches obviously malformed
+// documents before they reach the network, and an optional server-side
+// validation request for backends that support one.
+//
+// Example usage:
+//
+//	mutation := `mutation Delete($id: ID!) { deleteUser(id: $id) { id } }`
+//	if err := client.Validate(mutation, vars); err != nil {
+//	    return err
+//	}
+//	response, _ := client.Mutate(mutation, "Delete", vars, "User", "deleteUser")
+
+package gclient
+
+import (
+	nethttp "net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ValidationError is returned by Validate for a malformed GraphQL document or
+// a missing required variable. Line and Column locate the problem (1-indexed),
+// and are zero when it has no position, e.g. for an empty query.
+type ValidationError struct {
+	Line    int    // Line of the offending token
+	Column  int    // Column of the offending token
+	Message string // Description of the problem
+}
+
+// Error returns the message prefixed with its position, if known.
+func (e *ValidationError) Error() string {
+	if e.Line == 0 {
+		return "GraphQL validation: " + e.Message
+	}
+	return "GraphQL validation: line " + strconv.Itoa(e.Line) + ", column " + strconv.Itoa(e.Column) + ": " + e.Message
+}
+
+// Validate checks a GraphQL query or mutation without executing it.
+//
+// The client-side check rejects empty documents, documents without an
+// operation, unterminated strings, unbalanced braces, parentheses and
+// brackets, empty selection sets, variables used but not declared by their
+// operation, and, for single-operation documents, non-null variables without
+// a default that are missing from variables. It does not know the schema, so a
+// document that passes may still be rejected by the server.
+//
+// When ValidateExtension is set, a document that passes is also sent to the
+// server with that request extension set to true, and the GraphQL errors the
+// server reports are returned. Only set it for backends that honor the
+// extension: others would execute the operation.
+func (gc *GraphQLClient) Validate(query string, variables map[string]interface{}) error {
+	err := validateDocument(query, variables)
+	if err != nil || gc.ValidateExtension == "" {
+		return err
+	}
+
+	gqlRequest := &GraphQLRequest{
+		Query:      query,
+		Variables:  variables,
+		Extensions: map[string]interface{}{gc.ValidateExtension: true},
+	}
+	request, err := gc.request(nethttp.MethodPost, gc.Endpoint, gqlRequest)
+	if err != nil {
+		return err
+	}
+	response, err := gc.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	_, err = gc.readResponse(response, "", "")
+	return err
+}
+
+// gqlToken is a lexical token of a GraphQL document. Strings keep no value,
+// as only their extent matters to the checks.
+type gqlToken struct {
+	kind   byte   // One of the punctuators, or tokenName, tokenString, tokenOther
+	text   string // Name text, empty for other kinds
+	line   int
+	column int
+}
+
+const (
+	tokenName   = 'n' // Name or keyword
+	tokenString = 's' // String or block string
+	tokenOther  = 'o' // Number, spread or any other value token
+)
+
+// tokenize splits a GraphQL document into tokens, skipping whitespace, commas
+// and comments. It fails on an unterminated string.
+func tokenize(query string) ([]gqlToken, error) {
+	tokens := []gqlToken{}
+	line, lineStart := 1, 0
+	for i := 0; i < len(query); {
+		c := query[i]
+		column := i - lineStart + 1
+		switch {
+		case c == '\n':
+			line, lineStart = line+1, i+1
+			i++
+		case c == ' ' || c == '\t' || c == '\r' || c == ',':
+			i++
+		case c == '#':
+			for i < len(query) && query[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(query[i:], `"""`):
+			end := i + 3
+			for ; end < len(query); end++ {
+				if query[end] == '\\' && strings.HasPrefix(query[end+1:], `"""`) {
+					end += 3
+					continue
+				}
+				if strings.HasPrefix(query[end:], `"""`) {
+					break
+				}
+				if query[end] == '\n' {
+					line, lineStart = line+1, end+1
+				}
+			}
+			if end >= len(query) {
+				return nil, &ValidationError{Line: line, Column: column, Message: "unterminated block string"}
+			}
+			tokens = append(tokens, gqlToken{kind: tokenString, line: line, column: column})
+			i = end + 3
+		case c == '"':
+			end := i + 1
+			for ; end < len(query) && query[end] != '"' && query[end] != '\n'; end++ {
+				if query[end] == '\\' {
+					end++
+				}
+			}
+			if end >= len(query) || query[end] != '"' {
+				return nil, &ValidationError{Line: line, Column: column, Message: "unterminated string"}
+			}
+			tokens = append(tokens, gqlToken{kind: tokenString, line: line, column: column})
+			i = end + 1
+		case strings.IndexByte("{}()[]:=!$@|&", c) >= 0:
+			tokens = append(tokens, gqlToken{kind: c, line: line, column: column})
+			i++
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+			end := i + 1
+			for end < len(query) && isNameByte(query[end]) {
+				end++
+			}
+			tokens = append(tokens, gqlToken{kind: tokenName, text: query[i:end], line: line, column: column})
+			i = end
+		default:
+			// Numbers, spreads and anything else the checks don't look into
+			_, size := utf8.DecodeRuneInString(query[i:])
+			end := i + size
+			for end < len(query) && (isNameByte(query[end]) || query[end] == '.' || query[end] == '-' || query[end] == '+') {
+				end++
+			}
+			tokens = append(tokens, gqlToken{kind: tokenOther, line: line, column: column})
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+// isNameByte reports whether b may continue a GraphQL name.
+func isNameByte(b byte) bool {
+	return b == '_' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9'
+}
+
+// variableDefinition is a variable declared by an operation.
+type variableDefinition struct {
+	token    gqlToken // The variable's name token
+	required bool     // Non-null type without a default value
+}
+
+// gqlOperation collects what Validate checks per top-level definition.
+type gqlOperation struct {
+	fragment  bool                          // Fragment definitions don't declare variables
+	variables map[string]variableDefinition // Declared variables by name
+	used      []gqlToken                    // Variables referenced in the body
+}
+
+// validateDocument runs the client-side checks described on Validate.
+func validateDocument(query string, variables map[string]interface{}) error {
+	if strings.TrimSpace(query) == "" {
+		return &ValidationError{Message: "empty query"}
+	}
+	tokens, err := tokenize(query)
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		return &ValidationError{Message: "query holds only comments"}
+	}
+
+	// stack holds the open brackets. A '{' opens a selection set unless it
+	// sits in arguments or a default value, where it is an input object.
+	type open struct {
+		token     gqlToken
+		selection bool
+		empty     bool
+	}
+	stack := []open{}
+	operations := []*gqlOperation{}
+	var current *gqlOperation
+	betweenDefinitions := true
+	inVariableDefinitions := false
+
+	for i, token := range tokens {
+		if len(stack) > 0 && strings.IndexByte("})]", token.kind) < 0 {
+			stack[len(stack)-1].empty = false
+		}
+		if len(stack) == 0 && betweenDefinitions {
+			if token.kind != '{' && (token.kind != tokenName || !isDefinitionKeyword(token.text)) {
+				return &ValidationError{Line: token.line, Column: token.column, Message: "unexpected token, expected query, mutation, subscription, fragment or '{'"}
+			}
+			// A '{' here starts a shorthand query
+			current = &gqlOperation{fragment: token.text == "fragment", variables: map[string]variableDefinition{}}
+			operations = append(operations, current)
+			betweenDefinitions = false
+		}
+
+		switch token.kind {
+		case '{', '(', '[':
+			selection := token.kind == '{' && (len(stack) == 0 || stack[len(stack)-1].selection)
+			if token.kind == '(' && len(stack) == 0 && !current.fragment && i > 0 && tokens[i-1].kind == tokenName && (i < 2 || tokens[i-2].kind != '@') {
+				inVariableDefinitions = true
+			}
+			stack = append(stack, open{token: token, selection: selection, empty: true})
+		case '}', ')', ']':
+			if len(stack) == 0 {
+				return &ValidationError{Line: token.line, Column: token.column, Message: "unexpected '" + string(token.kind) + "' without a matching opening bracket"}
+			}
+			top := stack[len(stack)-1]
+			if closing(top.token.kind) != token.kind {
+				return &ValidationError{Line: token.line, Column: token.column, Message: "unexpected '" + string(token.kind) + "', expected '" + string(closing(top.token.kind)) + "' to close line " + strconv.Itoa(top.token.line) + ", column " + strconv.Itoa(top.token.column)}
+			}
+			if top.selection && top.empty {
+				return &ValidationError{Line: top.token.line, Column: top.token.column, Message: "empty selection set"}
+			}
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				inVariableDefinitions = false
+				betweenDefinitions = token.kind == '}'
+			}
+		case '$':
+			if i+1 >= len(tokens) || tokens[i+1].kind != tokenName {
+				return &ValidationError{Line: token.line, Column: token.column, Message: "'$' must be followed by a variable name"}
+			}
+			name := tokens[i+1]
+			if inVariableDefinitions && len(stack) == 1 {
+				current.variables[name.text] = variableDefinition{token: name, required: isRequiredVariable(tokens[i+2:])}
+			} else {
+				current.used = append(current.used, name)
+			}
+		}
+	}
+	if len(stack) > 0 {
+		top := stack[len(stack)-1]
+		return &ValidationError{Line: top.token.line, Column: top.token.column, Message: "'" + string(top.token.kind) + "' is never closed"}
+	}
+
+	executable := 0
+	for _, op := range operations {
+		if op.fragment {
+			continue
+		}
+		executable++
+		for _, used := range op.used {
+			if _, ok := op.variables[used.text]; !ok {
+				return &ValidationError{Line: used.line, Column: used.column, Message: "variable $" + used.text + " is not declared by its operation"}
+			}
+		}
+	}
+	if executable == 0 {
+		return &ValidationError{Message: "no operation: expected a query, mutation or subscription"}
+	}
+	if executable > 1 {
+		// The variables belong to whichever operation is selected by name
+		return nil
+	}
+	for _, op := range operations {
+		for name, definition := range op.variables {
+			if definition.required && (variables == nil || variables[name] == nil) {
+				return &ValidationError{Line: definition.token.line, Column: definition.token.column, Message: "required variable $" + name + " is not provided"}
+			}
+		}
+	}
+	return nil
+}
+
+// isDefinitionKeyword reports whether name starts a top-level definition.
+func isDefinitionKeyword(name string) bool {
+	return name == "query" || name == "mutation" || name == "subscription" || name == "fragment"
+}
+
+// isRequiredVariable reads a variable definition following its name
+// (": Type! = default") and reports whether the type is non-null with no
+// default value.
+func isRequiredVariable(tokens []gqlToken) bool {
+	if len(tokens) == 0 || tokens[0].kind != ':' {
+		return false
+	}
+	i := 1
+	for i < len(tokens) && (tokens[i].kind == tokenName || tokens[i].kind == '[' || tokens[i].kind == ']' || tokens[i].kind == '!') {
+		i++
+	}
+	return i > 1 && tokens[i-1].kind == '!' && (i == len(tokens) || tokens[i].kind != '=')
+}
+
+// closing returns the bracket that closes the opening bracket b, or 0 if b
+// is not an opening bracket.
+func closing(b byte) byte {
+	switch b {
+	case '{':
+		return '}'
+	case '(':
+		return ')'
+	case '[':
+		return ']'
+	}
+	return 0
+}
@@ -0,0 +1,23 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gclient
+
+import "github.com/saichler/l8web/go/web/internal/unmarshal"
+
+// UnmarshalError is returned when a response body cannot be unmarshaled into
+// the requested Protocol Buffer type. It carries enough context to log
+// precisely which type and field failed without inspecting stdout.
+type UnmarshalError = unmarshal.Error
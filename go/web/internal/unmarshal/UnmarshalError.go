@@ -0,0 +1,114 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package unmarshal describes failures to unmarshal a JSON response into a
+// Protocol Buffer message, shared by the REST and GraphQL clients, which
+// expose its Error as their UnmarshalError.
+package unmarshal
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// snippetRadius is the number of bytes shown on each side of the failure
+// position in Error.Snippet.
+const snippetRadius = 40
+
+var (
+	// errPositionPattern matches the "(line L:C)" position in protojson errors.
+	errPositionPattern = regexp.MustCompile(`\(line (\d+):(\d+)\)`)
+	// errUnknownFieldPattern matches protojson's `unknown field "name"` errors.
+	errUnknownFieldPattern = regexp.MustCompile(`unknown field "([^"]+)"`)
+	// errInvalidFieldPattern matches protojson's "invalid value for <kind> field <name>:" errors.
+	errInvalidFieldPattern = regexp.MustCompile(`field ([A-Za-z0-9_.]+):`)
+)
+
+// Error is returned when a response body cannot be unmarshaled into
+// the requested Protocol Buffer type. It carries enough context to log
+// precisely which type and field failed without inspecting stdout.
+type Error struct {
+	TypeName string // Full name of the target Protocol Buffer message
+	Field    string // Field rejected by protojson, empty if it could not be determined
+	Snippet  string // Excerpt of the JSON around the failure position
+	Err      error  // Underlying protojson error
+}
+
+// NewError builds an Error from a protojson failure,
+// extracting the rejected field and a snippet of the JSON where possible.
+func NewError(jsonBytes []byte, responsePb proto.Message, err error) *Error {
+	ue := &Error{
+		TypeName: string(responsePb.ProtoReflect().Descriptor().FullName()),
+		Err:      err,
+	}
+	if m := errUnknownFieldPattern.FindStringSubmatch(err.Error()); m != nil {
+		ue.Field = m[1]
+	} else if m := errInvalidFieldPattern.FindStringSubmatch(err.Error()); m != nil {
+		ue.Field = m[1]
+	}
+
+	offset := 0
+	if m := errPositionPattern.FindStringSubmatch(err.Error()); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		column, _ := strconv.Atoi(m[2])
+		for i := 1; i < line && offset < len(jsonBytes); i++ {
+			next := bytes.IndexByte(jsonBytes[offset:], '\n')
+			if next < 0 {
+				break
+			}
+			offset += next + 1
+		}
+		offset += column - 1
+	}
+	start := offset - snippetRadius
+	if start < 0 {
+		start = 0
+	}
+	end := offset + snippetRadius
+	if end > len(jsonBytes) {
+		end = len(jsonBytes)
+	}
+	if start < end {
+		ue.Snippet = string(jsonBytes[start:end])
+	}
+	return ue
+}
+
+// Error returns a description including the type, field and JSON snippet.
+func (e *Error) Error() string {
+	buff := bytes.Buffer{}
+	buff.WriteString("failed to unmarshal response into ")
+	buff.WriteString(e.TypeName)
+	if e.Field != "" {
+		buff.WriteString(" (field '")
+		buff.WriteString(e.Field)
+		buff.WriteString("')")
+	}
+	buff.WriteString(": ")
+	buff.WriteString(e.Err.Error())
+	if e.Snippet != "" {
+		buff.WriteString(" near: ")
+		buff.WriteString(e.Snippet)
+	}
+	return buff.String()
+}
+
+// Unwrap returns the underlying protojson error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
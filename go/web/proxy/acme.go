@@ -0,0 +1,116 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proxy
+
+import (
+	"crypto/tls"
+	"log"
+	"net/http"
+	"strings"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// Routes with RouteConfig.ACME set get their certificates from an ACME CA
+// (Let's Encrypt by default) instead of CertFile and KeyFile. Certificates
+// are obtained on the first handshake for a domain, renewed before they
+// expire and stored in ProxyConfig.ACMECacheDir so restarts reuse them.
+//
+// Domain ownership is proven with the tls-alpn-01 challenge on the route's
+// listener, which the CA reaches on port 443, or with http-01 when
+// ProxyConfig.ACMEHTTPPort is set.
+
+// DefaultACMECacheDir is used when ProxyConfig.ACMECacheDir is not set.
+const DefaultACMECacheDir = "acme-cache"
+
+// acmeManager returns the proxy's autocert manager, creating it on first use.
+// Only the domains of ACME routes are allowed, so handshakes for other names
+// can't make the proxy request certificates.
+func (pc *ProxyConfig) acmeManager() *autocert.Manager {
+	pc.acmeOnce.Do(func() {
+		cacheDir := pc.ACMECacheDir
+		if cacheDir == "" {
+			cacheDir = DefaultACMECacheDir
+		}
+		pc.acme = &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			Cache:      autocert.DirCache(cacheDir),
+			HostPolicy: autocert.HostWhitelist(pc.acmeDomains()...),
+			Email:      pc.ACMEEmail,
+		}
+		if pc.ACMEDirectoryURL != "" {
+			pc.acme.Client = &acme.Client{DirectoryURL: pc.ACMEDirectoryURL}
+		}
+	})
+	return pc.acme
+}
+
+// acmeDomains returns the domains of all ACME routes across all listeners.
+func (pc *ProxyConfig) acmeDomains() []string {
+	var domains []string
+	for _, listener := range pc.Listeners {
+		for _, route := range listener.Routes {
+			if !route.ACME {
+				continue
+			}
+			for _, domain := range route.Domains {
+				domains = append(domains, strings.ToLower(domain))
+			}
+		}
+	}
+	return domains
+}
+
+// acmeCertificate returns the ACME-managed certificate for domain. The hello
+// is passed on so tls-alpn-01 challenge handshakes and the client's key type
+// preference are honored.
+func (pc *ProxyConfig) acmeCertificate(info *tls.ClientHelloInfo, domain string) (*tls.Certificate, error) {
+	if info.ServerName != domain {
+		hello := *info
+		hello.ServerName = domain
+		info = &hello
+	}
+	cert, err := pc.acmeManager().GetCertificate(info)
+	if err != nil {
+		log.Printf("Error obtaining ACME certificate for %s: %v", domain, err)
+		return nil, err
+	}
+	return cert, nil
+}
+
+// hasACMERoutes reports whether any route of listener uses ACME.
+func hasACMERoutes(listener ListenerConfig) bool {
+	for _, route := range listener.Routes {
+		if route.ACME {
+			return true
+		}
+	}
+	return false
+}
+
+// startACMEHTTP serves http-01 challenges on ACMEHTTPPort. Other plain HTTP
+// requests are redirected to HTTPS.
+func (pc *ProxyConfig) startACMEHTTP() error {
+	server := &http.Server{
+		Addr:              pc.ACMEHTTPPort,
+		Handler:           pc.acmeManager().HTTPHandler(nil),
+		ReadHeaderTimeout: DefaultReadHeaderTimeout,
+		IdleTimeout:       DefaultIdleTimeout,
+	}
+	log.Printf("Serving ACME http-01 challenges on port %s", pc.ACMEHTTPPort)
+	return server.ListenAndServe()
+}
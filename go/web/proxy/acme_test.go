@@ -0,0 +1,95 @@
+package proxy
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeACMECache stores a certificate for domain in dir the way autocert
+// caches an issued ECDSA certificate: the key followed by the chain.
+func writeACMECache(t *testing.T, dir, domain string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: domain},
+		DNSNames:     []string{domain},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(90 * 24 * time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := append(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}),
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+	if err = os.WriteFile(filepath.Join(dir, domain), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// acmeHello is a ClientHello from a client that accepts ECDSA certificates.
+func acmeHello(serverName string) *tls.ClientHelloInfo {
+	return &tls.ClientHelloInfo{
+		ServerName:   serverName,
+		CipherSuites: []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256},
+	}
+}
+
+func TestGetCertificate_ACME(t *testing.T) {
+	dir := t.TempDir()
+	writeACMECache(t, dir, "example.com")
+	staticCert, staticKey := writeCert(t, dir, "static.com")
+
+	listener := ListenerConfig{
+		ListenPort: ":443",
+		Routes: []RouteConfig{
+			{Domains: []string{"example.com"}, ACME: true},
+			{Domains: []string{"static.com"}, CertFile: staticCert, KeyFile: staticKey},
+		},
+	}
+	pc := &ProxyConfig{Listeners: []ListenerConfig{listener}, ACMECacheDir: dir}
+
+	for _, serverName := range []string{"example.com", ""} {
+		cert, err := pc.getCertificateForListener(acmeHello(serverName), listener)
+		if err != nil {
+			t.Fatalf("unexpected error for %q: %v", serverName, err)
+		}
+		if cert.Leaf == nil || cert.Leaf.Subject.CommonName != "example.com" {
+			t.Fatalf("expected the cached ACME certificate for %q", serverName)
+		}
+	}
+
+	if cn := certFor(t, pc, "static.com", listener); cn != "static.com" {
+		t.Fatalf("expected static.com certificate, got %s", cn)
+	}
+}
+
+func TestGetCertificate_ACMEHostPolicy(t *testing.T) {
+	pc := &ProxyConfig{
+		Listeners: []ListenerConfig{{
+			Routes: []RouteConfig{{Domains: []string{"example.com"}, ACME: true}},
+		}},
+		ACMECacheDir: t.TempDir(),
+	}
+	// Names outside the ACME routes are refused before the CA is contacted.
+	if _, err := pc.acmeManager().GetCertificate(acmeHello("other.com")); err == nil {
+		t.Fatal("expected other.com to be rejected by the host policy")
+	}
+}
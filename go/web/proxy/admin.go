@@ -0,0 +1,129 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proxy
+
+import (
+	"encoding/json"
+	"log"
+	"net"
+	"net/http"
+	"time"
+)
+
+// The admin endpoint reports the proxy's own state as JSON for monitoring and
+// debugging. It is served on ProxyConfig.AdminAddr, which should be bound to a
+// loopback or internal interface: it lists the whole routing table and is not
+// authenticated.
+//
+//	GET /status   routes of every listener and the traffic proxied so far
+//	GET /healthz  200 "ok" while the proxy process is up
+
+// AdminStatus is the document served by the admin endpoint's /status.
+type AdminStatus struct {
+	Started   time.Time       `json:"started"`
+	Uptime    string          `json:"uptime"`
+	Listeners []AdminListener `json:"listeners"`
+	Totals    AdminTotals     `json:"totals"`
+	Traffic   []RouteTraffic  `json:"traffic"`
+}
+
+// AdminListener describes a listener and its routes.
+type AdminListener struct {
+	ListenPort string       `json:"listenPort"`
+	Routes     []AdminRoute `json:"routes"`
+}
+
+// AdminRoute describes a route and the backend it proxies to.
+type AdminRoute struct {
+	Domains      []string `json:"domains"`
+	Backend      string   `json:"backend"`
+	ACME         bool     `json:"acme,omitempty"`
+	CertFile     string   `json:"certFile,omitempty"`
+	PreserveHost bool     `json:"preserveHost,omitempty"`
+}
+
+// AdminTotals sums the traffic of all listeners and domains.
+type AdminTotals struct {
+	Requests int64 `json:"requests"`
+	BytesIn  int64 `json:"bytesIn"`
+	BytesOut int64 `json:"bytesOut"`
+}
+
+// Status returns the document served by the admin endpoint's /status: the
+// configured routes and the traffic proxied since Start. Backend health is
+// not probed.
+func (pc *ProxyConfig) Status() *AdminStatus {
+	status := &AdminStatus{
+		Started:   pc.started,
+		Listeners: []AdminListener{},
+		Traffic:   pc.Traffic(),
+	}
+	if !pc.started.IsZero() {
+		status.Uptime = time.Since(pc.started).Round(time.Second).String()
+	}
+	host := backendHost()
+	for _, listener := range pc.Listeners {
+		adminListener := AdminListener{ListenPort: listener.ListenPort, Routes: []AdminRoute{}}
+		for _, route := range listener.Routes {
+			adminRoute := AdminRoute{
+				Domains:      route.Domains,
+				Backend:      net.JoinHostPort(host, route.TargetPort),
+				ACME:         route.ACME,
+				PreserveHost: route.PreserveHost,
+			}
+			if !route.ACME {
+				adminRoute.CertFile = route.CertFile
+			}
+			adminListener.Routes = append(adminListener.Routes, adminRoute)
+		}
+		status.Listeners = append(status.Listeners, adminListener)
+	}
+	for _, t := range status.Traffic {
+		status.Totals.Requests += t.Requests
+		status.Totals.BytesIn += t.BytesIn
+		status.Totals.BytesOut += t.BytesOut
+	}
+	return status
+}
+
+// adminHandler serves the admin endpoint's paths.
+func (pc *ProxyConfig) adminHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(pc.Status())
+	})
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte("ok"))
+	})
+	return mux
+}
+
+// startAdmin serves the admin endpoint on AdminAddr over plain HTTP.
+func (pc *ProxyConfig) startAdmin() error {
+	server := &http.Server{
+		Addr:              pc.AdminAddr,
+		Handler:           pc.adminHandler(),
+		ReadHeaderTimeout: DefaultReadHeaderTimeout,
+		IdleTimeout:       DefaultIdleTimeout,
+	}
+	log.Printf("Serving the admin endpoint on %s", pc.AdminAddr)
+	return server.ListenAndServe()
+}
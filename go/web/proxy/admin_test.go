@@ -0,0 +1,64 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proxy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAdminHandler(t *testing.T) {
+	t.Setenv("NODE_IP", "10.0.0.5")
+	pc := &ProxyConfig{Listeners: []ListenerConfig{{
+		ListenPort: ":443",
+		Routes: []RouteConfig{
+			{Domains: []string{"example.com"}, TargetPort: "1443", CertFile: "example.com/cert.pem"},
+			{Domains: []string{"auto.example.com"}, TargetPort: "2443", ACME: true, CertFile: "ignored.pem"},
+		},
+	}}}
+	pc.trafficFor(":443", "example.com").requests.Add(3)
+	pc.trafficFor(":443", "example.com").bytesOut.Add(100)
+	pc.trafficFor(":443", "auto.example.com").bytesOut.Add(20)
+	handler := pc.adminHandler()
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/status", nil))
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("unexpected response %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+	status := &AdminStatus{}
+	if err := json.Unmarshal(w.Body.Bytes(), status); err != nil {
+		t.Fatal(err)
+	}
+	routes := status.Listeners[0].Routes
+	if len(routes) != 2 || routes[0].Backend != "10.0.0.5:1443" || routes[0].CertFile != "example.com/cert.pem" {
+		t.Fatalf("unexpected routes %+v", routes)
+	}
+	if !routes[1].ACME || routes[1].CertFile != "" {
+		t.Fatalf("expected an ACME route without a certificate file, got %+v", routes[1])
+	}
+	if status.Totals != (AdminTotals{Requests: 3, BytesOut: 120}) || len(status.Traffic) != 2 {
+		t.Fatalf("unexpected traffic %+v %+v", status.Totals, status.Traffic)
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "ok" {
+		t.Fatalf("unexpected health response %d %q", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,124 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proxy
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// Route and default certificates are loaded once and kept in an immutable
+// certCache behind an atomic pointer. ReloadCertificates builds a new cache
+// from the files and swaps it in, so a reload never blocks or fails a
+// handshake: connections keep the certificate they negotiated, and handshakes
+// started after the swap get the new one.
+
+// certKey identifies a certificate by its certificate and key files.
+type certKey struct {
+	certFile string
+	keyFile  string
+}
+
+// certCache maps certificate files to the loaded certificate. It is never
+// modified once published; changes publish a copy.
+type certCache map[certKey]*tls.Certificate
+
+// certificate returns the certificate loaded from certFile and keyFile,
+// loading and caching it on first use. The cached certificate is shared, so
+// callers that modify it, e.g. to staple an OCSP response, must copy it.
+func (pc *ProxyConfig) certificate(certFile, keyFile string) (*tls.Certificate, error) {
+	key := certKey{certFile: certFile, keyFile: keyFile}
+	cache := pc.certs.Load()
+	if cache != nil {
+		if cert, ok := (*cache)[key]; ok {
+			return cert, nil
+		}
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	next := certCache{key: &cert}
+	if cache != nil {
+		for k, v := range *cache {
+			next[k] = v
+		}
+	}
+	// If a reload or another handshake published a cache meanwhile, the
+	// certificate is served uncached and loaded again next time.
+	pc.certs.CompareAndSwap(cache, &next)
+	return &cert, nil
+}
+
+// ReloadCertificates loads the certificates of every listener's routes and
+// default certificate again, e.g. after they were renewed on disk, and swaps
+// them in atomically. Established connections are not affected; new
+// handshakes use the new certificates. If any file fails to load, the current
+// certificates are kept and the error is returned. ACME certificates are
+// managed separately and not reloaded.
+func (pc *ProxyConfig) ReloadCertificates() error {
+	next := certCache{}
+	load := func(certFile, keyFile string) error {
+		key := certKey{certFile: certFile, keyFile: keyFile}
+		if certFile == "" || next[key] != nil {
+			return nil
+		}
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return fmt.Errorf("failed to reload certificate %s: %w", certFile, err)
+		}
+		next[key] = &cert
+		return nil
+	}
+	for _, listener := range pc.Listeners {
+		for _, route := range listener.Routes {
+			if route.ACME {
+				continue
+			}
+			if err := load(route.CertFile, route.KeyFile); err != nil {
+				return err
+			}
+		}
+		if err := load(listener.DefaultCertFile, listener.DefaultKeyFile); err != nil {
+			return err
+		}
+	}
+
+	pc.certs.Store(&next)
+	// A staple belongs to the certificate it was fetched for
+	for key := range next {
+		staples.forget(key.certFile)
+	}
+	log.Printf("Reloaded %d certificates", len(next))
+	return nil
+}
+
+// reloadOnHangup calls ReloadCertificates whenever the process receives
+// SIGHUP, the conventional signal to reload configuration.
+func (pc *ProxyConfig) reloadOnHangup() {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	for range hangup {
+		if err := pc.ReloadCertificates(); err != nil {
+			log.Printf("Certificate reload failed, keeping the current certificates: %v", err)
+		}
+	}
+}
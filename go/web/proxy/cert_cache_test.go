@@ -0,0 +1,66 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proxy
+
+import (
+	"os"
+	"testing"
+)
+
+func TestReloadCertificates(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeCert(t, dir, "example.com")
+	pc := &ProxyConfig{Listeners: []ListenerConfig{{
+		ListenPort: ":443",
+		Routes:     []RouteConfig{{Domains: []string{"example.com"}, CertFile: certFile, KeyFile: keyFile}},
+	}}}
+	listener := pc.Listeners[0]
+	if name := certFor(t, pc, "example.com", listener); name != "example.com" {
+		t.Fatalf("expected the route certificate, got %s", name)
+	}
+
+	// A renewed certificate on disk is picked up by a reload, not before.
+	renewedCert, renewedKey := writeCert(t, t.TempDir(), "renewed.example.com")
+	replace := func(from, to string) {
+		data, err := os.ReadFile(from)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = os.WriteFile(to, data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	replace(renewedCert, certFile)
+	replace(renewedKey, keyFile)
+	if name := certFor(t, pc, "example.com", listener); name != "example.com" {
+		t.Fatalf("expected the cached certificate until a reload, got %s", name)
+	}
+	if err := pc.ReloadCertificates(); err != nil {
+		t.Fatal(err)
+	}
+	if name := certFor(t, pc, "example.com", listener); name != "renewed.example.com" {
+		t.Fatalf("expected the reloaded certificate, got %s", name)
+	}
+
+	// A failed reload keeps the current certificates.
+	os.WriteFile(keyFile, []byte("broken"), 0600)
+	if err := pc.ReloadCertificates(); err == nil {
+		t.Fatal("expected an error reloading a broken key")
+	}
+	if name := certFor(t, pc, "example.com", listener); name != "renewed.example.com" {
+		t.Fatalf("expected the previous certificate after a failed reload, got %s", name)
+	}
+}
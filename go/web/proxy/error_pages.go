@@ -0,0 +1,67 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proxy
+
+import (
+	"bytes"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// ErrorPages configures custom HTML pages served by the proxy instead of the
+// default plain-text error responses. Each field is a path to an HTML file that
+// may be a static page or an html/template using the fields of errorPageData
+// (e.g., {{.Status}}, {{.StatusText}}, {{.Host}}). An empty path keeps the
+// plain-text response.
+type ErrorPages struct {
+	UnknownHost string // Served when the Host header matches no route (502)
+	BackendDown string // Served when the backend cannot be reached (502)
+}
+
+// errorPageData is the data passed to error page templates.
+type errorPageData struct {
+	Status     int    // HTTP status code
+	StatusText string // Standard text for the status code
+	Host       string // Requested host, without port
+}
+
+// writeError writes an error response using the HTML page at the given path.
+// The page is loaded on every call so it can be updated without a restart.
+// If no page is configured, or it fails to load or render, the fallback text
+// is written as plain text with the same status code.
+func (pc *ProxyConfig) writeError(w http.ResponseWriter, r *http.Request, page string, status int, fallback string) {
+	if page != "" {
+		tmpl, err := template.ParseFiles(page)
+		if err == nil {
+			buff := bytes.Buffer{}
+			err = tmpl.Execute(&buff, &errorPageData{
+				Status:     status,
+				StatusText: http.StatusText(status),
+				Host:       strings.Split(strings.ToLower(r.Host), ":")[0],
+			})
+			if err == nil {
+				w.Header().Set("Content-Type", "text/html; charset=utf-8")
+				w.WriteHeader(status)
+				w.Write(buff.Bytes())
+				return
+			}
+		}
+		log.Printf("Error rendering error page %s: %v", page, err)
+	}
+	http.Error(w, fallback, status)
+}
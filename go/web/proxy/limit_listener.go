@@ -0,0 +1,80 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proxy
+
+import (
+	"log"
+	"net"
+	"sync"
+)
+
+// limitListener caps the number of concurrently open connections. Unlike
+// netutil.LimitListener, which blocks Accept until a slot frees up, connections
+// arriving while the listener is full are closed immediately so the kernel
+// backlog doesn't fill up with clients waiting on a saturated proxy.
+//
+// A flood of refused connections is logged once when the limit is reached
+// and once, with the number of refusals, when a connection fits again, so
+// it can't turn into unbounded log output.
+type limitListener struct {
+	net.Listener
+	sem     chan struct{}
+	refused int // Connections refused since the limit was reached; only used by Accept
+}
+
+// newLimitListener wraps l so that at most max connections are open at once.
+func newLimitListener(l net.Listener, max int) *limitListener {
+	return &limitListener{Listener: l, sem: make(chan struct{}, max)}
+}
+
+// Accept returns the next connection that fits within the limit, refusing
+// (closing) any connection accepted while all slots are taken.
+func (l *limitListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		select {
+		case l.sem <- struct{}{}:
+			if l.refused > 0 {
+				log.Printf("Connection limit %d on %s cleared after refusing %d connections", cap(l.sem), l.Addr(), l.refused)
+				l.refused = 0
+			}
+			return &limitConn{Conn: conn, release: func() { <-l.sem }}, nil
+		default:
+			if l.refused == 0 {
+				log.Printf("Connection limit %d reached on %s, refusing connections", cap(l.sem), l.Addr())
+			}
+			l.refused++
+			conn.Close()
+		}
+	}
+}
+
+// limitConn releases its listener slot when closed.
+type limitConn struct {
+	net.Conn
+	release func()
+	once    sync.Once
+}
+
+// Close closes the connection and frees its slot. Safe to call more than once.
+func (c *limitConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(c.release)
+	return err
+}
@@ -0,0 +1,72 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proxy
+
+import (
+	"bytes"
+	"log"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestLimitListener_LogsOncePerEpisode(t *testing.T) {
+	var logs bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&logs)
+
+	inner, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := newLimitListener(inner, 1)
+	defer l.Close()
+	accepted := make(chan net.Conn)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- conn
+		}
+	}()
+
+	held, _ := net.Dial("tcp", inner.Addr().String())
+	defer held.Close()
+	first := <-accepted
+	for i := 0; i < 3; i++ {
+		refused, err := net.Dial("tcp", inner.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The refused connection is closed by the listener
+		if n, _ := refused.Read(make([]byte, 1)); n != 0 {
+			t.Fatal("expected the connection to be refused")
+		}
+		refused.Close()
+	}
+	first.Close()
+
+	next, _ := net.Dial("tcp", inner.Addr().String())
+	defer next.Close()
+	(<-accepted).Close()
+
+	lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], "refusing connections") || !strings.Contains(lines[1], "after refusing 3 connections") {
+		t.Fatalf("expected one line per limit episode, got %q", logs.String())
+	}
+}
@@ -0,0 +1,179 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proxy
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+// OCSP stapling, enabled per route with RouteConfig.OCSPStapling, attaches the
+// certificate's OCSP response to the TLS handshake so browsers don't have to
+// ask the CA's responder themselves.
+//
+// Responses are fetched in the background and cached per certificate file.
+// A handshake never waits for the responder: until a response is cached, and
+// whenever it has expired, the certificate is served without a staple.
+
+const (
+	// ocspFetchTimeout bounds a request to an OCSP responder.
+	ocspFetchTimeout = 10 * time.Second
+	// ocspRetryInterval is how long to wait before retrying a failed fetch.
+	ocspRetryInterval = 5 * time.Minute
+	// ocspDefaultValidity is used for responses without a NextUpdate.
+	ocspDefaultValidity = time.Hour
+	// ocspMaxResponseSize caps the size of an OCSP response.
+	ocspMaxResponseSize = 1 << 20
+)
+
+// ocspClient sends the OCSP requests.
+var ocspClient = &http.Client{Timeout: ocspFetchTimeout}
+
+// staples caches the OCSP responses of the routes with OCSPStapling set.
+var staples = &stapleCache{entries: map[string]*staple{}}
+
+// stapleCache holds one staple per certificate file.
+type stapleCache struct {
+	mtx     sync.Mutex
+	entries map[string]*staple
+}
+
+// staple is the cached OCSP response of a certificate.
+type staple struct {
+	response   []byte    // Raw DER response, nil until a fetch succeeds
+	expires    time.Time // Response NextUpdate, the staple is dropped after it
+	refreshAt  time.Time // When to fetch a new response
+	refreshing bool      // A fetch is in flight
+}
+
+// staple sets cert.OCSPStaple from the cache for route's certificate, and
+// starts a background fetch if there is no valid response or it is due for
+// refresh.
+func (s *stapleCache) staple(route RouteConfig, cert *tls.Certificate) {
+	now := time.Now()
+	s.mtx.Lock()
+	entry, ok := s.entries[route.CertFile]
+	if !ok {
+		entry = &staple{}
+		s.entries[route.CertFile] = entry
+	}
+	if entry.response != nil && now.Before(entry.expires) {
+		cert.OCSPStaple = entry.response
+	}
+	fetch := !entry.refreshing && !now.Before(entry.refreshAt)
+	if fetch {
+		entry.refreshing = true
+	}
+	s.mtx.Unlock()
+
+	if fetch {
+		go s.refresh(route, cert)
+	}
+}
+
+// refresh fetches a new OCSP response for route's certificate and caches it.
+// The response is refreshed half way through its validity; failures are
+// retried after ocspRetryInterval while any still valid response is kept.
+func (s *stapleCache) refresh(route RouteConfig, cert *tls.Certificate) {
+	response, parsed, err := fetchOCSP(route, cert)
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	entry, ok := s.entries[route.CertFile]
+	if !ok {
+		// Forgotten meanwhile: the response is for a replaced certificate
+		return
+	}
+	entry.refreshing = false
+	if err != nil {
+		log.Printf("OCSP stapling for %s failed: %v", route.CertFile, err)
+		entry.refreshAt = time.Now().Add(ocspRetryInterval)
+		return
+	}
+	entry.response = response
+	entry.expires = parsed.NextUpdate
+	if entry.expires.IsZero() {
+		entry.expires = time.Now().Add(ocspDefaultValidity)
+	}
+	entry.refreshAt = parsed.ThisUpdate.Add(entry.expires.Sub(parsed.ThisUpdate) / 2)
+}
+
+// forget drops the staple of a certificate file, e.g. after the certificate
+// was reloaded. The next handshake fetches a response for the new one.
+func (s *stapleCache) forget(certFile string) {
+	s.mtx.Lock()
+	delete(s.entries, certFile)
+	s.mtx.Unlock()
+}
+
+// fetchOCSP requests the OCSP response for the leaf certificate of cert from
+// route.OCSPResponder, or the responder named in the certificate. The issuer
+// must follow the leaf in the certificate file. Only a Good status is accepted.
+func fetchOCSP(route RouteConfig, cert *tls.Certificate) ([]byte, *ocsp.Response, error) {
+	if len(cert.Certificate) < 2 {
+		return nil, nil, errors.New("the certificate file has no issuer certificate after the leaf")
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	issuer, err := x509.ParseCertificate(cert.Certificate[1])
+	if err != nil {
+		return nil, nil, err
+	}
+	responder := route.OCSPResponder
+	if responder == "" {
+		if len(leaf.OCSPServer) == 0 {
+			return nil, nil, errors.New("no OCSP responder URL in the certificate or the route")
+		}
+		responder = leaf.OCSPServer[0]
+	}
+
+	request, err := ocsp.CreateRequest(leaf, issuer, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, err := ocspClient.Post(responder, "application/ocsp-request", bytes.NewReader(request))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("OCSP responder %s returned %s", responder, resp.Status)
+	}
+	response, err := io.ReadAll(io.LimitReader(resp.Body, ocspMaxResponseSize))
+	if err != nil {
+		return nil, nil, err
+	}
+	parsed, err := ocsp.ParseResponseForCert(response, leaf, issuer)
+	if err != nil {
+		return nil, nil, err
+	}
+	if parsed.Status != ocsp.Good {
+		return nil, nil, fmt.Errorf("OCSP status of %s is not good (%d)", leaf.Subject.CommonName, parsed.Status)
+	}
+	return response, parsed, nil
+}
@@ -0,0 +1,167 @@
+package proxy
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+// ocspFixture is a CA, a leaf certificate file signed by it and an OCSP
+// responder answering for the leaf with status.
+type ocspFixture struct {
+	route     RouteConfig
+	responder *httptest.Server
+	status    int
+}
+
+func newOCSPFixture(t *testing.T) *ocspFixture {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	caDer, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, _ := x509.ParseCertificate(caDer)
+
+	fixture := &ocspFixture{status: ocsp.Good}
+	fixture.responder = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		request, err := ocsp.ParseRequest(body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		response, err := ocsp.CreateResponse(ca, ca, ocsp.Response{
+			Status:       fixture.status,
+			SerialNumber: request.SerialNumber,
+			ThisUpdate:   time.Now().Add(-time.Minute),
+			NextUpdate:   time.Now().Add(time.Hour),
+			RevokedAt:    time.Now().Add(-time.Minute),
+		}, caKey)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write(response)
+	}))
+	t.Cleanup(fixture.responder.Close)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leafTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "example.com"},
+		DNSNames:     []string{"example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		OCSPServer:   []string{fixture.responder.URL},
+	}
+	leafDer, err := x509.CreateCertificate(rand.Reader, leafTemplate, ca, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, _ := x509.MarshalECPrivateKey(key)
+
+	dir := t.TempDir()
+	fixture.route = RouteConfig{
+		Domains:      []string{"example.com"},
+		CertFile:     filepath.Join(dir, "domain.cert.pem"),
+		KeyFile:      filepath.Join(dir, "private.key.pem"),
+		OCSPStapling: true,
+	}
+	chain := append(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leafDer}),
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDer})...)
+	if err = os.WriteFile(fixture.route.CertFile, chain, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(fixture.route.KeyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return fixture
+}
+
+// handshakeStaple returns the OCSP staple served for example.com, waiting up to
+// a second for the background fetch the first handshake starts.
+func (f *ocspFixture) handshakeStaple(t *testing.T) []byte {
+	pc := &ProxyConfig{}
+	listener := ListenerConfig{Routes: []RouteConfig{f.route}}
+	for i := 0; i < 100; i++ {
+		cert, err := pc.getCertificateForListener(&tls.ClientHelloInfo{ServerName: "example.com"}, listener)
+		if err != nil {
+			t.Fatal(err)
+		}
+		staples.mtx.Lock()
+		done := !staples.entries[f.route.CertFile].refreshing
+		staples.mtx.Unlock()
+		if cert.OCSPStaple != nil || (done && i > 0) {
+			return cert.OCSPStaple
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return nil
+}
+
+func TestOCSPStapling_Good(t *testing.T) {
+	defer func() { staples = &stapleCache{entries: map[string]*staple{}} }()
+	fixture := newOCSPFixture(t)
+
+	response := fixture.handshakeStaple(t)
+	if response == nil {
+		t.Fatal("expected an OCSP staple")
+	}
+	parsed, err := ocsp.ParseResponse(response, nil)
+	if err != nil || parsed.Status != ocsp.Good {
+		t.Fatalf("expected a good OCSP response, got %v %v", parsed, err)
+	}
+
+	// The cached response is served until it is due for refresh.
+	staples.mtx.Lock()
+	entry := staples.entries[fixture.route.CertFile]
+	refreshAt := entry.refreshAt
+	staples.mtx.Unlock()
+	if !refreshAt.After(time.Now()) || !refreshAt.Before(entry.expires) {
+		t.Fatalf("expected a refresh before expiry, got %v (expires %v)", refreshAt, entry.expires)
+	}
+}
+
+func TestOCSPStapling_Revoked(t *testing.T) {
+	defer func() { staples = &stapleCache{entries: map[string]*staple{}} }()
+	fixture := newOCSPFixture(t)
+	fixture.status = ocsp.Revoked
+
+	if response := fixture.handshakeStaple(t); response != nil {
+		t.Fatal("expected no staple for a revoked certificate")
+	}
+	staples.mtx.Lock()
+	defer staples.mtx.Unlock()
+	if entry := staples.entries[fixture.route.CertFile]; entry.response != nil || !entry.refreshAt.After(time.Now()) {
+		t.Fatal("expected the failed fetch to be retried later")
+	}
+}
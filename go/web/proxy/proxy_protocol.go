@@ -0,0 +1,199 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// proxyV2Signature is the fixed 12-byte prefix of a PROXY protocol v2 header.
+var proxyV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+// proxyV1MaxLength is the maximum length of a PROXY protocol v1 header line.
+const proxyV1MaxLength = 107
+
+// proxyHeaderTimeout bounds how long a connection may take to send its PROXY header.
+const proxyHeaderTimeout = 5 * time.Second
+
+// proxyProtocolListener wraps a net.Listener and expects every accepted connection
+// to start with a PROXY protocol v1 or v2 header, as sent by L4 load balancers
+// (HAProxy, AWS NLB, etc.). Connections without a valid header are rejected.
+type proxyProtocolListener struct {
+	net.Listener
+}
+
+// Accept wraps the accepted connection so the PROXY header is consumed before
+// any TLS bytes are read. Parsing is deferred to the connection's own goroutine
+// so a slow client cannot block the accept loop.
+func (l *proxyProtocolListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &proxyProtocolConn{Conn: conn, reader: bufio.NewReader(conn)}, nil
+}
+
+// proxyProtocolConn is a net.Conn whose RemoteAddr reports the client address
+// carried in the PROXY header rather than the load balancer's address.
+type proxyProtocolConn struct {
+	net.Conn
+	reader *bufio.Reader
+	once   sync.Once
+	remote net.Addr
+	err    error
+}
+
+// init reads the PROXY header exactly once. net/http calls RemoteAddr before
+// starting the TLS handshake, so the header is always parsed before any Read.
+func (c *proxyProtocolConn) init() {
+	c.once.Do(func() {
+		c.Conn.SetReadDeadline(time.Now().Add(proxyHeaderTimeout))
+		c.remote, c.err = readProxyHeader(c.reader)
+		c.Conn.SetReadDeadline(time.Time{})
+		if c.err != nil {
+			log.Printf("PROXY protocol: rejecting connection from %s: %v", c.Conn.RemoteAddr(), c.err)
+		}
+	})
+}
+
+// Read returns data following the PROXY header, or the header parse error.
+func (c *proxyProtocolConn) Read(b []byte) (int, error) {
+	c.init()
+	if c.err != nil {
+		return 0, c.err
+	}
+	return c.reader.Read(b)
+}
+
+// RemoteAddr returns the client address from the PROXY header. It falls back to
+// the socket address for LOCAL/UNKNOWN headers (e.g., load balancer health checks).
+func (c *proxyProtocolConn) RemoteAddr() net.Addr {
+	c.init()
+	if c.remote != nil {
+		return c.remote
+	}
+	return c.Conn.RemoteAddr()
+}
+
+// readProxyHeader detects and parses a PROXY protocol v1 or v2 header.
+// Returns a nil address when the header carries no client address.
+func readProxyHeader(r *bufio.Reader) (net.Addr, error) {
+	sig, err := r.Peek(len(proxyV2Signature))
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(sig, proxyV2Signature) {
+		return readProxyV2(r)
+	}
+	if bytes.HasPrefix(sig, []byte("PROXY ")) {
+		return readProxyV1(r)
+	}
+	return nil, errors.New("missing PROXY protocol header")
+}
+
+// readProxyV1 parses a text header such as "PROXY TCP4 1.2.3.4 5.6.7.8 5555 443\r\n".
+func readProxyV1(r *bufio.Reader) (net.Addr, error) {
+	line, err := r.ReadSlice('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) > proxyV1MaxLength {
+		return nil, errors.New("PROXY v1 header too long")
+	}
+	fields := strings.Fields(strings.TrimRight(string(line), "\r\n"))
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return nil, nil
+	}
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, fmt.Errorf("malformed PROXY v1 header: %q", line)
+	}
+	ip := net.ParseIP(fields[2])
+	if ip == nil {
+		return nil, fmt.Errorf("invalid PROXY v1 source address: %s", fields[2])
+	}
+	port, err := strconv.Atoi(fields[4])
+	if err != nil {
+		return nil, fmt.Errorf("invalid PROXY v1 source port: %s", fields[4])
+	}
+	return &net.TCPAddr{IP: ip, Port: port}, nil
+}
+
+// readProxyV2 parses a binary header. Only the TCP over IPv4/IPv6 address
+// families carry a client address; other families keep the socket address.
+func readProxyV2(r *bufio.Reader) (net.Addr, error) {
+	header := make([]byte, 16)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	if header[12]>>4 != 2 {
+		return nil, fmt.Errorf("unsupported PROXY v2 version: %d", header[12]>>4)
+	}
+	payload := make([]byte, binary.BigEndian.Uint16(header[14:16]))
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, err
+	}
+	// LOCAL command: connection initiated by the load balancer itself
+	if header[12]&0x0F == 0 {
+		return nil, nil
+	}
+	switch header[13] >> 4 {
+	case 1: // AF_INET: src(4) dst(4) srcport(2) dstport(2)
+		if len(payload) < 12 {
+			return nil, errors.New("short PROXY v2 IPv4 address block")
+		}
+		return &net.TCPAddr{IP: net.IP(payload[0:4]), Port: int(binary.BigEndian.Uint16(payload[8:10]))}, nil
+	case 2: // AF_INET6: src(16) dst(16) srcport(2) dstport(2)
+		if len(payload) < 36 {
+			return nil, errors.New("short PROXY v2 IPv6 address block")
+		}
+		return &net.TCPAddr{IP: net.IP(payload[0:16]), Port: int(binary.BigEndian.Uint16(payload[32:34]))}, nil
+	}
+	return nil, nil
+}
+
+// setRealIP sets the X-Real-IP header on an outgoing backend request from the
+// client address. httputil.ReverseProxy appends X-Forwarded-For on its own.
+func setRealIP(req *http.Request) {
+	if ip, _, err := net.SplitHostPort(req.RemoteAddr); err == nil {
+		req.Header.Set("X-Real-IP", ip)
+	}
+}
+
+// setForwardedFor sets both X-Real-IP and X-Forwarded-For for requests that are
+// written to the backend directly rather than through httputil.ReverseProxy.
+func setForwardedFor(req *http.Request) {
+	ip, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return
+	}
+	req.Header.Set("X-Real-IP", ip)
+	if prior := req.Header.Get("X-Forwarded-For"); prior != "" {
+		ip = prior + ", " + ip
+	}
+	req.Header.Set("X-Forwarded-For", ip)
+}
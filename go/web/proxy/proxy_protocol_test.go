@@ -0,0 +1,86 @@
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestReadProxyHeader_V1(t *testing.T) {
+	r := bufio.NewReader(strings.NewReader("PROXY TCP4 203.0.113.7 10.0.0.1 51234 443\r\nHELLO"))
+	addr, err := readProxyHeader(r)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if addr.String() != "203.0.113.7:51234" {
+		t.Fatalf("expected 203.0.113.7:51234, got %s", addr)
+	}
+	rest, _ := io.ReadAll(r)
+	if string(rest) != "HELLO" {
+		t.Fatalf("expected remaining payload 'HELLO', got %q", rest)
+	}
+}
+
+func TestReadProxyHeader_V1Unknown(t *testing.T) {
+	r := bufio.NewReader(strings.NewReader("PROXY UNKNOWN\r\n"))
+	addr, err := readProxyHeader(r)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if addr != nil {
+		t.Fatalf("expected nil address, got %s", addr)
+	}
+}
+
+func TestReadProxyHeader_V2IPv4(t *testing.T) {
+	buff := bytes.Buffer{}
+	buff.Write(proxyV2Signature)
+	buff.WriteByte(0x21) // version 2, PROXY command
+	buff.WriteByte(0x11) // AF_INET, STREAM
+	binary.Write(&buff, binary.BigEndian, uint16(12))
+	buff.Write(net.ParseIP("198.51.100.9").To4())
+	buff.Write(net.ParseIP("10.0.0.1").To4())
+	binary.Write(&buff, binary.BigEndian, uint16(40000))
+	binary.Write(&buff, binary.BigEndian, uint16(443))
+	buff.WriteString("HELLO")
+
+	r := bufio.NewReader(&buff)
+	addr, err := readProxyHeader(r)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if addr.String() != "198.51.100.9:40000" {
+		t.Fatalf("expected 198.51.100.9:40000, got %s", addr)
+	}
+	rest, _ := io.ReadAll(r)
+	if string(rest) != "HELLO" {
+		t.Fatalf("expected remaining payload 'HELLO', got %q", rest)
+	}
+}
+
+func TestReadProxyHeader_V2Local(t *testing.T) {
+	buff := bytes.Buffer{}
+	buff.Write(proxyV2Signature)
+	buff.WriteByte(0x20) // version 2, LOCAL command
+	buff.WriteByte(0x00)
+	binary.Write(&buff, binary.BigEndian, uint16(0))
+
+	addr, err := readProxyHeader(bufio.NewReader(&buff))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if addr != nil {
+		t.Fatalf("expected nil address, got %s", addr)
+	}
+}
+
+func TestReadProxyHeader_Missing(t *testing.T) {
+	r := bufio.NewReader(strings.NewReader("\x16\x03\x01\x02\x00\x01\x00\x01\xfc\x03\x03\x00"))
+	if _, err := readProxyHeader(r); err == nil {
+		t.Fatal("expected error for connection without PROXY header")
+	}
+}
@@ -23,6 +23,15 @@
 //   - Per-route SSL certificate configuration
 //   - Environment-based backend host configuration (NODE_IP)
 //   - Fallback domain matching for unmatched routes
+//   - Optional custom HTML error pages for unknown hosts and unreachable backends
+//   - Optional PROXY protocol v1/v2 support for real client IPs behind L4 load balancers
+//   - Per-listener header/idle timeouts, header size and count limits, and a
+//     concurrent connection limit
+//   - Optional per-route OCSP stapling
+//   - Optional automatic ACME (Let's Encrypt) certificates per route
+//   - Cached certificates, reloaded without dropping connections by ReloadCertificates
+//   - Per-domain request and bandwidth accounting, see Traffic
+//   - Optional admin endpoint reporting routes and traffic as JSON, see Status
 //
 // Default route configuration:
 //   - Port 443: layer8vibe.dev->1443, probler.dev->2443, layer-8.dev->4443
@@ -35,26 +44,75 @@ import (
 	"crypto/tls"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
 	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
 )
 
 // ProxyConfig holds the complete configuration for the reverse proxy,
 // including all listeners and their routing rules.
 type ProxyConfig struct {
-	Listeners []ListenerConfig // List of port listeners to start
+	Listeners  []ListenerConfig // List of port listeners to start
+	ErrorPages ErrorPages       // Optional custom HTML error pages
+
+	ACMECacheDir     string // Directory for ACME certificates and the account key (default: DefaultACMECacheDir)
+	ACMEEmail        string // Contact address registered with the ACME CA (optional)
+	ACMEDirectoryURL string // ACME directory endpoint (default: Let's Encrypt production)
+	ACMEHTTPPort     string // Port serving ACME http-01 challenges (e.g., ":80"); empty uses tls-alpn-01 only
+
+	// TrafficLogInterval logs the requests and bytes proxied per listener and
+	// domain at this interval; zero (the default) disables it. See Traffic.
+	TrafficLogInterval time.Duration
+	// AdminAddr serves the admin status endpoint on this address (e.g.,
+	// "127.0.0.1:9900"); empty (the default) disables it. It is not
+	// authenticated, so keep it off public interfaces. See Status.
+	AdminAddr string
+
+	started  time.Time // When Start was called, reported by Status
+	acmeOnce sync.Once
+	acme     *autocert.Manager
+	certs    atomic.Pointer[certCache] // Loaded certificates, see ReloadCertificates
+	traffic  sync.Map                  // trafficKey -> *trafficCounter, see Traffic
 }
 
 // ListenerConfig defines a single port listener with its routing rules.
 // Each listener can have multiple routes for different domains.
 type ListenerConfig struct {
-	ListenPort string        // Port to listen on (e.g., ":443", ":14443")
-	Routes     []RouteConfig // Routing rules for this listener
+	ListenPort        string        // Port to listen on (e.g., ":443", ":14443")
+	Routes            []RouteConfig // Routing rules for this listener
+	ProxyProtocol     bool          // Expect a PROXY protocol v1/v2 header on each connection (behind an L4 load balancer)
+	ReadHeaderTimeout time.Duration // Max time to read request headers (default: DefaultReadHeaderTimeout)
+	IdleTimeout       time.Duration // Max keep-alive idle time between requests (default: DefaultIdleTimeout)
+	MaxConnections    int           // Max concurrent connections; extra connections are refused (0 = unlimited)
+	MaxHeaderBytes    int           // Max size of request headers, answered with 431 beyond it (default: http.DefaultMaxHeaderBytes)
+	MaxHeaderCount    int           // Max number of request header lines, answered with 431 beyond it (0 = unlimited)
+	DefaultCertFile   string        // Certificate for handshakes whose SNI name matches no route, or that send none (default: first route's CertFile)
+	DefaultKeyFile    string        // Private key for DefaultCertFile
 }
 
+const (
+	// DefaultReadHeaderTimeout is used when a listener doesn't set ReadHeaderTimeout.
+	// It bounds how long a client may trickle in request headers (slowloris).
+	DefaultReadHeaderTimeout = 10 * time.Second
+	// DefaultIdleTimeout is used when a listener doesn't set IdleTimeout.
+	DefaultIdleTimeout = 120 * time.Second
+	// DefaultIdleConnTimeout is used when a route doesn't set IdleConnTimeout.
+	// It closes idle keep-alive connections to the backend.
+	DefaultIdleConnTimeout = 90 * time.Second
+	// DefaultExpectContinueTimeout is used when a route doesn't set
+	// ExpectContinueTimeout.
+	DefaultExpectContinueTimeout = time.Second
+)
+
 // RouteConfig defines a single routing rule that maps domains to a backend port.
 // Each route has its own SSL certificate for TLS termination.
 type RouteConfig struct {
@@ -62,6 +120,34 @@ type RouteConfig struct {
 	TargetPort string   // Backend port to proxy to (e.g., "1443")
 	CertFile   string   // Path to SSL certificate file
 	KeyFile    string   // Path to SSL private key file
+
+	// OCSPStapling staples the certificate's OCSP response to handshakes. The
+	// CertFile must hold the issuer certificate after the leaf.
+	OCSPStapling bool
+	// OCSPResponder overrides the OCSP responder URL named in the certificate.
+	OCSPResponder string
+	// ACME obtains and renews the certificate for Domains automatically. When
+	// set, CertFile, KeyFile and OCSPStapling are ignored.
+	ACME bool
+	// PreserveHost forwards the client's Host header to the backend instead of
+	// the backend address, for backends that route by virtual host.
+	PreserveHost bool
+
+	// ResponseHeaderTimeout bounds the wait for the backend's response headers
+	// once the request is sent. Zero waits indefinitely, as long-poll endpoints
+	// need; the response body is never bounded, so streams are not cut.
+	ResponseHeaderTimeout time.Duration
+	// IdleConnTimeout closes idle keep-alive connections to the backend
+	// (default DefaultIdleConnTimeout).
+	IdleConnTimeout time.Duration
+	// ExpectContinueTimeout bounds the wait for the backend's 100 Continue
+	// before sending a request body announced with "Expect: 100-continue"
+	// (default DefaultExpectContinueTimeout).
+	ExpectContinueTimeout time.Duration
+	// FlushInterval flushes responses to the client at this interval. Zero
+	// (the default) flushes after every write, so streamed responses are not
+	// buffered; raise it to batch writes of bulk routes.
+	FlushInterval time.Duration
 }
 
 // NewReverseProxy creates a ProxyConfig with the default Layer 8 routing configuration.
@@ -168,6 +254,7 @@ func NewReverseProxy() *ProxyConfig {
 // It blocks until one of the listeners returns an error, then returns that error.
 // Each listener runs in its own goroutine for concurrent multi-port operation.
 func (pc *ProxyConfig) Start() error {
+	pc.started = time.Now()
 	errChan := make(chan error, len(pc.Listeners))
 
 	for _, listener := range pc.Listeners {
@@ -178,6 +265,22 @@ func (pc *ProxyConfig) Start() error {
 		}(listener)
 	}
 
+	if pc.TrafficLogInterval > 0 {
+		go pc.logTraffic(pc.TrafficLogInterval)
+	}
+
+	if pc.ACMEHTTPPort != "" && len(pc.acmeDomains()) > 0 {
+		go func() {
+			errChan <- pc.startACMEHTTP()
+		}()
+	}
+
+	if pc.AdminAddr != "" {
+		go func() {
+			errChan <- pc.startAdmin()
+		}()
+	}
+
 	// Wait for first error from any listener
 	return <-errChan
 }
@@ -185,7 +288,11 @@ func (pc *ProxyConfig) Start() error {
 // startListener initializes and starts a single port listener.
 // It creates reverse proxy handlers for each route, sets up SNI-based certificate
 // selection, and starts the HTTPS server. The backend host is determined by the
-// NODE_IP environment variable (defaults to "localhost").
+// NODE_IP environment variable (defaults to "localhost"). If ProxyProtocol is set,
+// the listener parses PROXY protocol headers so backends see the real client IP
+// in X-Forwarded-For and X-Real-IP. ReadHeaderTimeout, IdleTimeout and
+// MaxConnections guard the listener against slow or idle connection floods,
+// MaxHeaderBytes and MaxHeaderCount against header floods.
 //
 // The function sets up two types of handlers:
 // 1. Domain-specific pattern handlers (e.g., "example.com/")
@@ -193,10 +300,7 @@ func (pc *ProxyConfig) Start() error {
 func (pc *ProxyConfig) startListener(listener ListenerConfig) error {
 	mux := http.NewServeMux()
 
-	hostname := os.Getenv("NODE_IP")
-	if hostname == "" {
-		hostname = "localhost"
-	}
+	hostname := backendHost()
 
 	for _, route := range listener.Routes {
 		targetURL, err := url.Parse(fmt.Sprintf("https://%s:%s", hostname, route.TargetPort))
@@ -204,24 +308,11 @@ func (pc *ProxyConfig) startListener(listener ListenerConfig) error {
 			return fmt.Errorf("failed to parse target URL for port %s: %v", route.TargetPort, err)
 		}
 
-		proxy := httputil.NewSingleHostReverseProxy(targetURL)
-
-		originalDirector := proxy.Director
-		proxy.Director = func(req *http.Request) {
-			originalDirector(req)
-			req.Host = req.URL.Host
-			req.URL.Scheme = "https"
-		}
-
-		proxy.Transport = &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: true,
-			},
-		}
+		proxy := pc.newBackendProxy(targetURL, route)
 
 		for _, domain := range route.Domains {
 			pattern := fmt.Sprintf("%s/", domain)
-			mux.HandleFunc(pattern, makeHandler(domain, hostname, route.TargetPort, proxy))
+			mux.HandleFunc(pattern, pc.makeHandler(domain, hostname, route.TargetPort, proxy, pc.trafficFor(listener.ListenPort, domain)))
 		}
 	}
 
@@ -232,35 +323,23 @@ func (pc *ProxyConfig) startListener(listener ListenerConfig) error {
 			for _, domain := range route.Domains {
 				hostWithoutPort := strings.Split(host, ":")[0]
 				if hostWithoutPort == domain || host == domain {
+					traffic := pc.trafficFor(listener.ListenPort, domain)
 					if isWebSocketUpgrade(r) {
-						proxyWebSocket(w, r, hostname, route.TargetPort)
+						pc.proxyWebSocket(w, r, hostname, route.TargetPort, traffic)
 						return
 					}
 
 					targetURL, _ := url.Parse(fmt.Sprintf("https://%s:%s", hostname, route.TargetPort))
-					proxy := httputil.NewSingleHostReverseProxy(targetURL)
-
-					originalDirector := proxy.Director
-					proxy.Director = func(req *http.Request) {
-						originalDirector(req)
-						req.Host = req.URL.Host
-						req.URL.Scheme = "https"
-					}
-
-					proxy.Transport = &http.Transport{
-						TLSClientConfig: &tls.Config{
-							InsecureSkipVerify: true,
-						},
-					}
+					proxy := pc.newBackendProxy(targetURL, route)
 
 					log.Printf("Proxying request from %s to %s:%s", host, hostname, route.TargetPort)
-					proxy.ServeHTTP(w, r)
+					withTraffic(traffic, proxy.ServeHTTP)(w, r)
 					return
 				}
 			}
 		}
 
-		http.Error(w, "Unknown host", http.StatusBadGateway)
+		pc.writeError(w, r, pc.ErrorPages.UnknownHost, http.StatusBadGateway, "Unknown host")
 	})
 
 	tlsConfig := &tls.Config{
@@ -270,21 +349,130 @@ func (pc *ProxyConfig) startListener(listener ListenerConfig) error {
 	}
 
 	tlsConfig.NextProtos = []string{"http/1.1"}
+	if hasACMERoutes(listener) {
+		tlsConfig.NextProtos = append(tlsConfig.NextProtos, acme.ALPNProto)
+	}
+
+	readHeaderTimeout := listener.ReadHeaderTimeout
+	if readHeaderTimeout == 0 {
+		readHeaderTimeout = DefaultReadHeaderTimeout
+	}
+	idleTimeout := listener.IdleTimeout
+	if idleTimeout == 0 {
+		idleTimeout = DefaultIdleTimeout
+	}
 
 	server := &http.Server{
-		Addr:      listener.ListenPort,
-		Handler:   mux,
-		TLSConfig: tlsConfig,
+		Addr:              listener.ListenPort,
+		Handler:           limitHeaderCount(mux, listener.MaxHeaderCount),
+		TLSConfig:         tlsConfig,
+		ReadHeaderTimeout: readHeaderTimeout,
+		IdleTimeout:       idleTimeout,
+		MaxHeaderBytes:    listener.MaxHeaderBytes,
+	}
+
+	ln, err := net.Listen("tcp", listener.ListenPort)
+	if err != nil {
+		return err
+	}
+	if listener.MaxConnections > 0 {
+		ln = newLimitListener(ln, listener.MaxConnections)
+	}
+	if listener.ProxyProtocol {
+		ln = &proxyProtocolListener{Listener: ln}
 	}
 
 	log.Printf("Starting reverse proxy on port %s", listener.ListenPort)
-	return server.ListenAndServeTLS("", "")
+	return server.ServeTLS(ln, "", "")
+}
+
+// limitHeaderCount wraps a handler, answering requests with more than max
+// header lines (each value of a repeated header counts) with 431 Request
+// Header Fields Too Large. A max of 0 or less disables the check.
+func limitHeaderCount(next http.Handler, max int) http.Handler {
+	if max <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count := 0
+		for _, values := range r.Header {
+			count += len(values)
+		}
+		if count > max {
+			log.Printf("Refusing request from %s with %d headers (limit %d)", r.RemoteAddr, count, max)
+			http.Error(w, "Too many request headers", http.StatusRequestHeaderFieldsTooLarge)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// backendHost returns the host running the backends: the NODE_IP environment
+// variable, or localhost if it is not set.
+func backendHost() string {
+	if hostname := os.Getenv("NODE_IP"); hostname != "" {
+		return hostname
+	}
+	return "localhost"
+}
+
+// newBackendProxy creates a single-host reverse proxy to the given backend URL
+// for route. The Director rewrites the Host header to the backend address
+// unless the route preserves it, and passes the client's Host in
+// X-Forwarded-Host either way. The transport applies the route's timeouts and
+// skips certificate verification (backends use self-signed certs), and backend
+// failures are rendered through the configured BackendDown error page.
+func (pc *ProxyConfig) newBackendProxy(targetURL *url.URL, route RouteConfig) *httputil.ReverseProxy {
+	proxy := httputil.NewSingleHostReverseProxy(targetURL)
+	proxy.FlushInterval = route.FlushInterval
+	if proxy.FlushInterval == 0 {
+		proxy.FlushInterval = -1
+	}
+
+	originalDirector := proxy.Director
+	proxy.Director = func(req *http.Request) {
+		originalDirector(req)
+		req.Header.Set("X-Forwarded-Host", req.Host)
+		if !route.PreserveHost {
+			req.Host = req.URL.Host
+		}
+		req.URL.Scheme = "https"
+		setRealIP(req)
+	}
+
+	idleConnTimeout := route.IdleConnTimeout
+	if idleConnTimeout == 0 {
+		idleConnTimeout = DefaultIdleConnTimeout
+	}
+	expectContinueTimeout := route.ExpectContinueTimeout
+	if expectContinueTimeout == 0 {
+		expectContinueTimeout = DefaultExpectContinueTimeout
+	}
+	proxy.Transport = &http.Transport{
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: true,
+		},
+		ResponseHeaderTimeout: route.ResponseHeaderTimeout,
+		IdleConnTimeout:       idleConnTimeout,
+		ExpectContinueTimeout: expectContinueTimeout,
+	}
+
+	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		log.Printf("Backend %s unreachable: %v", targetURL.Host, err)
+		pc.writeError(w, r, pc.ErrorPages.BackendDown, http.StatusBadGateway, "Backend unavailable")
+	}
+	return proxy
 }
 
 // getCertificateForListener implements SNI-based certificate selection.
 // It searches the listener's routes for a matching domain and returns the
-// corresponding certificate. If no match is found, it falls back to the
-// first route's certificate (for domain aliases or misconfigured clients).
+// corresponding certificate, which for ACME routes is the one managed by the
+// proxy's autocert manager. If no match is found, including for clients that
+// send no SNI name (health checkers, IP-only connections), it falls back to the
+// listener's DefaultCertFile, or to the first route's certificate if that is
+// not set, and logs the fallback.
+//
+// Certificates are loaded once and cached, see ReloadCertificates.
 //
 // This function is called during the TLS handshake via tls.Config.GetCertificate.
 func (pc *ProxyConfig) getCertificateForListener(info *tls.ClientHelloInfo, listener ListenerConfig) (*tls.Certificate, error) {
@@ -293,33 +481,52 @@ func (pc *ProxyConfig) getCertificateForListener(info *tls.ClientHelloInfo, list
 	for _, route := range listener.Routes {
 		for _, domain := range route.Domains {
 			if host == domain {
-				cert, err := tls.LoadX509KeyPair(route.CertFile, route.KeyFile)
+				if route.ACME {
+					return pc.acmeCertificate(info, domain)
+				}
+				cert, err := pc.certificate(route.CertFile, route.KeyFile)
 				if err != nil {
 					log.Printf("Error loading certificate for %s: %v", domain, err)
 					return nil, err
 				}
-				return &cert, nil
+				if route.OCSPStapling {
+					stapled := *cert
+					staples.staple(route, &stapled)
+					return &stapled, nil
+				}
+				return cert, nil
 			}
 		}
 	}
 
-	// Fallback to first route's certificate
-	if len(listener.Routes) > 0 {
-		cert, err := tls.LoadX509KeyPair(listener.Routes[0].CertFile, listener.Routes[0].KeyFile)
-		if err != nil {
-			return nil, err
+	certFile, keyFile := listener.DefaultCertFile, listener.DefaultKeyFile
+	if certFile == "" && len(listener.Routes) > 0 {
+		if route := listener.Routes[0]; route.ACME && len(route.Domains) > 0 {
+			log.Printf("No route certificate for SNI name %q on %s, using fallback %s", host, listener.ListenPort, route.Domains[0])
+			return pc.acmeCertificate(info, route.Domains[0])
 		}
-		return &cert, nil
+		certFile, keyFile = listener.Routes[0].CertFile, listener.Routes[0].KeyFile
+	}
+	if certFile == "" {
+		return nil, fmt.Errorf("no certificate found for host: %s", host)
 	}
 
-	return nil, fmt.Errorf("no certificate found for host: %s", host)
+	log.Printf("No route certificate for SNI name %q on %s, using fallback %s", host, listener.ListenPort, certFile)
+	cert, err := pc.certificate(certFile, keyFile)
+	if err != nil {
+		log.Printf("Error loading fallback certificate %s: %v", certFile, err)
+		return nil, err
+	}
+	return cert, nil
 }
 
 // Run creates a new reverse proxy with default configuration and starts it.
 // This is the main entry point for running the proxy as a standalone service.
+// SIGHUP reloads the certificates, see ReloadCertificates.
 // It blocks until an error occurs and calls log.Fatal on failure.
 func Run() {
 	proxy := NewReverseProxy()
+	go proxy.reloadOnHangup()
 	if err := proxy.Start(); err != nil {
 		log.Fatal("Failed to start proxy:", err)
 	}
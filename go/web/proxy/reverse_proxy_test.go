@@ -0,0 +1,183 @@
+package proxy
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeCert writes a self-signed certificate for commonName to dir and
+// returns the certificate and key file paths.
+func writeCert(t *testing.T, dir, commonName string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile := filepath.Join(dir, commonName+".cert.pem")
+	keyFile := filepath.Join(dir, commonName+".key.pem")
+	if err = os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+// certFor returns the common name of the certificate the listener serves for serverName.
+func certFor(t *testing.T, pc *ProxyConfig, serverName string, listener ListenerConfig) string {
+	cert, err := pc.getCertificateForListener(&tls.ClientHelloInfo{ServerName: serverName}, listener)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	parsed, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed.Subject.CommonName
+}
+
+func TestGetCertificateForListener_Fallback(t *testing.T) {
+	dir := t.TempDir()
+	routeCert, routeKey := writeCert(t, dir, "example.com")
+	defaultCert, defaultKey := writeCert(t, dir, "default")
+	pc := &ProxyConfig{}
+	listener := ListenerConfig{
+		ListenPort: ":443",
+		Routes:     []RouteConfig{{Domains: []string{"example.com"}, CertFile: routeCert, KeyFile: routeKey}},
+	}
+
+	name := certFor(t, pc, "Example.com", listener)
+	if name != "example.com" {
+		t.Fatalf("expected the route certificate, got %s", name)
+	}
+	name = certFor(t, pc, "", listener)
+	if name != "example.com" {
+		t.Fatalf("expected the first route's certificate without a default, got %s", name)
+	}
+
+	listener.DefaultCertFile = defaultCert
+	listener.DefaultKeyFile = defaultKey
+	for _, serverName := range []string{"", "other.com"} {
+		name = certFor(t, pc, serverName, listener)
+		if name != "default" {
+			t.Fatalf("expected the default certificate for %q, got %s", serverName, name)
+		}
+	}
+
+	if _, err := pc.getCertificateForListener(&tls.ClientHelloInfo{}, ListenerConfig{}); err == nil {
+		t.Fatal("expected an error without routes or a default certificate")
+	}
+}
+
+func TestLimitHeaderCount(t *testing.T) {
+	handler := limitHeaderCount(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), 3)
+	serve := func(headers int) int {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		for i := 0; i < headers; i++ {
+			r.Header.Add("X-Forwarded-For", "10.0.0.1")
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+	if code := serve(3); code != http.StatusOK {
+		t.Fatalf("expected a request at the limit to pass, got %d", code)
+	}
+	if code := serve(4); code != http.StatusRequestHeaderFieldsTooLarge {
+		t.Fatalf("expected 431 over the limit, got %d", code)
+	}
+}
+
+func TestNewBackendProxy_PreserveHost(t *testing.T) {
+	var host, forwardedHost string
+	backend := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, forwardedHost = r.Host, r.Header.Get("X-Forwarded-Host")
+	}))
+	defer backend.Close()
+	targetURL, _ := url.Parse(backend.URL)
+
+	pc := &ProxyConfig{}
+	for _, preserveHost := range []bool{false, true} {
+		r := httptest.NewRequest(http.MethodGet, "https://example.com/", nil)
+		pc.newBackendProxy(targetURL, RouteConfig{PreserveHost: preserveHost}).ServeHTTP(httptest.NewRecorder(), r)
+		expected := targetURL.Host
+		if preserveHost {
+			expected = "example.com"
+		}
+		if host != expected {
+			t.Fatalf("preserveHost=%v: expected Host %s, got %s", preserveHost, expected, host)
+		}
+		if forwardedHost != "example.com" {
+			t.Fatalf("preserveHost=%v: expected X-Forwarded-Host example.com, got %q", preserveHost, forwardedHost)
+		}
+	}
+}
+
+func TestNewBackendProxy_Timeouts(t *testing.T) {
+	release := make(chan struct{})
+	backend := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.Write([]byte("first\n"))
+		http.NewResponseController(w).Flush()
+		<-release
+		w.Write([]byte("second\n"))
+	}))
+	defer backend.Close()
+	targetURL, _ := url.Parse(backend.URL)
+
+	pc := &ProxyConfig{}
+	front := httptest.NewServer(pc.newBackendProxy(targetURL, RouteConfig{ResponseHeaderTimeout: 100 * time.Millisecond}))
+	defer front.Close()
+	defer close(release)
+
+	// The first line arrives while the backend is still streaming, and the
+	// response header timeout doesn't cut the body.
+	response, err := http.Get(front.URL + "/stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	line := make([]byte, len("first\n"))
+	if _, err = io.ReadFull(response.Body, line); err != nil || string(line) != "first\n" {
+		t.Fatalf("expected the flushed first line, got %q, %v", line, err)
+	}
+
+	response, err = http.Get(front.URL + "/slow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusBadGateway {
+		t.Fatalf("expected 502 past the response header timeout, got %d", response.StatusCode)
+	}
+}
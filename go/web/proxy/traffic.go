@@ -0,0 +1,139 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proxy
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// Traffic accounting counts, per listener and route domain, the requests
+// proxied and the bytes they carried: request bodies in, response bodies out,
+// and for WebSocket connections the frames in each direction. Headers are not
+// counted. The totals are cumulative since the proxy started; Traffic returns
+// them, and TrafficLogInterval logs them periodically.
+
+// RouteTraffic is the traffic proxied for one domain of a listener.
+type RouteTraffic struct {
+	Listener string `json:"listener"` // Listen port of the listener (e.g., ":443")
+	Domain   string `json:"domain"`   // Route domain the requests were addressed to
+	Requests int64  `json:"requests"` // Requests proxied, WebSocket upgrades included
+	BytesIn  int64  `json:"bytesIn"`  // Body bytes received from clients
+	BytesOut int64  `json:"bytesOut"` // Body bytes sent to clients
+}
+
+// trafficKey identifies the counters of a domain on a listener.
+type trafficKey struct {
+	listener string
+	domain   string
+}
+
+// trafficCounter accumulates the traffic of one domain on one listener.
+type trafficCounter struct {
+	requests atomic.Int64
+	bytesIn  atomic.Int64
+	bytesOut atomic.Int64
+}
+
+// trafficFor returns the counter of domain on the listener at listenPort,
+// creating it on first use.
+func (pc *ProxyConfig) trafficFor(listenPort, domain string) *trafficCounter {
+	counter, _ := pc.traffic.LoadOrStore(trafficKey{listener: listenPort, domain: domain}, &trafficCounter{})
+	return counter.(*trafficCounter)
+}
+
+// Traffic returns the traffic proxied so far per listener and domain, sorted
+// by listener then domain. Every domain of a started listener is listed, with
+// zero counts until it receives a request.
+func (pc *ProxyConfig) Traffic() []RouteTraffic {
+	result := []RouteTraffic{}
+	pc.traffic.Range(func(key, value any) bool {
+		k := key.(trafficKey)
+		counter := value.(*trafficCounter)
+		result = append(result, RouteTraffic{
+			Listener: k.listener,
+			Domain:   k.domain,
+			Requests: counter.requests.Load(),
+			BytesIn:  counter.bytesIn.Load(),
+			BytesOut: counter.bytesOut.Load(),
+		})
+		return true
+	})
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Listener != result[j].Listener {
+			return result[i].Listener < result[j].Listener
+		}
+		return result[i].Domain < result[j].Domain
+	})
+	return result
+}
+
+// logTraffic logs the traffic totals every interval, forever.
+func (pc *ProxyConfig) logTraffic(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		for _, t := range pc.Traffic() {
+			log.Printf("Traffic %s %s: %d requests, %d bytes in, %d bytes out", t.Listener, t.Domain, t.Requests, t.BytesIn, t.BytesOut)
+		}
+	}
+}
+
+// withTraffic wraps a handler, counting its requests and their request and
+// response body bytes in counter.
+func withTraffic(counter *trafficCounter, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		counter.requests.Add(1)
+		if r.Body != nil && r.Body != http.NoBody {
+			r.Body = &countingBody{ReadCloser: r.Body, counter: counter}
+		}
+		next(&countingWriter{ResponseWriter: w, counter: counter}, r)
+	}
+}
+
+// countingBody counts the bytes read from a request body.
+type countingBody struct {
+	io.ReadCloser
+	counter *trafficCounter
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.counter.bytesIn.Add(int64(n))
+	return n, err
+}
+
+// countingWriter counts the bytes written to a response.
+type countingWriter struct {
+	http.ResponseWriter
+	counter *trafficCounter
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.counter.bytesOut.Add(int64(n))
+	return n, err
+}
+
+// Unwrap lets http.ResponseController, which the reverse proxy flushes and
+// hijacks through, reach the wrapped writer.
+func (w *countingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proxy
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestTraffic(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write([]byte("echo:" + string(body)))
+	}))
+	defer backend.Close()
+	target, _ := url.Parse(backend.URL)
+
+	pc := &ProxyConfig{}
+	handler := pc.makeHandler("example.com", target.Hostname(), target.Port(), httputil.NewSingleHostReverseProxy(target), pc.trafficFor(":443", "example.com"))
+	for _, body := range []string{"hello", "world!"} {
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest(http.MethodPost, "https://example.com/", strings.NewReader(body)))
+		if w.Body.String() != "echo:"+body {
+			t.Fatalf("unexpected response %q", w.Body.String())
+		}
+	}
+	pc.trafficFor(":8443", "api.example.com")
+
+	traffic := pc.Traffic()
+	if len(traffic) != 2 {
+		t.Fatalf("expected 2 entries, got %+v", traffic)
+	}
+	expected := RouteTraffic{Listener: ":443", Domain: "example.com", Requests: 2, BytesIn: 11, BytesOut: 21}
+	if traffic[0] != expected {
+		t.Fatalf("expected %+v, got %+v", expected, traffic[0])
+	}
+	if traffic[1].Listener != ":8443" || traffic[1].Requests != 0 {
+		t.Fatalf("expected an empty :8443 entry, got %+v", traffic[1])
+	}
+}
@@ -32,7 +32,8 @@ func isWebSocketUpgrade(r *http.Request) bool {
 	return strings.Contains(conn, "upgrade") && upgrade == "websocket"
 }
 
-func proxyWebSocket(w http.ResponseWriter, r *http.Request, backendHost string, backendPort string) {
+func (pc *ProxyConfig) proxyWebSocket(w http.ResponseWriter, r *http.Request, backendHost string, backendPort string, traffic *trafficCounter) {
+	traffic.requests.Add(1)
 	hijacker, ok := w.(http.Hijacker)
 	if !ok {
 		http.Error(w, "WebSocket hijack not supported", http.StatusInternalServerError)
@@ -45,10 +46,12 @@ func proxyWebSocket(w http.ResponseWriter, r *http.Request, backendHost string,
 	})
 	if err != nil {
 		log.Printf("WebSocket: TLS dial to backend %s failed: %v", backendAddr, err)
-		http.Error(w, "Backend connection failed", http.StatusBadGateway)
+		pc.writeError(w, r, pc.ErrorPages.BackendDown, http.StatusBadGateway, "Backend connection failed")
 		return
 	}
 
+	setForwardedFor(r)
+	r.Header.Set("X-Forwarded-Host", r.Host)
 	err = r.Write(backendConn)
 	if err != nil {
 		backendConn.Close()
@@ -69,6 +72,7 @@ func proxyWebSocket(w http.ResponseWriter, r *http.Request, backendHost string,
 		n, _ := clientBuf.Read(buffered)
 		if n > 0 {
 			backendConn.Write(buffered[:n])
+			traffic.bytesIn.Add(int64(n))
 		}
 	}
 
@@ -77,25 +81,28 @@ func proxyWebSocket(w http.ResponseWriter, r *http.Request, backendHost string,
 
 	go func() {
 		defer wg.Done()
-		io.Copy(clientConn, backendConn)
+		n, _ := io.Copy(clientConn, backendConn)
+		traffic.bytesOut.Add(n)
 		clientConn.Close()
 	}()
 
 	go func() {
 		defer wg.Done()
-		io.Copy(backendConn, clientConn)
+		n, _ := io.Copy(backendConn, clientConn)
+		traffic.bytesIn.Add(n)
 		backendConn.Close()
 	}()
 
 	wg.Wait()
 }
 
-func makeHandler(domain string, hostname string, targetPort string, proxy *httputil.ReverseProxy) http.HandlerFunc {
+func (pc *ProxyConfig) makeHandler(domain string, hostname string, targetPort string, proxy *httputil.ReverseProxy, traffic *trafficCounter) http.HandlerFunc {
+	proxied := withTraffic(traffic, proxy.ServeHTTP)
 	return func(w http.ResponseWriter, r *http.Request) {
 		if isWebSocketUpgrade(r) {
-			proxyWebSocket(w, r, hostname, targetPort)
+			pc.proxyWebSocket(w, r, hostname, targetPort, traffic)
 			return
 		}
-		proxy.ServeHTTP(w, r)
+		proxied(w, r)
 	}
 }
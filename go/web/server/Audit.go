@@ -0,0 +1,189 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Audit.go reports every mutating service request to an optional audit hook,
+// with who made it, what it changed and how it ended.
+//
+// The hook runs on its own goroutine once the response is written, so a slow
+// or panicking hook never delays or fails the request. Records may therefore
+// reach the hook out of order; AuditRecord.Time orders them.
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// AuditRedacted replaces the values of redacted fields in AuditRecord.Body.
+const AuditRedacted = "[REDACTED]"
+
+// AuditRecord describes one POST, PUT, PATCH or DELETE service request.
+type AuditRecord struct {
+	Time        time.Time // When the request arrived
+	RequestID   string    // The request id sent in RequestIDHeader
+	UserID      string    // Authenticated user id, "" if the method doesn't require a bearer token or authentication failed
+	ServiceName string    // Web service the request was sent to
+	ServiceArea byte      // Service area of the web service
+	Method      string    // HTTP method
+	Path        string    // Request URL path
+	Body        []byte    // Request body as received, with AuditRedactFields redacted
+	Status      int       // Response status, 0 if the client went away before one was written
+}
+
+// auditor holds a handler's audit settings, nil when auditing is disabled.
+type auditor struct {
+	hook   func(AuditRecord)
+	redact map[string]bool // Lower case field names to redact
+}
+
+func newAuditor(hook func(AuditRecord), redactFields []string) *auditor {
+	a := &auditor{hook: hook, redact: make(map[string]bool, len(redactFields))}
+	for _, field := range redactFields {
+		a.redact[strings.ToLower(field)] = true
+	}
+	return a
+}
+
+// start returns a writer recording the request's outcome if r is a mutating
+// request, or nil, on which every auditWriter method is a no-op.
+func (this *auditor) start(w http.ResponseWriter, r *http.Request, handler *ServiceHandler, reqID string) *auditWriter {
+	if this == nil {
+		return nil
+	}
+	switch r.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+	default:
+		return nil
+	}
+	return &auditWriter{ResponseWriter: w, auditor: this, form: isFormContentType(r), record: AuditRecord{
+		Time:        time.Now(),
+		RequestID:   reqID,
+		ServiceName: handler.serviceName,
+		ServiceArea: handler.serviceArea,
+		Method:      r.Method,
+		Path:        r.URL.Path,
+	}}
+}
+
+// auditWriter captures the response status of an audited request.
+type auditWriter struct {
+	http.ResponseWriter
+	auditor *auditor
+	form    bool // The body is form encoded rather than JSON
+	record  AuditRecord
+}
+
+func (this *auditWriter) WriteHeader(status int) {
+	if this.record.Status == 0 {
+		this.record.Status = status
+	}
+	this.ResponseWriter.WriteHeader(status)
+}
+
+func (this *auditWriter) Write(data []byte) (int, error) {
+	if this.record.Status == 0 {
+		this.record.Status = http.StatusOK
+	}
+	return this.ResponseWriter.Write(data)
+}
+
+// Unwrap lets http.ResponseController reach the wrapped writer, e.g. to flush.
+func (this *auditWriter) Unwrap() http.ResponseWriter {
+	return this.ResponseWriter
+}
+
+// setUser records the authenticated user id.
+func (this *auditWriter) setUser(aaaid string) {
+	if this != nil {
+		this.record.UserID = aaaid
+	}
+}
+
+// setBody records the request body, redacted.
+func (this *auditWriter) setBody(body []byte) {
+	if this != nil {
+		this.record.Body = this.auditor.redactBody(body, this.form)
+	}
+}
+
+// done hands the record to the hook on its own goroutine. A panic in the hook
+// is logged and otherwise ignored.
+func (this *auditWriter) done() {
+	if this == nil {
+		return
+	}
+	record := this.record
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				fmt.Println("["+record.RequestID+"] Audit hook failed:", r)
+			}
+		}()
+		this.auditor.hook(record)
+	}()
+}
+
+// redactBody replaces the values of the redacted fields in a form encoded
+// body, or at any depth in a JSON body. A body that doesn't parse is kept as
+// is, the service rejects it anyway.
+func (this *auditor) redactBody(body []byte, isForm bool) []byte {
+	if len(this.redact) == 0 || len(body) == 0 {
+		return body
+	}
+	if !isForm {
+		var doc interface{}
+		if json.Unmarshal(body, &doc) != nil {
+			return body
+		}
+		redacted, err := json.Marshal(this.redactValue(doc))
+		if err != nil {
+			return body
+		}
+		return redacted
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return body
+	}
+	for field := range form {
+		if this.redact[strings.ToLower(field)] {
+			form[field] = []string{AuditRedacted}
+		}
+	}
+	return []byte(form.Encode())
+}
+
+func (this *auditor) redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for field, fieldValue := range v {
+			if this.redact[strings.ToLower(field)] {
+				v[field] = AuditRedacted
+			} else {
+				v[field] = this.redactValue(fieldValue)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = this.redactValue(item)
+		}
+	}
+	return value
+}
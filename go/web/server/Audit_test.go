@@ -0,0 +1,105 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saichler/l8types/go/ifs"
+)
+
+// auditVnic is an echoVnic that accepts any bearer token as the user's id.
+type auditVnic struct {
+	echoVnic
+}
+
+func (this *auditVnic) Resources() ifs.IResources {
+	return &securityResources{security: &tokenSecurity{}}
+}
+
+func auditRequest(handler *ServiceHandler, method, user, body string) int {
+	r := httptest.NewRequest(method, "/3/Tests", strings.NewReader(body))
+	if user != "" {
+		r.Header.Set("Authorization", "Bearer "+user)
+	}
+	w := httptest.NewRecorder()
+	handler.serveHttp(w, r)
+	return w.Code
+}
+
+func TestAudit_Hook(t *testing.T) {
+	records := make(chan AuditRecord, 10)
+	handler := &ServiceHandler{serviceName: "Tests", serviceArea: 3, authEnabled: true,
+		webService: &echoService{}, vnic: &auditVnic{},
+		audit: newAuditor(func(record AuditRecord) { records <- record }, []string{"Text"})}
+
+	if status := auditRequest(handler, http.MethodPost, "alice", `{"text":"secret"}`); status != http.StatusOK {
+		t.Fatalf("expected 200, got %d", status)
+	}
+	record := <-records
+	if record.UserID != "alice" || record.Method != http.MethodPost || record.Status != http.StatusOK ||
+		record.ServiceName != "Tests" || record.ServiceArea != 3 || record.Path != "/3/Tests" || record.RequestID == "" {
+		t.Fatalf("unexpected record %+v", record)
+	}
+	if string(record.Body) != `{"text":"[REDACTED]"}` {
+		t.Fatalf("expected the text to be redacted, got %s", record.Body)
+	}
+
+	// Rejected writes are audited too.
+	if status := auditRequest(handler, http.MethodDelete, "", `{}`); status != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", status)
+	}
+	if record = <-records; record.UserID != "" || record.Status != http.StatusUnauthorized {
+		t.Fatalf("unexpected record for a rejected delete %+v", record)
+	}
+
+	// Reads are not.
+	auditRequest(handler, http.MethodGet, "alice", "")
+	select {
+	case record = <-records:
+		t.Fatalf("unexpected record for a GET %+v", record)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestAudit_HookPanic(t *testing.T) {
+	handler := &ServiceHandler{serviceName: "Tests", webService: &echoService{}, vnic: &auditVnic{},
+		audit: newAuditor(func(AuditRecord) { panic("audit sink down") }, nil)}
+	if status := auditRequest(handler, http.MethodPut, "", `{"text":"x"}`); status != http.StatusOK {
+		t.Fatalf("expected the request to succeed despite the hook, got %d", status)
+	}
+}
+
+func TestAudit_Redact(t *testing.T) {
+	a := newAuditor(nil, []string{"password", "Token"})
+	for _, test := range []struct {
+		body, expected string
+		form           bool
+	}{
+		{`{"user":{"name":"bob","PASSWORD":"x"},"tokens":[{"token":"y"}]}`,
+			`{"tokens":[{"token":"[REDACTED]"}],"user":{"PASSWORD":"[REDACTED]","name":"bob"}}`, false},
+		{`name=bob&password=x`, `name=bob&password=%5BREDACTED%5D`, true},
+		{`not json`, `not json`, false},
+	} {
+		if redacted := string(a.redactBody([]byte(test.body), test.form)); redacted != test.expected {
+			t.Fatalf("expected %s, got %s", test.expected, redacted)
+		}
+	}
+}
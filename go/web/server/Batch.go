@@ -0,0 +1,195 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Batch.go serves the ":batch" sub-path of every web service. A POST to
+// {service path}:batch carries a JSON array of sub-requests, each with an HTTP
+// method and body, which are dispatched through the service's regular handler
+// concurrently and answered as one JSON array of results in the same order.
+//
+// Each sub-request goes through serveHttp with the batch request's headers, so
+// authentication, body parsing, timeouts and error envelopes behave exactly as
+// for a single request.
+
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// BatchSuffix is appended to a service path to reach its batch endpoint,
+// e.g. "/api/v1/0/Users:batch".
+const BatchSuffix = ":batch"
+
+// BatchWorkers is the number of sub-requests of a batch dispatched concurrently.
+var BatchWorkers = 8
+
+// MaxBatchSize is the largest number of sub-requests accepted in one batch.
+var MaxBatchSize = 100
+
+// BatchRequest is one sub-request of a batch.
+type BatchRequest struct {
+	Method string          `json:"method"`         // HTTP method (GET, POST, PUT, PATCH, DELETE)
+	Body   json.RawMessage `json:"body,omitempty"` // Request body, as sent to the service path
+}
+
+// BatchResponse is the result of one sub-request of a batch.
+type BatchResponse struct {
+	Status int             `json:"status"`         // HTTP status the sub-request would have returned
+	Body   json.RawMessage `json:"body,omitempty"` // Response body, or an ErrorResponse on failure
+}
+
+// batchMethods are the methods a sub-request may use.
+var batchMethods = map[string]bool{
+	http.MethodGet:    true,
+	http.MethodPost:   true,
+	http.MethodPut:    true,
+	http.MethodPatch:  true,
+	http.MethodDelete: true,
+}
+
+// batchWriter records a sub-request's response in memory.
+type batchWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (this *batchWriter) Header() http.Header {
+	return this.header
+}
+
+func (this *batchWriter) WriteHeader(status int) {
+	if this.status == 0 {
+		this.status = status
+	}
+}
+
+func (this *batchWriter) Write(data []byte) (int, error) {
+	if this.status == 0 {
+		this.status = http.StatusOK
+	}
+	return this.body.Write(data)
+}
+
+// serveBatch handles a POST to the service's batch endpoint. It returns HTTP 405
+// for other methods, HTTP 415 for a non-JSON body, HTTP 400 for a body that is
+// not an array of valid sub-requests or has more than MaxBatchSize of them, and
+// otherwise HTTP 200 OK with one BatchResponse per sub-request.
+func (this *ServiceHandler) serveBatch(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	if !isJSONContentType(r) {
+		writeError(w, http.StatusUnsupportedMediaType, "Unsupported Content-Type "+r.Header.Get("Content-Type")+", expected "+JSONContentType)
+		return
+	}
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Failed to read batch body: "+err.Error())
+		return
+	}
+	reqs := make([]BatchRequest, 0)
+	err = json.Unmarshal(data, &reqs)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid batch body, expected an array of {method, body}: "+err.Error())
+		return
+	}
+	if len(reqs) > MaxBatchSize {
+		writeError(w, http.StatusBadRequest, "Batch of "+strconv.Itoa(len(reqs))+" requests exceeds the limit of "+strconv.Itoa(MaxBatchSize))
+		return
+	}
+	for i, req := range reqs {
+		if !batchMethods[strings.ToUpper(req.Method)] {
+			writeError(w, http.StatusBadRequest, "Batch request "+strconv.Itoa(i)+" has unsupported method \""+req.Method+"\"")
+			return
+		}
+	}
+
+	reqID := requestID(r)
+	w.Header().Set(RequestIDHeader, reqID)
+	results := make([]*BatchResponse, len(reqs))
+	workers := BatchWorkers
+	if workers <= 0 {
+		workers = 1
+	}
+	if workers > len(reqs) {
+		workers = len(reqs)
+	}
+
+	indexes := make(chan int)
+	wg := sync.WaitGroup{}
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				results[index] = this.serveBatchItem(r, reqID+"-"+strconv.Itoa(index), reqs[index])
+			}
+		}()
+	}
+	for index := range reqs {
+		indexes <- index
+	}
+	close(indexes)
+	wg.Wait()
+
+	j, err := json.Marshal(results)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to marshal batch response: "+err.Error())
+		fmt.Println("[" + reqID + "] Failed to marshal batch response: " + err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", JSONContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(j)
+}
+
+// serveBatchItem runs one sub-request through serveHttp as a request to the
+// service path, with the batch request's headers and context.
+func (this *ServiceHandler) serveBatchItem(r *http.Request, reqID string, req BatchRequest) *BatchResponse {
+	w := &batchWriter{header: http.Header{}}
+	sub, err := http.NewRequestWithContext(r.Context(), strings.ToUpper(req.Method), strings.TrimSuffix(r.URL.Path, BatchSuffix), bytes.NewReader(req.Body))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid batch request: "+err.Error())
+		return &BatchResponse{Status: w.status, Body: w.body.Bytes()}
+	}
+	sub.Header = r.Header.Clone()
+	sub.Header.Set("Content-Type", JSONContentType)
+	sub.Header.Set(RequestIDHeader, reqID)
+	sub.Header.Del("Content-Length")
+	sub.Header.Del("If-None-Match")
+	sub.Header.Del("If-Modified-Since")
+	// Batch results are JSON documents, never streams
+	sub.Header.Del("Accept")
+	sub.RemoteAddr = r.RemoteAddr
+
+	this.serveHttp(w, sub)
+	if w.status == 0 {
+		// serveHttp writes nothing for an abandoned request: the client went away.
+		return &BatchResponse{Status: http.StatusServiceUnavailable}
+	}
+	result := &BatchResponse{Status: w.status}
+	if json.Valid(w.body.Bytes()) {
+		result.Body = w.body.Bytes()
+	}
+	return result
+}
@@ -0,0 +1,130 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8types/go/types/l8api"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// echoService is an ifs.IWebService whose bodies are L8Query parsed from the request.
+type echoService struct {
+	ifs.IWebService
+}
+
+func (this *echoService) Protos(data string, action ifs.Action) (proto.Message, proto.Message, error) {
+	query := &l8api.L8Query{}
+	return query, nil, protojson.Unmarshal([]byte(data), query)
+}
+
+// echoElements answers a request with its own body, or fails for the text "fail".
+type echoElements struct {
+	ifs.IElements
+	query *l8api.L8Query
+}
+
+func (this *echoElements) Error() error {
+	if this.query.Text == "fail" {
+		return errors.New("backend failure")
+	}
+	return nil
+}
+
+func (this *echoElements) Element() interface{}                      { return this.query }
+func (this *echoElements) AsList(ifs.IRegistry) (interface{}, error) { return this.query, nil }
+
+// echoVnic is an ifs.IVNic whose leader requests echo the request body.
+type echoVnic struct {
+	ifs.IVNic
+}
+
+func (this *echoVnic) Resources() ifs.IResources {
+	return &quietResources{}
+}
+
+func (this *echoVnic) Running() bool {
+	return true
+}
+
+func (this *echoVnic) LeaderRequest(serviceName string, serviceArea byte, action ifs.Action, body interface{}, timeout int, tokens ...string) ifs.IElements {
+	return &echoElements{query: body.(*l8api.L8Query)}
+}
+
+func TestBatch_Dispatch(t *testing.T) {
+	handler := &ServiceHandler{serviceName: "Tests", webService: &echoService{}, vnic: &echoVnic{}}
+	body := `[
+		{"method": "GET", "body": {"text": "select * from a"}},
+		{"method": "post", "body": {"text": "fail"}},
+		{"method": "PUT", "body": {"text": 7}},
+		{"method": "DELETE", "body": {"text": "select * from d"}}
+	]`
+	r := httptest.NewRequest(http.MethodPost, "/0/Tests"+BatchSuffix, strings.NewReader(body))
+	r.Header.Set(RequestIDHeader, "batch1")
+	w := httptest.NewRecorder()
+	handler.serveBatch(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	results := make([]*BatchResponse, 0)
+	if err := json.Unmarshal(w.Body.Bytes(), &results); err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 4 {
+		t.Fatalf("expected 4 results, got %d", len(results))
+	}
+	for i, text := range map[int]string{0: "select * from a", 3: "select * from d"} {
+		query := &l8api.L8Query{}
+		if results[i].Status != http.StatusOK || protojson.Unmarshal(results[i].Body, query) != nil || query.Text != text {
+			t.Fatalf("unexpected result %d: %d %s", i, results[i].Status, results[i].Body)
+		}
+	}
+	for _, i := range []int{1, 2} {
+		errResp := &ErrorResponse{}
+		if results[i].Status != http.StatusBadRequest || json.Unmarshal(results[i].Body, errResp) != nil || errResp.Error.Code != http.StatusBadRequest {
+			t.Fatalf("expected result %d to be a 400 envelope, got %d %s", i, results[i].Status, results[i].Body)
+		}
+	}
+}
+
+func TestBatch_Invalid(t *testing.T) {
+	defer func(max int) { MaxBatchSize = max }(MaxBatchSize)
+	MaxBatchSize = 2
+	handler := &ServiceHandler{serviceName: "Tests", webService: &echoService{}, vnic: &echoVnic{}}
+
+	w := httptest.NewRecorder()
+	handler.serveBatch(w, httptest.NewRequest(http.MethodGet, "/0/Tests"+BatchSuffix, nil))
+	decodeError(t, w, http.StatusMethodNotAllowed)
+
+	for _, body := range []string{
+		`{"method": "GET"}`,
+		`[{"method": "TRACE"}]`,
+		`[{"method": "GET"}, {"method": "GET"}, {"method": "GET"}]`,
+	} {
+		w = httptest.NewRecorder()
+		handler.serveBatch(w, httptest.NewRequest(http.MethodPost, "/0/Tests"+BatchSuffix, strings.NewReader(body)))
+		decodeError(t, w, http.StatusBadRequest)
+	}
+}
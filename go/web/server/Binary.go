@@ -0,0 +1,73 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Binary.go lets a service answer with raw bytes, such as a PDF report, a CSV
+// export or an image, instead of a JSON document.
+//
+// Like CreatedResource, the indicator is an optional interface on the
+// response element: an element implementing BinaryResource is written as is
+// with its content type, and any other element is marshaled to JSON or
+// streamed. The bytes keep the status the JSON would have had, 201 Created
+// included, and with ETags enabled a matching GET gets 304 Not Modified. A
+// generated Protocol Buffer type gets it by declaring the methods in a file of
+// its own package, typically over a bytes field:
+//
+//	func (this *Report) BinaryContentType() string { return "application/pdf" }
+//	func (this *Report) BinaryData() []byte        { return this.Pdf }
+//	func (this *Report) BinaryDisposition() string {
+//	    return `attachment; filename="` + this.Name + `.pdf"`
+//	}
+
+package server
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// BinaryResource is implemented by a service response element whose payload is
+// sent to the client as raw bytes rather than marshaled to JSON. The method
+// names are prefixed so they don't clash with the getters of generated fields.
+type BinaryResource interface {
+	// BinaryContentType returns the Content-Type of the payload, e.g.
+	// "text/csv". An empty one is sent as "application/octet-stream".
+	BinaryContentType() string
+	// BinaryData returns the payload.
+	BinaryData() []byte
+	// BinaryDisposition returns the Content-Disposition header, e.g.
+	// `attachment; filename="report.pdf"`, or "" to send none.
+	BinaryDisposition() string
+}
+
+// writeBinary writes a BinaryResource response with the given status, or
+// 304 Not Modified for a GET whose ETag matches.
+func (this *ServiceHandler) writeBinary(w http.ResponseWriter, r *http.Request, status int, binary BinaryResource) {
+	data := binary.BinaryData()
+	contentType := binary.BinaryContentType()
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", contentType)
+	if disposition := binary.BinaryDisposition(); disposition != "" {
+		w.Header().Set("Content-Disposition", disposition)
+	}
+	if this.etags != nil && r.Method == http.MethodGet && this.etags.notModified(w, r, data) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.WriteHeader(status)
+	w.Write(data)
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8types/go/types/l8api"
+)
+
+// csvResult is a response element exporting its query text as CSV.
+type csvResult struct {
+	*l8api.L8Query
+}
+
+func (this *csvResult) BinaryContentType() string { return "text/csv" }
+func (this *csvResult) BinaryData() []byte        { return []byte("text\n" + this.Text + "\n") }
+func (this *csvResult) BinaryDisposition() string { return `attachment; filename="export.csv"` }
+
+// csvElements answers with a csvResult for the text "export", and with the
+// query itself otherwise.
+type csvElements struct {
+	echoElements
+}
+
+func (this *csvElements) Element() interface{} {
+	if this.query.Text == "export" {
+		return &csvResult{L8Query: this.query}
+	}
+	return this.query
+}
+
+type csvVnic struct {
+	echoVnic
+}
+
+func (this *csvVnic) LeaderRequest(serviceName string, serviceArea byte, action ifs.Action, body interface{}, timeout int, tokens ...string) ifs.IElements {
+	return &csvElements{echoElements{query: body.(*l8api.L8Query)}}
+}
+
+func TestBinary_Response(t *testing.T) {
+	handler := &ServiceHandler{serviceName: "Tests", webService: &echoService{}, vnic: &csvVnic{}, etags: newETagCache()}
+	get := func(text, etag string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/0/Tests", strings.NewReader(`{"text":"`+text+`"}`))
+		if etag != "" {
+			r.Header.Set("If-None-Match", etag)
+		}
+		w := httptest.NewRecorder()
+		handler.serveHttp(w, r)
+		return w
+	}
+
+	w := get("export", "")
+	if w.Code != http.StatusOK || w.Body.String() != "text\nexport\n" {
+		t.Fatalf("expected the CSV payload, got %d %q", w.Code, w.Body.String())
+	}
+	if w.Header().Get("Content-Type") != "text/csv" || w.Header().Get("Content-Disposition") != `attachment; filename="export.csv"` || w.Header().Get("Content-Length") != "12" {
+		t.Fatalf("unexpected headers %v", w.Header())
+	}
+	if w = get("export", w.Header().Get("ETag")); w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Fatalf("expected 304 for a matching ETag, got %d", w.Code)
+	}
+
+	w = get("select * from a", "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"select * from a"`) || w.Header().Get("Content-Disposition") != "" {
+		t.Fatalf("expected a JSON response, got %d %s", w.Code, w.Body.String())
+	}
+}
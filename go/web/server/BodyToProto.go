@@ -22,8 +22,10 @@ package server
 import (
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"reflect"
+	"strings"
 
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
@@ -38,14 +40,17 @@ import (
 //   - method: HTTP method name for error messages
 //   - body: Target Protocol Buffer message to unmarshal into
 //
-// Returns true if parsing succeeded, false if an error occurred (error already written to response).
+// Returns true if parsing succeeded, false if an error occurred (an ErrorResponse is already written).
 // Empty request bodies are allowed and will leave the body message in its zero state.
+// Requests with a Content-Type other than application/json are rejected with 415.
 func bodyToProto(w http.ResponseWriter, r *http.Request, method string, body proto.Message) bool {
+	if !isJSONContentType(r) {
+		writeError(w, http.StatusUnsupportedMediaType, "Unsupported Content-Type "+r.Header.Get("Content-Type")+", expected "+JSONContentType)
+		return false
+	}
 	data, err := io.ReadAll(r.Body)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte("Failed to read body for method " + method + "\n"))
-		w.Write([]byte(err.Error()))
+		writeError(w, http.StatusBadRequest, "Failed to read body for method "+method+": "+err.Error())
 		fmt.Println("Failed to read body for method " + method + "\n")
 		return false
 	}
@@ -53,10 +58,7 @@ func bodyToProto(w http.ResponseWriter, r *http.Request, method string, body pro
 	if data != nil && len(data) > 0 {
 		err = protojson.Unmarshal(data, body)
 		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			w.Write([]byte("Failed to unmarshal body for method " + method + " element " + reflect.ValueOf(body).Elem().Type().Name() + "\n"))
-			w.Write([]byte("body for method " + method + string(data) + "\n"))
-			w.Write([]byte(err.Error()))
+			writeError(w, http.StatusBadRequest, "Failed to unmarshal body for method "+method+" element "+reflect.ValueOf(body).Elem().Type().Name()+": "+err.Error())
 			fmt.Println("Failed to unmarshal body for method " + method + " element " + reflect.ValueOf(body).Elem().Type().Name() + "\n")
 			fmt.Println("body for method " + method + string(data) + "\n")
 			return false
@@ -64,3 +66,30 @@ func bodyToProto(w http.ResponseWriter, r *http.Request, method string, body pro
 	}
 	return true
 }
+
+// JSONContentType is the media type accepted for Protocol Buffer JSON bodies.
+const JSONContentType = "application/json"
+
+// isJSONContentType reports whether the request's Content-Type is
+// application/json (parameters such as charset are ignored) or absent.
+func isJSONContentType(r *http.Request) bool {
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == JSONContentType
+}
+
+// allowMethod reports whether the request uses one of methods. Otherwise it
+// writes 405 Method Not Allowed with an Allow header listing them.
+func allowMethod(w http.ResponseWriter, r *http.Request, methods ...string) bool {
+	for _, method := range methods {
+		if r.Method == method {
+			return true
+		}
+	}
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	writeError(w, http.StatusMethodNotAllowed, "method "+r.Method+" not allowed")
+	return false
+}
@@ -0,0 +1,52 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/saichler/l8types/go/types/l8api"
+)
+
+func TestBodyToProto_ContentType(t *testing.T) {
+	for _, contentType := range []string{"", "application/json", "application/json; charset=utf-8"} {
+		r := httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(`{"user":"admin"}`))
+		if contentType != "" {
+			r.Header.Set("Content-Type", contentType)
+		}
+		body := &l8api.AuthUser{}
+		if !bodyToProto(httptest.NewRecorder(), r, "POST", body) || body.User != "admin" {
+			t.Fatalf("expected %q to be accepted", contentType)
+		}
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/register", strings.NewReader("user=admin"))
+	r.Header.Set("Content-Type", "text/plain")
+	w := httptest.NewRecorder()
+	if bodyToProto(w, r, "POST", &l8api.AuthUser{}) {
+		t.Fatal("expected text/plain to be rejected")
+	}
+	decodeError(t, w, http.StatusUnsupportedMediaType)
+
+	r = httptest.NewRequest(http.MethodPost, "/0/Tests", strings.NewReader("<xml/>"))
+	r.Header.Set("Content-Type", "application/xml")
+	w = httptest.NewRecorder()
+	(&ServiceHandler{serviceName: "Tests"}).serveHttp(w, r)
+	decodeError(t, w, http.StatusUnsupportedMediaType)
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// CORS.go answers cross-origin requests to the built-in endpoints, so an SPA
+// hosted on a different origin than the API can log in and register.
+//
+// Origins listed in RestServerConfig.AllowedOrigins receive the CORS response
+// headers, with credentials allowed so the bearer cookie is sent. A "*" entry
+// lets any other origin call the endpoints with "Access-Control-Allow-Origin: *"
+// but never with credentials, so a foreign site can't act with the user's
+// cookie. Preflight OPTIONS requests are answered with 204 for every origin,
+// but without the allow headers the browser blocks origins that are not listed.
+
+package server
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// corsMaxAge is how long, in seconds, browsers may cache a preflight response.
+const corsMaxAge = 600
+
+// corsOrigin returns the request's Origin if it is in origins, "*" if it is
+// only allowed by a "*" entry, or "" otherwise.
+func corsOrigin(r *http.Request, origins []string) string {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return ""
+	}
+	wildcard := false
+	for _, allowed := range origins {
+		if allowed == origin {
+			return origin
+		}
+		wildcard = wildcard || allowed == "*"
+	}
+	if wildcard {
+		return "*"
+	}
+	return ""
+}
+
+// withPreflight wraps a built-in endpoint handler that accepts the given
+// methods, adding CORS headers for the server's AllowedOrigins and answering
+// OPTIONS preflight requests itself.
+func withPreflight(origins []string, methods string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		origin := corsOrigin(r, origins)
+		if origin != "" {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+		}
+		if origin != "" && origin != "*" {
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+		w.Header().Add("Vary", "Origin")
+		if r.Method != http.MethodOptions {
+			handler(w, r)
+			return
+		}
+		w.Header().Set("Allow", methods+", "+http.MethodOptions)
+		if origin != "" {
+			w.Header().Set("Access-Control-Allow-Methods", methods+", "+http.MethodOptions)
+			headers := r.Header.Get("Access-Control-Request-Headers")
+			if headers == "" {
+				headers = "Authorization, Content-Type"
+			}
+			w.Header().Set("Access-Control-Allow-Headers", headers)
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(corsMaxAge))
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
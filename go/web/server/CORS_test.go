@@ -0,0 +1,85 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCORS_Preflight(t *testing.T) {
+	called := false
+	handler := withPreflight([]string{"https://app.example.com"}, http.MethodPost, func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusOK)
+	})
+
+	r := httptest.NewRequest(http.MethodOptions, "/auth", nil)
+	r.Header.Set("Origin", "https://app.example.com")
+	r.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	r.Header.Set("Access-Control-Request-Headers", "content-type")
+	w := httptest.NewRecorder()
+	handler(w, r)
+	if called || w.Code != http.StatusNoContent {
+		t.Fatalf("expected preflight to be answered with 204, got %d", w.Code)
+	}
+	if w.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" ||
+		w.Header().Get("Access-Control-Allow-Methods") != "POST, OPTIONS" ||
+		w.Header().Get("Access-Control-Allow-Headers") != "content-type" ||
+		w.Header().Get("Access-Control-Allow-Credentials") != "true" {
+		t.Fatalf("unexpected preflight headers %v", w.Header())
+	}
+
+	r = httptest.NewRequest(http.MethodOptions, "/auth", nil)
+	r.Header.Set("Origin", "https://evil.example.com")
+	w = httptest.NewRecorder()
+	handler(w, r)
+	if w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Fatal("unlisted origin must not be allowed")
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/auth", nil)
+	r.Header.Set("Origin", "https://app.example.com")
+	w = httptest.NewRecorder()
+	handler(w, r)
+	if !called || w.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" {
+		t.Fatal("expected the handler to run with CORS headers")
+	}
+}
+
+func TestCORS_WildcardWithoutCredentials(t *testing.T) {
+	handler := withPreflight([]string{"*", "https://app.example.com"}, http.MethodPost, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	for _, method := range []string{http.MethodOptions, http.MethodPost} {
+		r := httptest.NewRequest(method, "/auth", nil)
+		r.Header.Set("Origin", "https://evil.example.com")
+		w := httptest.NewRecorder()
+		handler(w, r)
+		if w.Header().Get("Access-Control-Allow-Origin") != "*" || w.Header().Get("Access-Control-Allow-Credentials") != "" {
+			t.Fatalf("%s: expected \"*\" without credentials, got %v", method, w.Header())
+		}
+
+		// An explicitly listed origin still gets credentials.
+		r.Header.Set("Origin", "https://app.example.com")
+		w = httptest.NewRecorder()
+		handler(w, r)
+		if w.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" || w.Header().Get("Access-Control-Allow-Credentials") != "true" {
+			t.Fatalf("%s: expected credentials for a listed origin, got %v", method, w.Header())
+		}
+	}
+}
@@ -0,0 +1,76 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCSRF_Check(t *testing.T) {
+	w := httptest.NewRecorder()
+	setCSRFCookie(w)
+	csrf := w.Result().Cookies()[0]
+	if csrf.Name != CSRFCookieName || csrf.HttpOnly || len(csrf.Value) != 64 {
+		t.Fatalf("expected a script readable CSRF cookie, got %+v", csrf)
+	}
+
+	request := func(method, header string, bearerCookie, authorization bool) *http.Request {
+		r := httptest.NewRequest(method, "/", nil)
+		r.AddCookie(csrf)
+		if bearerCookie {
+			r.AddCookie(&http.Cookie{Name: DefaultBearerCookieName, Value: "alice"})
+		}
+		if authorization {
+			r.Header.Set("Authorization", "Bearer alice")
+		}
+		if header != "" {
+			r.Header.Set(CSRFHeader, header)
+		}
+		return r
+	}
+	for _, test := range []struct {
+		name string
+		r    *http.Request
+		err  error
+	}{
+		{"safe method", request(http.MethodGet, "", true, false), nil},
+		{"matching header", request(http.MethodPost, csrf.Value, true, false), nil},
+		{"missing header", request(http.MethodPost, "", true, false), ErrCSRFToken},
+		{"wrong header", request(http.MethodDelete, "forged", true, false), ErrCSRFToken},
+		{"authorization header", request(http.MethodPut, "", true, true), nil},
+		{"no bearer cookie", request(http.MethodPost, "", false, false), nil},
+	} {
+		if err := checkCSRF(test.r, DefaultBearerCookieName); err != test.err {
+			t.Errorf("%s: expected %v, got %v", test.name, test.err, err)
+		}
+	}
+}
+
+func TestWebService_CSRF(t *testing.T) {
+	vnic := &securityVnic{resources: &securityResources{security: &tokenSecurity{}}}
+	protected := &WebService{vnic: vnic, server: &RestServer{RestServerConfig: RestServerConfig{EnableCSRF: true}}}
+	open := &WebService{vnic: vnic, server: &RestServer{}}
+
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	r.AddCookie(&http.Cookie{Name: DefaultBearerCookieName, Value: "alice"})
+	if err := protected.ValidateBearerToken(r); err != ErrCSRFToken {
+		t.Fatalf("expected ErrCSRFToken, got %v", err)
+	}
+	if err := open.ValidateBearerToken(r); err != nil {
+		t.Fatalf("expected no CSRF check when disabled, got %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Certificates.go builds the server's TLS configuration from the certificate
+// sources in RestServerConfig. Certificates loaded from files are reloaded
+// when the files change, so certs mounted in Kubernetes and rotated by a
+// cert-manager sidecar are picked up without a restart.
+
+package server
+
+import (
+	"crypto/tls"
+	"os"
+	"sync"
+	"time"
+)
+
+// tlsConfig returns the TLS configuration for the configured certificate
+// source, in order of precedence: GetCertificate, Certificate,
+// CertFile/KeyFile, then the CertDomain/CertPrivate PEM strings.
+func (this *RestServer) tlsConfig() (*tls.Config, error) {
+	if this.GetCertificate != nil {
+		return &tls.Config{GetCertificate: this.GetCertificate}, nil
+	}
+	if this.Certificate != nil {
+		return &tls.Config{Certificates: []tls.Certificate{*this.Certificate}}, nil
+	}
+	if this.CertFile != "" && this.KeyFile != "" {
+		loader := &certFileLoader{certFile: this.CertFile, keyFile: this.KeyFile}
+		_, err := loader.load()
+		if err != nil {
+			return nil, err
+		}
+		return &tls.Config{GetCertificate: loader.getCertificate}, nil
+	}
+	cert, err := tls.X509KeyPair([]byte(this.CertDomain), []byte(this.CertPrivate))
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{Certificates: []tls.Certificate{cert}}, nil
+}
+
+// certFileLoader serves a certificate from PEM files, reloading it when either
+// file's modification time changes.
+type certFileLoader struct {
+	certFile string
+	keyFile  string
+	mtx      sync.Mutex
+	cert     *tls.Certificate
+	certMod  time.Time
+	keyMod   time.Time
+}
+
+// getCertificate implements tls.Config.GetCertificate. If a reload fails, for
+// example while the files are being replaced, the last good certificate is kept.
+func (this *certFileLoader) getCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cert, err := this.load()
+	if err != nil {
+		this.mtx.Lock()
+		defer this.mtx.Unlock()
+		if this.cert != nil {
+			return this.cert, nil
+		}
+		return nil, err
+	}
+	return cert, nil
+}
+
+// load returns the cached certificate, reading the files again if they have
+// changed since the last load.
+func (this *certFileLoader) load() (*tls.Certificate, error) {
+	certInfo, err := os.Stat(this.certFile)
+	if err != nil {
+		return nil, err
+	}
+	keyInfo, err := os.Stat(this.keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	if this.cert != nil && certInfo.ModTime().Equal(this.certMod) && keyInfo.ModTime().Equal(this.keyMod) {
+		return this.cert, nil
+	}
+	cert, err := tls.LoadX509KeyPair(this.certFile, this.keyFile)
+	if err != nil {
+		return nil, err
+	}
+	this.cert = &cert
+	this.certMod = certInfo.ModTime()
+	this.keyMod = keyInfo.ModTime()
+	return this.cert, nil
+}
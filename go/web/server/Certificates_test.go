@@ -0,0 +1,174 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeTestCert writes a self-signed certificate and key for commonName to
+// certFile and keyFile, with the given modification time.
+func writeTestCert(t *testing.T, certFile, keyFile, commonName string, mod time.Time) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPem := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+	if err = os.WriteFile(certFile, certPem, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(keyFile, keyPem, 0600); err != nil {
+		t.Fatal(err)
+	}
+	os.Chtimes(certFile, mod, mod)
+	os.Chtimes(keyFile, mod, mod)
+}
+
+func commonNameOf(t *testing.T, cert *tls.Certificate) string {
+	parsed, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed.Subject.CommonName
+}
+
+func TestCertificates_FileReload(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	now := time.Now()
+	writeTestCert(t, certFile, keyFile, "first", now.Add(-time.Minute))
+
+	rs := &RestServer{RestServerConfig: RestServerConfig{CertFile: certFile, KeyFile: keyFile}}
+	tlsConfig, err := rs.tlsConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := tlsConfig.GetCertificate(&tls.ClientHelloInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name := commonNameOf(t, cert); name != "first" {
+		t.Fatalf("expected first certificate, got %s", name)
+	}
+
+	writeTestCert(t, certFile, keyFile, "rotated", now)
+	cert, err = tlsConfig.GetCertificate(&tls.ClientHelloInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name := commonNameOf(t, cert); name != "rotated" {
+		t.Fatalf("expected rotated certificate, got %s", name)
+	}
+
+	// A half-written key must not drop the last good certificate.
+	os.WriteFile(keyFile, []byte("partial"), 0600)
+	os.Chtimes(keyFile, now.Add(time.Minute), now.Add(time.Minute))
+	cert, err = tlsConfig.GetCertificate(&tls.ClientHelloInfo{})
+	if err != nil || commonNameOf(t, cert) != "rotated" {
+		t.Fatalf("expected last good certificate to be kept, got err %v", err)
+	}
+}
+
+func TestCertificates_Sources(t *testing.T) {
+	_, err := NewRestServer(&RestServerConfig{Host: "127.0.0.1", Port: 0})
+	if err == nil {
+		t.Fatal("expected an error when no certificate source is configured")
+	}
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	writeTestCert(t, certFile, keyFile, "memory", time.Now())
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rs := &RestServer{RestServerConfig: RestServerConfig{Certificate: &cert}}
+	tlsConfig, err := rs.tlsConfig()
+	if err != nil || len(tlsConfig.Certificates) != 1 {
+		t.Fatalf("expected the in-memory certificate, got %v", err)
+	}
+
+	called := false
+	rs.GetCertificate = func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+		called = true
+		return &cert, nil
+	}
+	tlsConfig, err = rs.tlsConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tlsConfig.GetCertificate(&tls.ClientHelloInfo{})
+	if !called || len(tlsConfig.Certificates) != 0 {
+		t.Fatal("expected GetCertificate to take precedence")
+	}
+}
+
+func TestCertificates_NoPlainHTTPFallback(t *testing.T) {
+	rs := &RestServer{RestServerConfig: RestServerConfig{
+		Host:        "127.0.0.1",
+		CertDomain:  "not a certificate",
+		CertPrivate: "not a key",
+	}}
+	if err := rs.Start(); err == nil {
+		t.Fatal("expected Start to fail on an invalid certificate")
+	}
+	if rs.webServer != nil {
+		t.Fatal("server must not be configured after a certificate failure")
+	}
+}
+
+func TestCertificates_MissingCertFile(t *testing.T) {
+	dir := t.TempDir()
+	rs := &RestServer{RestServerConfig: RestServerConfig{
+		Host:     "127.0.0.1",
+		CertFile: filepath.Join(dir, "tls.crt"),
+		KeyFile:  filepath.Join(dir, "tls.key"),
+	}}
+	err := rs.Start()
+	if err == nil || !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected a not-exist error for a missing CertFile, got %v", err)
+	}
+}
@@ -17,7 +17,8 @@
 // It supports multiple methods of providing authentication tokens:
 // 1. HTTP-only cookies (primary method for browser security)
 // 2. Authorization header with Bearer scheme (for API clients)
-// 3. Query parameter fallback (for initial page load redirects)
+// 3. Query parameter fallback (for initial page load redirects), off unless
+// RestServerConfig.AllowQueryToken is set
 
 package server
 
@@ -26,20 +27,31 @@ import (
 	"strings"
 )
 
-// BearerCookieName is the name of the HTTP-only cookie used to store
-// bearer tokens for browser-based authentication.
-var BearerCookieName = "bToken"
+// DefaultBearerCookieName is the name of the HTTP-only cookie used to store
+// bearer tokens for browser-based authentication, unless
+// RestServerConfig.BearerCookieName sets another one.
+const DefaultBearerCookieName = "bToken"
 
 // extractToken attempts to extract an authentication token from an HTTP request.
 // It checks multiple sources in priority order:
-// 1. Cookie named "bToken" (primary method for browser security with HttpOnly flag)
+// 1. Cookie named cookieName, DefaultBearerCookieName if empty (primary method
+// for browser security with HttpOnly flag)
 // 2. Authorization header with "Bearer" scheme (for API clients)
-// 3. Query parameter named "token" (fallback for redirects)
+// 3. Query parameter named "token" (fallback for redirects), only if allowQuery
+//
+// A token in the URL is copied into server and proxy access logs, the browser
+// history and the Referer header of requests the page makes, where anyone who
+// reads them can replay it. It is therefore only accepted when the server
+// opts in for a redirect flow that needs it.
 //
 // Returns an empty string if no token is found in any location.
-func extractToken(r *http.Request) string {
+func extractToken(r *http.Request, cookieName string, allowQuery bool) string {
+	if cookieName == "" {
+		cookieName = DefaultBearerCookieName
+	}
+
 	// 1. Try cookie first (primary method for browser requests)
-	cookie, err := r.Cookie(BearerCookieName)
+	cookie, err := r.Cookie(cookieName)
 	if err == nil && cookie.Value != "" {
 		return cookie.Value
 	}
@@ -54,9 +66,11 @@ func extractToken(r *http.Request) string {
 	}
 
 	// 3. Fallback to query parameter (for initial page load redirect)
-	token := r.URL.Query().Get("token")
-	if token != "" {
-		return token
+	if allowQuery {
+		token := r.URL.Query().Get("token")
+		if token != "" {
+			return token
+		}
 	}
 
 	return ""
@@ -0,0 +1,66 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestExtractToken_CookieName(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(&http.Cookie{Name: DefaultBearerCookieName, Value: "default"})
+	r.AddCookie(&http.Cookie{Name: "appToken", Value: "app"})
+
+	if token := extractToken(r, "", false); token != "default" {
+		t.Fatalf("expected the default cookie, got %q", token)
+	}
+	if token := extractToken(r, "appToken", false); token != "app" {
+		t.Fatalf("expected the named cookie, got %q", token)
+	}
+	if token := extractToken(r, "otherToken", false); token != "" {
+		t.Fatalf("expected no token, got %q", token)
+	}
+}
+
+func TestWebService_BearerCookieName(t *testing.T) {
+	vnic := &securityVnic{resources: &securityResources{security: &tokenSecurity{}}}
+	app := &WebService{vnic: vnic, server: &RestServer{RestServerConfig: RestServerConfig{BearerCookieName: "appToken"}}}
+	other := &WebService{vnic: vnic, server: &RestServer{}}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(&http.Cookie{Name: "appToken", Value: "alice"})
+	if err := app.ValidateBearerToken(r); err != nil {
+		t.Fatalf("expected the app cookie to authenticate, got %v", err)
+	}
+	if err := other.ValidateBearerToken(r); err == nil {
+		t.Fatal("expected a server with the default cookie name to ignore the app cookie")
+	}
+}
+
+func TestExtractToken_QueryParameter(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/?token=leaked", nil)
+	if token := extractToken(r, "", false); token != "" {
+		t.Fatalf("expected the query parameter to be ignored by default, got %q", token)
+	}
+	if token := extractToken(r, "", true); token != "leaked" {
+		t.Fatalf("expected the query parameter when allowed, got %q", token)
+	}
+	r.Header.Set("Authorization", "Bearer header")
+	if token := extractToken(r, "", true); token != "header" {
+		t.Fatalf("expected the Authorization header before the query parameter, got %q", token)
+	}
+}
@@ -0,0 +1,59 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Created.go lets a service report that a write created its resource, so the
+// handler can answer 201 Created instead of 200 OK, e.g. for a PUT upsert.
+//
+// The VNic response carries only the service's elements, so the indicator is
+// an optional interface on the response element. A generated Protocol Buffer
+// type gets it by declaring the two methods in a file of its own package.
+
+package server
+
+import (
+	"net/http"
+
+	"github.com/saichler/l8types/go/ifs"
+)
+
+// CreatedResource is implemented by a service response element that reports
+// whether a POST, PUT or PATCH created the resource rather than updating an
+// existing one.
+type CreatedResource interface {
+	// Created reports whether the request created the resource.
+	Created() bool
+	// Location returns the URL path of the created resource, sent as the
+	// Location header, or "" to send none.
+	Location() string
+}
+
+// createdStatus returns the success status for a request: 201 Created, with the
+// Location header set, if the response element reports a created resource for
+// a POST, PUT or PATCH, and 200 OK otherwise.
+func createdStatus(w http.ResponseWriter, r *http.Request, elems ifs.IElements) int {
+	switch r.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+	default:
+		return http.StatusOK
+	}
+	created, ok := elems.Element().(CreatedResource)
+	if !ok || !created.Created() {
+		return http.StatusOK
+	}
+	if location := created.Location(); location != "" {
+		w.Header().Set("Location", location)
+	}
+	return http.StatusCreated
+}
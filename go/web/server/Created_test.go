@@ -0,0 +1,79 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8types/go/types/l8api"
+)
+
+// upsertResult is a response element that reports whether it was created.
+type upsertResult struct {
+	*l8api.L8Query
+	created bool
+}
+
+func (this *upsertResult) Created() bool    { return this.created }
+func (this *upsertResult) Location() string { return "/0/Tests/" + this.Text }
+
+// upsertElements answers with an upsertResult created for the text "new".
+type upsertElements struct {
+	ifs.IElements
+	result *upsertResult
+}
+
+func (this *upsertElements) Error() error         { return nil }
+func (this *upsertElements) Element() interface{} { return this.result }
+func (this *upsertElements) AsList(ifs.IRegistry) (interface{}, error) {
+	return this.result.L8Query, nil
+}
+
+type upsertVnic struct {
+	echoVnic
+}
+
+func (this *upsertVnic) LeaderRequest(serviceName string, serviceArea byte, action ifs.Action, body interface{}, timeout int, tokens ...string) ifs.IElements {
+	query := body.(*l8api.L8Query)
+	return &upsertElements{result: &upsertResult{L8Query: query, created: query.Text == "new"}}
+}
+
+func TestCreated_Upsert(t *testing.T) {
+	handler := &ServiceHandler{serviceName: "Tests", webService: &echoService{}, vnic: &upsertVnic{}}
+	for _, test := range []struct {
+		method, text, location string
+		status                 int
+	}{
+		{http.MethodPut, "new", "/0/Tests/new", http.StatusCreated},
+		{http.MethodPost, "new", "/0/Tests/new", http.StatusCreated},
+		{http.MethodPut, "old", "", http.StatusOK},
+		{http.MethodGet, "new", "", http.StatusOK},
+	} {
+		r := httptest.NewRequest(test.method, "/0/Tests", strings.NewReader(`{"text":"`+test.text+`"}`))
+		w := httptest.NewRecorder()
+		handler.serveHttp(w, r)
+		if w.Code != test.status || w.Header().Get("Location") != test.location {
+			t.Fatalf("%s %s: expected %d %q, got %d %q", test.method, test.text, test.status, test.location, w.Code, w.Header().Get("Location"))
+		}
+		if !strings.Contains(w.Body.String(), test.text) {
+			t.Fatalf("%s %s: expected the element in the body, got %s", test.method, test.text, w.Body.String())
+		}
+	}
+}
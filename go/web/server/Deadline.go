@@ -0,0 +1,101 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Deadline.go derives the VNic request timeout from the HTTP request, so the
+// backend round trip never outlives the client that asked for it, and bounds
+// the time any handler may take with RestServerConfig.HandlerTimeout.
+
+package server
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimeoutHeader is the header a client may use to ask for a VNic request timeout
+// in seconds, for example when its own deadline is longer than Timeout.
+var TimeoutHeader = "X-Timeout"
+
+// MaxTimeout caps the VNic request timeout in seconds, whatever the client asks
+// for through TimeoutHeader. Zero or less disables the cap.
+var MaxTimeout = 300
+
+// requestTimeout returns the VNic request timeout in seconds for r. It starts from
+// Timeout, or a positive TimeoutHeader value, is lowered to the time left before the
+// request context's deadline if there is one and is capped at MaxTimeout. It never
+// returns less than one second; a deadline that already passed is reported by
+// r.Context().Err().
+func requestTimeout(r *http.Request) int {
+	timeout := Timeout
+	if hint, err := strconv.Atoi(r.Header.Get(TimeoutHeader)); err == nil && hint > 0 {
+		timeout = hint
+	}
+	if deadline, ok := r.Context().Deadline(); ok {
+		left := int(math.Ceil(time.Until(deadline).Seconds()))
+		if left < timeout {
+			timeout = left
+		}
+	}
+	if MaxTimeout > 0 && timeout > MaxTimeout {
+		timeout = MaxTimeout
+	}
+	if timeout < 1 {
+		timeout = 1
+	}
+	return timeout
+}
+
+// withHandlerTimeout wraps next with http.TimeoutHandler when HandlerTimeout is
+// set, so a handler that hangs, e.g. blocked writing its response, answers 503
+// with an ErrorResponse and frees the connection. The handler's request
+// context gets the same deadline, which also lowers the VNic request timeout.
+// WebSocket upgrades are not wrapped, their connection outlives any timeout.
+func (this *RestServer) withHandlerTimeout(next http.Handler) http.Handler {
+	if this.HandlerTimeout <= 0 {
+		return next
+	}
+	message := "Request exceeded the server timeout of " + this.HandlerTimeout.String()
+	body, _ := json.Marshal(&ErrorResponse{Error: &ErrorDetail{Code: http.StatusServiceUnavailable, Message: message}})
+	timeoutHandler := http.TimeoutHandler(next, this.HandlerTimeout, string(body))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		timeoutHandler.ServeHTTP(&timeoutErrorWriter{ResponseWriter: w}, r)
+	})
+}
+
+// timeoutErrorWriter labels the ErrorResponse http.TimeoutHandler writes on a
+// timeout as JSON; the handler's own headers are discarded then.
+type timeoutErrorWriter struct {
+	http.ResponseWriter
+}
+
+func (this *timeoutErrorWriter) WriteHeader(status int) {
+	if status == http.StatusServiceUnavailable && this.Header().Get("Content-Type") == "" {
+		this.Header().Set("Content-Type", "application/json")
+	}
+	this.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap lets http.ResponseController reach the wrapped writer.
+func (this *timeoutErrorWriter) Unwrap() http.ResponseWriter {
+	return this.ResponseWriter
+}
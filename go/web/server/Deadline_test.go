@@ -0,0 +1,163 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8types/go/types/l8api"
+	"google.golang.org/protobuf/proto"
+)
+
+// queryService is an ifs.IWebService whose bodies are all L8Query.
+type queryService struct {
+	ifs.IWebService
+}
+
+func (this *queryService) Protos(string, ifs.Action) (proto.Message, proto.Message, error) {
+	return &l8api.L8Query{}, nil, nil
+}
+
+// quietLogger is an ifs.ILogger that drops everything.
+type quietLogger struct {
+	ifs.ILogger
+}
+
+func (this *quietLogger) Debug(...interface{})   {}
+func (this *quietLogger) Info(...interface{})    {}
+func (this *quietLogger) Warning(...interface{}) {}
+
+type quietResources struct {
+	ifs.IResources
+}
+
+func (this *quietResources) Logger() ifs.ILogger       { return &quietLogger{} }
+func (this *quietResources) SysConfig() *ifs.SysConfig { return &ifs.SysConfig{} }
+func (this *quietResources) Registry() ifs.IRegistry   { return nil }
+
+// blockingVnic is an ifs.IVNic whose leader requests block until release is
+// closed. The timeout each request was sent with is passed to timeouts.
+type blockingVnic struct {
+	ifs.IVNic
+	timeouts chan int
+	release  chan struct{}
+}
+
+func (this *blockingVnic) Resources() ifs.IResources {
+	return &quietResources{}
+}
+
+func (this *blockingVnic) Running() bool {
+	return true
+}
+
+func (this *blockingVnic) LeaderRequest(serviceName string, serviceArea byte, action ifs.Action, body interface{}, timeout int, tokens ...string) ifs.IElements {
+	this.timeouts <- timeout
+	<-this.release
+	return nil
+}
+
+func TestDeadline_RequestTimeout(t *testing.T) {
+	defer func(timeout, max int) { Timeout, MaxTimeout = timeout, max }(Timeout, MaxTimeout)
+	Timeout, MaxTimeout = 30, 120
+
+	r := httptest.NewRequest(http.MethodGet, "/0/Tests", nil)
+	if got := requestTimeout(r); got != 30 {
+		t.Fatalf("expected the default timeout, got %d", got)
+	}
+	r.Header.Set(TimeoutHeader, "60")
+	if got := requestTimeout(r); got != 60 {
+		t.Fatalf("expected the header timeout, got %d", got)
+	}
+	r.Header.Set(TimeoutHeader, "3600")
+	if got := requestTimeout(r); got != 120 {
+		t.Fatalf("expected the capped timeout, got %d", got)
+	}
+	r.Header.Set(TimeoutHeader, "-1")
+	if got := requestTimeout(r); got != 30 {
+		t.Fatalf("expected an invalid header to be ignored, got %d", got)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 4500*time.Millisecond)
+	defer cancel()
+	if got := requestTimeout(r.WithContext(ctx)); got != 5 {
+		t.Fatalf("expected the time left before the deadline, got %d", got)
+	}
+}
+
+func TestDeadline_Abandoned(t *testing.T) {
+	handler := &ServiceHandler{serviceName: "Tests", webService: &queryService{}}
+
+	// A cancelled request never reaches the (nil) vnic.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	w := httptest.NewRecorder()
+	handler.serveHttp(w, httptest.NewRequest(http.MethodGet, "/0/Tests", nil).WithContext(ctx))
+	if w.Body.Len() != 0 {
+		t.Fatalf("expected no response for a disconnected client, got %q", w.Body.String())
+	}
+
+	// A deadline that passes while the vnic is busy ends the request with 504.
+	vnic := &blockingVnic{timeouts: make(chan int, 1), release: make(chan struct{})}
+	defer close(vnic.release)
+	handler.vnic = vnic
+	ctx, cancel = context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	w = httptest.NewRecorder()
+	handler.serveHttp(w, httptest.NewRequest(http.MethodGet, "/0/Tests", nil).WithContext(ctx))
+	if timeout := <-vnic.timeouts; timeout != 1 {
+		t.Fatalf("expected a one second vnic timeout, got %d", timeout)
+	}
+	decodeError(t, w, http.StatusGatewayTimeout)
+}
+
+func TestDeadline_HandlerTimeout(t *testing.T) {
+	rs := &RestServer{}
+	rs.HandlerTimeout = 50 * time.Millisecond
+	handler := rs.withHandlerTimeout(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/hang" {
+			<-r.Context().Done()
+			return
+		}
+		_, deadline := r.Context().Deadline()
+		w.Write([]byte(strconv.FormatBool(deadline)))
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/hang", nil))
+	decodeError(t, w, http.StatusServiceUnavailable)
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fast", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "true" {
+		t.Fatalf("expected a fast handler to answer with a deadline, got %d %q", w.Code, w.Body.String())
+	}
+
+	// A WebSocket upgrade is never cut off.
+	r := httptest.NewRequest(http.MethodGet, "/ws", nil)
+	r.Header.Set("Upgrade", "websocket")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Body.String() != "false" {
+		t.Fatalf("expected the upgrade without a deadline, got %q", w.Body.String())
+	}
+}
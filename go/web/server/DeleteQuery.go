@@ -0,0 +1,104 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// DeleteQuery.go implements bulk deletes: a DELETE without a body whose URL
+// has query parameters, e.g. DELETE /0/Events?olderThan=30d, deletes every
+// element matching them. The parameters become the where clause of an
+// L8Query sent to the service, and the response reports how many elements
+// the service deleted.
+//
+// A bulk delete is not safely retryable: a relative filter matches different
+// elements on every attempt, and a retry after a timeout may repeat a delete
+// that already happened. RestClient therefore never retries a DELETE with
+// query parameters, and clients should check the result before retrying.
+
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8types/go/types/l8api"
+)
+
+// DeleteResult is the response of a bulk delete.
+type DeleteResult struct {
+	Deleted int `json:"deleted"` // Number of elements the service reported deleted
+}
+
+var (
+	// deleteFilterKey matches the attribute names accepted in a bulk delete filter.
+	deleteFilterKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+	// deleteFilterValue matches the values accepted in a bulk delete filter.
+	// Spaces, quotes and parentheses are refused so a value can't extend the
+	// query beyond a single comparison.
+	deleteFilterValue = regexp.MustCompile(`^[A-Za-z0-9_.:@*+-]+$`)
+)
+
+// isBulkDelete reports whether r, with the request body data, is a bulk delete.
+func isBulkDelete(r *http.Request, data []byte) bool {
+	return r.Method == http.MethodDelete && len(data) == 0 && r.URL.RawQuery != ""
+}
+
+// deleteQuery builds the L8Query of a bulk delete from the query parameters,
+// "select * from <element type> where k1=v1 and k2=v2", with the parameters
+// in name order. The element type is the service's POST body type.
+func (this *ServiceHandler) deleteQuery(params url.Values) (*l8api.L8Query, error) {
+	elem, _, err := this.webService.Protos("{}", ifs.POST)
+	if err != nil {
+		return nil, err
+	}
+	if elem == nil {
+		return nil, errors.New("service " + this.serviceName + " has no element type")
+	}
+
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	conditions := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if !deleteFilterKey.MatchString(key) {
+			return nil, errors.New("invalid filter attribute " + key)
+		}
+		for _, value := range params[key] {
+			if !deleteFilterValue.MatchString(value) {
+				return nil, errors.New("invalid value for " + key)
+			}
+			conditions = append(conditions, key+"="+value)
+		}
+	}
+	if len(conditions) == 0 {
+		return nil, errors.New("empty filter")
+	}
+	typeName := string(elem.ProtoReflect().Descriptor().Name())
+	return &l8api.L8Query{Text: "select * from " + typeName + " where " + strings.Join(conditions, " and ")}, nil
+}
+
+// writeDeleteResult answers a bulk delete with the number of elements the
+// service returned as deleted.
+func writeDeleteResult(w http.ResponseWriter, elems ifs.IElements) {
+	byt, _ := json.Marshal(&DeleteResult{Deleted: len(elems.Elements())})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(byt)
+}
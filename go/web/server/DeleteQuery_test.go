@@ -0,0 +1,71 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8types/go/types/l8api"
+)
+
+// deletedElements answers a bulk delete with count deleted elements.
+type deletedElements struct {
+	ifs.IElements
+	count int
+}
+
+func (this *deletedElements) Error() error         { return nil }
+func (this *deletedElements) Element() interface{} { return nil }
+func (this *deletedElements) Elements() []interface{} {
+	return make([]interface{}, this.count)
+}
+
+// bulkDeleteVnic records the query of each request and deletes three elements.
+type bulkDeleteVnic struct {
+	echoVnic
+	queries []string
+}
+
+func (this *bulkDeleteVnic) LeaderRequest(serviceName string, serviceArea byte, action ifs.Action, body interface{}, timeout int, tokens ...string) ifs.IElements {
+	this.queries = append(this.queries, body.(*l8api.L8Query).Text)
+	return &deletedElements{count: 3}
+}
+
+func TestDeleteQuery_BulkDelete(t *testing.T) {
+	vnic := &bulkDeleteVnic{}
+	handler := &ServiceHandler{serviceName: "Events", webService: &echoService{}, vnic: vnic}
+
+	w := httptest.NewRecorder()
+	handler.serveHttp(w, httptest.NewRequest(http.MethodDelete, "/0/Events?olderThan=30d&kind=audit", nil))
+	if w.Code != http.StatusOK || w.Body.String() != `{"deleted":3}` {
+		t.Fatalf("expected 3 deleted, got %d %s", w.Code, w.Body.String())
+	}
+	if len(vnic.queries) != 1 || vnic.queries[0] != "select * from L8Query where kind=audit and olderThan=30d" {
+		t.Fatalf("unexpected query %v", vnic.queries)
+	}
+
+	for _, query := range []string{"id=1%20or%20x=x", "id='1'", "a%20b=1"} {
+		w = httptest.NewRecorder()
+		handler.serveHttp(w, httptest.NewRequest(http.MethodDelete, "/0/Events?"+query, nil))
+		decodeError(t, w, http.StatusBadRequest)
+	}
+	if len(vnic.queries) != 1 {
+		t.Fatalf("expected invalid filters to be rejected before the request, got %v", vnic.queries)
+	}
+}
@@ -0,0 +1,106 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// ETag.go implements conditional GET support for service handlers. The ETag
+// is a hash of the serialized response, so it changes exactly when the
+// payload does. Since services don't report modification times, Last-Modified
+// is the time the current ETag was first served for the request URL.
+//
+// With RestServerConfig.EnableETags, a GET whose JSON or BinaryResource
+// response the client already has is answered with 304 Not Modified and no
+// body. Streamed responses carry no ETag.
+
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxETagEntries bounds the per-handler Last-Modified tracking. When exceeded,
+// the tracking is reset, which only makes If-Modified-Since less effective
+// until URLs are served again; If-None-Match is unaffected.
+const maxETagEntries = 10000
+
+// etagCache tracks, per request URL, the last ETag served and when it was first seen.
+type etagCache struct {
+	mtx     sync.Mutex
+	entries map[string]*etagEntry
+}
+
+type etagEntry struct {
+	etag     string
+	modified time.Time
+}
+
+func newETagCache() *etagCache {
+	return &etagCache{entries: make(map[string]*etagEntry)}
+}
+
+// notModified sets the ETag and Last-Modified headers for body and reports
+// whether the request's If-None-Match or, in its absence, If-Modified-Since
+// shows the client already has this version.
+func (this *etagCache) notModified(w http.ResponseWriter, r *http.Request, body []byte) bool {
+	sum := sha256.Sum256(body)
+	etag := "\"" + hex.EncodeToString(sum[:16]) + "\""
+	modified := this.lastModified(r.URL.RequestURI(), etag)
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return etagMatches(inm, etag)
+	}
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" {
+		since, err := http.ParseTime(ims)
+		if err == nil && !modified.Truncate(time.Second).After(since) {
+			return true
+		}
+	}
+	return false
+}
+
+// lastModified returns when etag was first served for uri, recording now if
+// the ETag is new or has changed.
+func (this *etagCache) lastModified(uri, etag string) time.Time {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	entry, ok := this.entries[uri]
+	if ok && entry.etag == etag {
+		return entry.modified
+	}
+	if len(this.entries) >= maxETagEntries {
+		this.entries = make(map[string]*etagEntry)
+	}
+	entry = &etagEntry{etag: etag, modified: time.Now()}
+	this.entries[uri] = entry
+	return entry.modified
+}
+
+// etagMatches checks an If-None-Match header value against etag, using the
+// weak comparison required for GET (W/ prefixes are ignored).
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestETag_IfNoneMatch(t *testing.T) {
+	cache := newETagCache()
+	body := []byte(`{"list":[1,2,3]}`)
+
+	w := httptest.NewRecorder()
+	if cache.notModified(w, httptest.NewRequest(http.MethodGet, "/0/Users", nil), body) {
+		t.Fatal("first request without validators must not be 304")
+	}
+	etag := w.Header().Get("ETag")
+	if etag == "" || w.Header().Get("Last-Modified") == "" {
+		t.Fatalf("expected ETag and Last-Modified headers, got %v", w.Header())
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/0/Users", nil)
+	r.Header.Set("If-None-Match", `"other", W/`+etag)
+	if !cache.notModified(httptest.NewRecorder(), r, body) {
+		t.Fatal("expected 304 for matching weak If-None-Match")
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/0/Users", nil)
+	r.Header.Set("If-None-Match", etag)
+	if cache.notModified(httptest.NewRecorder(), r, []byte(`{"list":[1,2]}`)) {
+		t.Fatal("changed body must not be 304")
+	}
+}
+
+func TestETag_IfModifiedSince(t *testing.T) {
+	cache := newETagCache()
+	body := []byte(`{"list":[1]}`)
+
+	w := httptest.NewRecorder()
+	cache.notModified(w, httptest.NewRequest(http.MethodGet, "/0/Users", nil), body)
+	lastModified := w.Header().Get("Last-Modified")
+
+	r := httptest.NewRequest(http.MethodGet, "/0/Users", nil)
+	r.Header.Set("If-Modified-Since", lastModified)
+	if !cache.notModified(httptest.NewRecorder(), r, body) {
+		t.Fatal("expected 304 for unchanged body since Last-Modified")
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/0/Users", nil)
+	r.Header.Set("If-Modified-Since", time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat))
+	if cache.notModified(httptest.NewRecorder(), r, []byte(`{"list":[2]}`)) {
+		t.Fatal("changed body must not be 304")
+	}
+}
@@ -0,0 +1,59 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Errors.go defines the JSON error envelope written by the server's endpoints,
+// so clients can parse every failure the same way:
+//
+//	{"error": {"code": 401, "message": "invalid token"}}
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ErrorResponse is the JSON envelope for error responses.
+type ErrorResponse struct {
+	Error *ErrorDetail `json:"error"`
+}
+
+// ErrorDetail describes a failed request.
+type ErrorDetail struct {
+	Code    int    `json:"code"`    // HTTP status code
+	Message string `json:"message"` // Human readable reason
+}
+
+// errorStatus returns the HTTP status for an error returned by the backend:
+// the one chosen by the server's ErrorMapper, or 400 Bad Request if there is
+// none or it returns a status that is not an error.
+func (this *ServiceHandler) errorStatus(err error) int {
+	if this.errorMapper == nil {
+		return http.StatusBadRequest
+	}
+	code := this.errorMapper(err)
+	if code < http.StatusBadRequest || code > 599 {
+		return http.StatusBadRequest
+	}
+	return code
+}
+
+// writeError writes an ErrorResponse with the given status code and message.
+func writeError(w http.ResponseWriter, code int, message string) {
+	byt, _ := json.Marshal(&ErrorResponse{Error: &ErrorDetail{Code: code, Message: message}})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(byt)
+}
@@ -0,0 +1,109 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func decodeError(t *testing.T, w *httptest.ResponseRecorder, code int) *ErrorDetail {
+	if w.Code != code {
+		t.Fatalf("expected status %d, got %d", code, w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("expected application/json, got %q", ct)
+	}
+	resp := &ErrorResponse{}
+	if err := json.Unmarshal(w.Body.Bytes(), resp); err != nil || resp.Error == nil {
+		t.Fatalf("expected an error envelope, got %s", w.Body.String())
+	}
+	if resp.Error.Code != code {
+		t.Fatalf("expected code %d in envelope, got %d", code, resp.Error.Code)
+	}
+	return resp.Error
+}
+
+func TestErrors_Envelope(t *testing.T) {
+	handler := &ServiceHandler{serviceName: "Tests", authEnabled: true}
+	w := httptest.NewRecorder()
+	handler.serveHttp(w, httptest.NewRequest(http.MethodGet, "/0/Tests", nil))
+	if e := decodeError(t, w, http.StatusUnauthorized); e.Message != "missing bearer token" {
+		t.Fatalf("unexpected message %q", e.Message)
+	}
+
+	w = httptest.NewRecorder()
+	(&WebService{}).Auth(w, httptest.NewRequest(http.MethodPost, "/auth", strings.NewReader("{")))
+	decodeError(t, w, http.StatusBadRequest)
+
+	w = httptest.NewRecorder()
+	(&WebService{}).TFASetup(w, httptest.NewRequest(http.MethodPost, "/tfaSetup", strings.NewReader("{")))
+	if e := decodeError(t, w, http.StatusBadRequest); !strings.Contains(e.Message, "L8TFASetup") {
+		t.Fatalf("expected the element name in the message, got %q", e.Message)
+	}
+}
+
+func TestErrors_MethodNotAllowed(t *testing.T) {
+	ws := &WebService{}
+	handlers := map[string]http.HandlerFunc{
+		"/auth":      ws.Auth,
+		"/register":  ws.Register,
+		"/tfaSetup":  ws.TFASetup,
+		"/tfaVerify": ws.TFAVerify,
+	}
+	for path, handler := range handlers {
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest(http.MethodGet, path, nil))
+		decodeError(t, w, http.StatusMethodNotAllowed)
+		if w.Header().Get("Allow") != http.MethodPost {
+			t.Fatalf("expected Allow: POST for %s, got %q", path, w.Header().Get("Allow"))
+		}
+	}
+
+	w := httptest.NewRecorder()
+	ws.Captcha(w, httptest.NewRequest(http.MethodDelete, "/captcha", nil))
+	decodeError(t, w, http.StatusMethodNotAllowed)
+	if w.Header().Get("Allow") != "GET, POST" {
+		t.Fatalf("expected Allow: GET, POST for /captcha, got %q", w.Header().Get("Allow"))
+	}
+}
+
+func TestErrors_ErrorMapper(t *testing.T) {
+	handler := &ServiceHandler{serviceName: "Tests", webService: &echoService{}, vnic: &echoVnic{}}
+	serve := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.serveHttp(w, httptest.NewRequest(http.MethodPost, "/0/Tests", strings.NewReader(`{"text":"fail"}`)))
+		return w
+	}
+
+	decodeError(t, serve(), http.StatusBadRequest)
+
+	handler.errorMapper = func(err error) int {
+		if strings.Contains(err.Error(), "failure") {
+			return http.StatusConflict
+		}
+		return 0
+	}
+	if e := decodeError(t, serve(), http.StatusConflict); !strings.Contains(e.Message, "backend failure") {
+		t.Fatalf("expected the backend error in the message, got %q", e.Message)
+	}
+
+	handler.errorMapper = func(error) int { return http.StatusOK }
+	decodeError(t, serve(), http.StatusBadRequest)
+}
@@ -0,0 +1,153 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Form.go maps application/x-www-form-urlencoded request bodies onto the
+// service's Protocol Buffer body, so HTML forms can post to a service directly.
+//
+// Mapping rules:
+//   - A form key names a field by its JSON name (e.g. "myString") or its proto
+//     name (e.g. "my_string").
+//   - Dotted keys address fields of nested messages (e.g. "address.city").
+//   - Repeated scalar fields take every value given for the key, in order;
+//     other fields take the last value.
+//   - Values are parsed by field kind: integers and floats in decimal, bools by
+//     strconv.ParseBool or "on" (an HTML checkbox), enums by value name or
+//     number, and bytes as the raw string.
+//   - Unknown keys, map fields and repeated message fields are rejected.
+
+package server
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// FormContentType is the media type of an HTML form submission.
+const FormContentType = "application/x-www-form-urlencoded"
+
+// isFormContentType reports whether the request body is an HTML form submission.
+func isFormContentType(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mediaType == FormContentType
+}
+
+// formToProto parses a form-urlencoded body and sets the fields it names on msg.
+func formToProto(data []byte, msg proto.Message) error {
+	values, err := url.ParseQuery(string(data))
+	if err != nil {
+		return err
+	}
+	for key, vals := range values {
+		err = setFormField(msg.ProtoReflect(), key, strings.Split(key, "."), vals)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setFormField sets the field at path on msg from the form values for key.
+func setFormField(msg protoreflect.Message, key string, path []string, vals []string) error {
+	fields := msg.Descriptor().Fields()
+	fd := fields.ByJSONName(path[0])
+	if fd == nil {
+		fd = fields.ByName(protoreflect.Name(path[0]))
+	}
+	if fd == nil {
+		return errors.New("unknown form field '" + key + "'")
+	}
+	if fd.IsMap() {
+		return errors.New("form field '" + key + "' is a map, which forms cannot set")
+	}
+
+	if len(path) > 1 {
+		if fd.Kind() != protoreflect.MessageKind || fd.IsList() {
+			return errors.New("form field '" + key + "' does not name a nested message field")
+		}
+		return setFormField(msg.Mutable(fd).Message(), key, path[1:], vals)
+	}
+
+	if fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind {
+		return errors.New("form field '" + key + "' is a message, set its fields with dotted keys")
+	}
+	if fd.IsList() {
+		list := msg.Mutable(fd).List()
+		for _, val := range vals {
+			v, err := formValue(fd, val)
+			if err != nil {
+				return errors.New("form field '" + key + "': " + err.Error())
+			}
+			list.Append(v)
+		}
+		return nil
+	}
+	v, err := formValue(fd, vals[len(vals)-1])
+	if err != nil {
+		return errors.New("form field '" + key + "': " + err.Error())
+	}
+	msg.Set(fd, v)
+	return nil
+}
+
+// formValue parses a single form value according to the field's kind.
+func formValue(fd protoreflect.FieldDescriptor, val string) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(val), nil
+	case protoreflect.BytesKind:
+		return protoreflect.ValueOfBytes([]byte(val)), nil
+	case protoreflect.BoolKind:
+		if val == "on" {
+			return protoreflect.ValueOfBool(true), nil
+		}
+		b, err := strconv.ParseBool(val)
+		return protoreflect.ValueOfBool(b), err
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		i, err := strconv.ParseInt(val, 10, 32)
+		return protoreflect.ValueOfInt32(int32(i)), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		i, err := strconv.ParseInt(val, 10, 64)
+		return protoreflect.ValueOfInt64(i), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		u, err := strconv.ParseUint(val, 10, 32)
+		return protoreflect.ValueOfUint32(uint32(u)), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		u, err := strconv.ParseUint(val, 10, 64)
+		return protoreflect.ValueOfUint64(u), err
+	case protoreflect.FloatKind:
+		f, err := strconv.ParseFloat(val, 32)
+		return protoreflect.ValueOfFloat32(float32(f)), err
+	case protoreflect.DoubleKind:
+		f, err := strconv.ParseFloat(val, 64)
+		return protoreflect.ValueOfFloat64(f), err
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByName(protoreflect.Name(val)); ev != nil {
+			return protoreflect.ValueOfEnum(ev.Number()), nil
+		}
+		i, err := strconv.ParseInt(val, 10, 32)
+		if err != nil {
+			return protoreflect.Value{}, errors.New("unknown enum value '" + val + "'")
+		}
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(i)), nil
+	}
+	return protoreflect.Value{}, errors.New("unsupported field kind " + fd.Kind().String())
+}
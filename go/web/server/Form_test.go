@@ -0,0 +1,64 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/typepb"
+)
+
+func TestForm_ToProto(t *testing.T) {
+	typ := &typepb.Type{}
+	err := formToProto([]byte("name=User&oneofs=a&oneofs=b&sourceContext.file_name=user.proto&syntax=SYNTAX_PROTO3"), typ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if typ.Name != "User" || len(typ.Oneofs) != 2 || typ.Oneofs[1] != "b" ||
+		typ.SourceContext.GetFileName() != "user.proto" || typ.Syntax != typepb.Syntax_SYNTAX_PROTO3 {
+		t.Fatalf("unexpected mapping %v", typ)
+	}
+
+	field := &typepb.Field{}
+	err = formToProto([]byte("number=7&packed=on&kind=9&json_name=userName"), field)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if field.Number != 7 || !field.Packed || field.Kind != typepb.Field_TYPE_STRING || field.JsonName != "userName" {
+		t.Fatalf("unexpected mapping %v", field)
+	}
+}
+
+func TestForm_Rejects(t *testing.T) {
+	cases := []struct {
+		form string
+		msg  proto.Message
+	}{
+		{"unknown=1", &typepb.Field{}},
+		{"number=seven", &typepb.Field{}},
+		{"kind=NOT_A_KIND", &typepb.Field{}},
+		{"fields.name=id", &typepb.Type{}},
+		{"sourceContext=user.proto", &typepb.Type{}},
+		{"fields=x", &structpb.Struct{}},
+	}
+	for _, c := range cases {
+		if formToProto([]byte(c.form), c.msg) == nil {
+			t.Fatalf("expected %q to be rejected", c.form)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// ForwardHeaders.go passes selected HTTP request headers, such as a locale, a
+// tenant or feature flags, to the backend service handling the request.
+//
+// The VNic request carries only the body and the AAA id, so the headers travel
+// in the body: a request body implementing HeaderCarrier is handed the
+// RestServerConfig.ForwardHeaders headers of the request before it is sent. A
+// generated Protocol Buffer type gets it by declaring the method in a file of
+// its own package, typically over a map<string, string> field:
+//
+//	func (this *Order) SetForwardedHeaders(headers map[string]string) {
+//	    this.Headers = headers
+//	}
+//
+// Bodies that don't implement it are sent without the headers.
+
+package server
+
+import (
+	"net/http"
+	"strings"
+)
+
+// HeaderCarrier is implemented by a service request body that carries
+// forwarded HTTP headers to the backend.
+type HeaderCarrier interface {
+	// SetForwardedHeaders is called with the forwarded headers by canonical
+	// name, the values of a repeated header joined with ", ". It is not called
+	// when the request has none of them.
+	SetForwardedHeaders(headers map[string]string)
+}
+
+// sensitiveHeaders carry credentials. They are only forwarded when listed by
+// name in ForwardHeaders, never through a wildcard.
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	CSRFHeader:            true,
+}
+
+// headerForwarder selects the headers to forward, see ForwardHeaders.
+type headerForwarder struct {
+	names    []string // Canonical header names
+	prefixes []string // Canonical prefixes of the wildcard entries
+}
+
+// newHeaderForwarder parses a ForwardHeaders allowlist, nil if it is empty.
+func newHeaderForwarder(allowlist []string) *headerForwarder {
+	if len(allowlist) == 0 {
+		return nil
+	}
+	forwarder := &headerForwarder{}
+	for _, entry := range allowlist {
+		if prefix, ok := strings.CutSuffix(entry, "*"); ok {
+			forwarder.prefixes = append(forwarder.prefixes, http.CanonicalHeaderKey(prefix))
+		} else {
+			forwarder.names = append(forwarder.names, http.CanonicalHeaderKey(entry))
+		}
+	}
+	return forwarder
+}
+
+// headers returns the allowlisted headers of r, nil if there are none.
+func (this *headerForwarder) headers(r *http.Request) map[string]string {
+	if this == nil {
+		return nil
+	}
+	var headers map[string]string
+	add := func(name string, values []string) {
+		if headers == nil {
+			headers = map[string]string{}
+		}
+		headers[name] = strings.Join(values, ", ")
+	}
+	for _, name := range this.names {
+		if values := r.Header.Values(name); len(values) > 0 {
+			add(name, values)
+		}
+	}
+	for name, values := range r.Header {
+		if sensitiveHeaders[name] || len(values) == 0 {
+			continue
+		}
+		for _, prefix := range this.prefixes {
+			if strings.HasPrefix(name, prefix) {
+				add(name, values)
+				break
+			}
+		}
+	}
+	return headers
+}
+
+// forward hands the allowlisted headers of r to a body implementing
+// HeaderCarrier.
+func (this *headerForwarder) forward(r *http.Request, body interface{}) {
+	carrier, ok := body.(HeaderCarrier)
+	if !ok {
+		return
+	}
+	if headers := this.headers(r); headers != nil {
+		carrier.SetForwardedHeaders(headers)
+	}
+}
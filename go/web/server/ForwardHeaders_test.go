@@ -0,0 +1,122 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8types/go/types/l8api"
+	"google.golang.org/protobuf/proto"
+)
+
+// headerQuery is a request body that carries forwarded headers and the request id.
+type headerQuery struct {
+	*l8api.L8Query
+	headers   map[string]string
+	requestID string
+}
+
+func (this *headerQuery) SetForwardedHeaders(headers map[string]string) {
+	this.headers = headers
+}
+
+func (this *headerQuery) SetRequestID(id string) {
+	this.requestID = id
+}
+
+// headerService parses bodies into a headerQuery.
+type headerService struct {
+	echoService
+}
+
+func (this *headerService) Protos(data string, action ifs.Action) (proto.Message, proto.Message, error) {
+	query, _, err := this.echoService.Protos(data, action)
+	return &headerQuery{L8Query: query.(*l8api.L8Query)}, nil, err
+}
+
+// headerVnic records the body it was sent and answers with its query.
+type headerVnic struct {
+	echoVnic
+	body *headerQuery
+}
+
+func (this *headerVnic) LeaderRequest(serviceName string, serviceArea byte, action ifs.Action, body interface{}, timeout int, tokens ...string) ifs.IElements {
+	this.body = body.(*headerQuery)
+	return &echoElements{query: this.body.L8Query}
+}
+
+func TestForwardHeaders(t *testing.T) {
+	vnic := &headerVnic{}
+	forwarder := newHeaderForwarder([]string{"accept-language", "X-Tenant", "X-Feature-*", "Missing"})
+	handler := &ServiceHandler{serviceName: "Tests", webService: &headerService{}, vnic: vnic, forwarder: forwarder}
+
+	r := httptest.NewRequest(http.MethodPost, "/0/Tests", strings.NewReader(`{"text":"x"}`))
+	r.Header.Set("Accept-Language", "fr-CA")
+	r.Header.Set("X-Tenant", "acme")
+	r.Header.Add("X-Feature-Beta", "on")
+	r.Header.Add("X-Feature-Beta", "dark")
+	r.Header.Set("X-Other", "dropped")
+	r.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	handler.serveHttp(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	expected := map[string]string{"Accept-Language": "fr-CA", "X-Tenant": "acme", "X-Feature-Beta": "on, dark"}
+	if len(vnic.body.headers) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, vnic.body.headers)
+	}
+	for name, value := range expected {
+		if vnic.body.headers[name] != value {
+			t.Fatalf("expected %v, got %v", expected, vnic.body.headers)
+		}
+	}
+
+	// Credentials only go through when listed by name.
+	r.Header.Set("Cookie", "bToken=secret")
+	if headers := newHeaderForwarder([]string{"*"}).headers(r); headers["Authorization"] != "" || headers["Cookie"] != "" || headers["X-Other"] != "dropped" {
+		t.Fatalf("expected a wildcard to skip credentials, got %v", headers)
+	}
+	if headers := newHeaderForwarder([]string{"Authorization"}).headers(r); headers["Authorization"] != "Bearer secret" {
+		t.Fatalf("expected an explicitly listed Authorization, got %v", headers)
+	}
+	if newHeaderForwarder(nil).headers(r) != nil {
+		t.Fatal("expected no headers without an allowlist")
+	}
+}
+
+func TestForwardRequestID(t *testing.T) {
+	vnic := &headerVnic{}
+	handler := &ServiceHandler{serviceName: "Tests", webService: &headerService{}, vnic: vnic}
+
+	r := httptest.NewRequest(http.MethodPost, "/0/Tests", strings.NewReader(`{"text":"x"}`))
+	r.Header.Set(RequestIDHeader, "edge-7f3a:42")
+	w := httptest.NewRecorder()
+	handler.serveHttp(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if vnic.body.requestID != "edge-7f3a:42" {
+		t.Fatalf("expected the request id in the body, got %q", vnic.body.requestID)
+	}
+	if vnic.body.headers != nil {
+		t.Fatalf("expected no headers without an allowlist, got %v", vnic.body.headers)
+	}
+}
@@ -0,0 +1,159 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Gzip.go compresses service and built-in endpoint responses for clients that
+// send Accept-Encoding: gzip, when RestServerConfig.EnableGzip is set.
+//
+// Responses are buffered until they reach GzipMinSize, so small payloads, where
+// compression costs more than it saves, are sent as they are.
+
+package server
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// GzipMinSize is the response size in bytes from which responses are compressed.
+var GzipMinSize = 1024
+
+// gzipResponseWriter buffers a response until it is large enough to compress,
+// then writes it through a gzip.Writer with Content-Encoding: gzip.
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	status      int          // Status passed to WriteHeader, sent with the first bytes
+	buff        []byte       // Response bytes held back until GzipMinSize is reached
+	gz          *gzip.Writer // Set once the response is being compressed
+	passthrough bool         // Set once the response is being sent uncompressed
+}
+
+// withGzip wraps a handler, compressing its response if enabled, from the
+// server's EnableGzip, and the client accepts it.
+func withGzip(enabled bool, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !enabled {
+			handler(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Accept-Encoding")
+		if r.Method == http.MethodHead || !acceptsGzip(r) {
+			handler(w, r)
+			return
+		}
+		gw := &gzipResponseWriter{ResponseWriter: w}
+		defer gw.close()
+		handler(gw, r)
+	}
+}
+
+// acceptsGzip reports whether the request's Accept-Encoding allows gzip.
+func acceptsGzip(r *http.Request) bool {
+	for _, coding := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(coding, ";")
+		name = strings.TrimSpace(name)
+		if !strings.EqualFold(name, "gzip") && name != "*" {
+			continue
+		}
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			weight, err := strconv.ParseFloat(q, 64)
+			return err == nil && weight > 0
+		}
+		return true
+	}
+	return false
+}
+
+func (this *gzipResponseWriter) WriteHeader(status int) {
+	if this.status == 0 {
+		this.status = status
+	}
+}
+
+func (this *gzipResponseWriter) Write(data []byte) (int, error) {
+	if this.status == 0 {
+		this.status = http.StatusOK
+	}
+	if this.gz != nil {
+		return this.gz.Write(data)
+	}
+	if this.passthrough {
+		return this.ResponseWriter.Write(data)
+	}
+	this.buff = append(this.buff, data...)
+	if len(this.buff) < GzipMinSize {
+		return len(data), nil
+	}
+	if err := this.flush(this.compressible()); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+// compressible reports whether the response may be compressed: it must have a
+// body and not be encoded by the handler already.
+func (this *gzipResponseWriter) compressible() bool {
+	return this.status != http.StatusNoContent && this.status != http.StatusNotModified &&
+		this.Header().Get("Content-Encoding") == ""
+}
+
+// flush sends the header and the buffered bytes, compressed or not, and
+// switches the writer to that mode for the rest of the response.
+func (this *gzipResponseWriter) flush(compress bool) error {
+	if compress {
+		this.Header().Set("Content-Encoding", "gzip")
+		this.Header().Del("Content-Length")
+		this.ResponseWriter.WriteHeader(this.status)
+		this.gz = gzip.NewWriter(this.ResponseWriter)
+		_, err := this.gz.Write(this.buff)
+		this.buff = nil
+		return err
+	}
+	this.passthrough = true
+	this.ResponseWriter.WriteHeader(this.status)
+	_, err := this.ResponseWriter.Write(this.buff)
+	this.buff = nil
+	return err
+}
+
+// Flush sends the bytes written so far, switching to compressed or plain mode
+// if the response is still buffered, and flushes the underlying writer.
+func (this *gzipResponseWriter) Flush() {
+	if this.status == 0 {
+		return
+	}
+	if this.gz == nil && !this.passthrough {
+		if this.flush(this.compressible()) != nil {
+			return
+		}
+	}
+	if this.gz != nil {
+		this.gz.Flush()
+	}
+	http.NewResponseController(this.ResponseWriter).Flush()
+}
+
+// close completes the response: it ends the gzip stream, or sends a response
+// that stayed below GzipMinSize as it is.
+func (this *gzipResponseWriter) close() {
+	switch {
+	case this.gz != nil:
+		this.gz.Close()
+	case this.passthrough, this.status == 0:
+	default:
+		this.flush(false)
+	}
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// serveGzip runs body through a withGzip wrapped handler, enabled or not, with
+// the given Accept-Encoding and returns the recorded response.
+func serveGzip(enabled bool, acceptEncoding, body string) *httptest.ResponseRecorder {
+	handler := withGzip(enabled, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(body))
+	})
+	r := httptest.NewRequest(http.MethodGet, "/0/Tests", nil)
+	if acceptEncoding != "" {
+		r.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	w := httptest.NewRecorder()
+	handler(w, r)
+	return w
+}
+
+func TestGzip_Compress(t *testing.T) {
+	large := strings.Repeat(`{"name":"element"},`, GzipMinSize)
+
+	if w := serveGzip(false, "gzip", large); w.Header().Get("Content-Encoding") != "" {
+		t.Fatal("expected no compression while gzip is disabled")
+	}
+
+	w := serveGzip(true, "deflate, gzip", large)
+	if w.Code != http.StatusCreated || w.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("expected a gzipped 201, got %d %q", w.Code, w.Header().Get("Content-Encoding"))
+	}
+	if w.Body.Len() >= len(large) {
+		t.Fatalf("expected a compressed body, got %d bytes", w.Body.Len())
+	}
+	reader, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil || string(data) != large {
+		t.Fatalf("unexpected decompressed body: %v", err)
+	}
+
+	for _, acceptEncoding := range []string{"", "deflate", "gzip;q=0"} {
+		w = serveGzip(true, acceptEncoding, large)
+		if w.Header().Get("Content-Encoding") != "" || w.Body.String() != large {
+			t.Fatalf("expected %q to get the plain body", acceptEncoding)
+		}
+	}
+
+	w = serveGzip(true, "gzip", `{"name":"element"}`)
+	if w.Code != http.StatusCreated || w.Header().Get("Content-Encoding") != "" || w.Body.String() != `{"name":"element"}` {
+		t.Fatalf("expected a small response to be sent as is, got %d %q", w.Code, w.Body.String())
+	}
+	if w.Header().Get("Vary") != "Accept-Encoding" {
+		t.Fatalf("expected Vary: Accept-Encoding, got %q", w.Header().Get("Vary"))
+	}
+}
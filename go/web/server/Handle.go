@@ -0,0 +1,117 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Handle.go registers plain http.Handlers on the server next to the Layer 8
+// web services, for endpoints that are not services, such as a webhook
+// receiver or an OAuth callback. They are served under the server's Prefix
+// and listed by Routes and /services like any other route.
+//
+// Example usage:
+//
+//	srv.HandleFunc("oauth/callback", oauthCallback)
+//	srv.Handle("exports", srv.RequireAuth(exportHandler))
+//
+// Inside a handler wrapped by RequireAuth, AuthenticatedUser returns the
+// id of the user the bearer token belongs to.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// authUserKey is the request context key of the user id set by RequireAuth.
+type authUserKey struct{}
+
+// authHandler is a handler that requires a valid bearer token, see RequireAuth.
+type authHandler struct {
+	server  *RestServer
+	handler http.Handler
+}
+
+// Handle registers handler at pattern, prefixed with the server's URL prefix,
+// e.g. "hooks/github" under "/api/v1/". A pattern ending in "/" matches its
+// whole subtree, as with http.ServeMux. Registering a pattern twice keeps the
+// first handler. Wrap handler with RequireAuth to require a bearer token.
+func (this *RestServer) Handle(pattern string, handler http.Handler) {
+	fullPath := this.Prefix + pattern
+	_, ok := endPoints.Get(fullPath)
+	if ok {
+		return
+	}
+	_, auth := handler.(*authHandler)
+	endPoints.Put(fullPath, &RouteInfo{Path: fullPath, Auth: auth})
+	fmt.Println("Registering path=", fullPath)
+	http.DefaultServeMux.Handle(fullPath, handler)
+}
+
+// HandleFunc registers a handler function at pattern, see Handle.
+func (this *RestServer) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	this.Handle(pattern, http.HandlerFunc(handler))
+}
+
+// RequireAuth wraps handler so it is only called for requests with a valid
+// bearer token, taken like the service endpoints' token: from the
+// Authorization header, the bearer cookie or, with AllowQueryToken, the
+// "token" query parameter. With EnableCSRF, cookie-authenticated,
+// state-changing requests must also pass the CSRF check. Other requests get
+// 401 Unauthorized, or 503 Service Unavailable before the WebService is
+// activated, as there is no security provider to validate tokens with yet.
+func (this *RestServer) RequireAuth(handler http.Handler) http.Handler {
+	return &authHandler{server: this, handler: handler}
+}
+
+func (this *authHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	mtx.Lock()
+	vnic := readyVnic
+	mtx.Unlock()
+	if vnic == nil {
+		writeError(w, http.StatusServiceUnavailable, "authentication is not available yet")
+		return
+	}
+	cookieName := this.server.BearerCookieName
+	if cookieName == "" {
+		cookieName = DefaultBearerCookieName
+	}
+	bearer := r.Header.Get("Authorization")
+	if bearer == "" {
+		bearer = extractToken(r, cookieName, this.server.AllowQueryToken)
+	}
+	if bearer == "" {
+		writeError(w, http.StatusUnauthorized, "missing bearer token")
+		return
+	}
+	if this.server.EnableCSRF {
+		if err := checkCSRF(r, cookieName); err != nil {
+			writeError(w, http.StatusForbidden, err.Error())
+			return
+		}
+	}
+	aaaid, ok := vnic.Resources().Security().ValidateToken(bearer, vnic)
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "invalid bearer token")
+		return
+	}
+	this.handler.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), authUserKey{}, aaaid)))
+}
+
+// AuthenticatedUser returns the id of the user authenticated by RequireAuth,
+// or "" if the request did not go through it.
+func AuthenticatedUser(r *http.Request) string {
+	aaaid, _ := r.Context().Value(authUserKey{}).(string)
+	return aaaid
+}
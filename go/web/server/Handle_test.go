@@ -0,0 +1,89 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/saichler/l8types/go/ifs"
+)
+
+// aliceSecurity accepts only alice's bearer token.
+type aliceSecurity struct {
+	ifs.ISecurityProvider
+}
+
+func (this *aliceSecurity) ValidateToken(token string, vnic ifs.IVNic) (string, bool) {
+	if token == "Bearer alice-token" || token == "alice-token" {
+		return "alice", true
+	}
+	return "", false
+}
+
+func TestHandle_CustomHandlers(t *testing.T) {
+	defer func() {
+		endPoints.Clean()
+		mtx.Lock()
+		readyVnic = nil
+		mtx.Unlock()
+	}()
+	http.DefaultServeMux = http.NewServeMux()
+	rs := &RestServer{}
+	rs.Prefix = "/api/v1/"
+	rs.HandleFunc("oauth/callback", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("callback"))
+	})
+	rs.Handle("exports", rs.RequireAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("export for " + AuthenticatedUser(r)))
+	})))
+
+	serve := func(path, token string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		if token != "" {
+			r.AddCookie(&http.Cookie{Name: DefaultBearerCookieName, Value: token})
+		}
+		w := httptest.NewRecorder()
+		http.DefaultServeMux.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := serve("/api/v1/oauth/callback", ""); w.Code != http.StatusOK || w.Body.String() != "callback" {
+		t.Fatalf("expected the public handler, got %d %q", w.Code, w.Body.String())
+	}
+	decodeError(t, serve("/api/v1/exports", "alice-token"), http.StatusServiceUnavailable)
+
+	mtx.Lock()
+	readyVnic = &securityVnic{resources: &securityResources{security: &aliceSecurity{}}}
+	mtx.Unlock()
+	decodeError(t, serve("/api/v1/exports", ""), http.StatusUnauthorized)
+	decodeError(t, serve("/api/v1/exports", "mallory-token"), http.StatusUnauthorized)
+	if w := serve("/api/v1/exports", "alice-token"); w.Code != http.StatusOK || w.Body.String() != "export for alice" {
+		t.Fatalf("expected alice's export, got %d %q", w.Code, w.Body.String())
+	}
+
+	routes := map[string]bool{}
+	for _, route := range Routes() {
+		routes[route.Path] = route.Auth
+	}
+	if auth, ok := routes["/api/v1/oauth/callback"]; !ok || auth {
+		t.Fatalf("expected a public callback route, got %v", routes)
+	}
+	if auth, ok := routes["/api/v1/exports"]; !ok || !auth {
+		t.Fatalf("expected an authenticated exports route, got %v", routes)
+	}
+}
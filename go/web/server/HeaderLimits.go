@@ -0,0 +1,50 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// HeaderLimits.go bounds the request headers the server accepts: their total
+// size, enforced by http.Server while reading them, and their number, checked
+// before any handler runs. Both harden the server against header floods.
+
+package server
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// withHeaderLimit wraps a handler, answering requests with more header lines
+// than MaxHeaderCount with 431 Request Header Fields Too Large.
+func (this *RestServer) withHeaderLimit(next http.Handler) http.Handler {
+	if this.MaxHeaderCount <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if headerCount(r.Header) > this.MaxHeaderCount {
+			writeError(w, http.StatusRequestHeaderFieldsTooLarge, "Request has more than "+strconv.Itoa(this.MaxHeaderCount)+" headers")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// headerCount returns the number of header lines, counting each value of a
+// repeated header.
+func headerCount(header http.Header) int {
+	count := 0
+	for _, values := range header {
+		count += len(values)
+	}
+	return count
+}
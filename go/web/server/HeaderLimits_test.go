@@ -0,0 +1,45 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestHeaderLimits_MaxHeaderCount(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	serve := func(rs *RestServer, headers int) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		for i := 0; i < headers; i++ {
+			r.Header.Add("X-Forwarded-For", "10.0.0."+strconv.Itoa(i))
+		}
+		w := httptest.NewRecorder()
+		rs.withHeaderLimit(ok).ServeHTTP(w, r)
+		return w
+	}
+
+	if w := serve(&RestServer{}, 100); w.Code != http.StatusOK {
+		t.Fatalf("expected no limit by default, got %d", w.Code)
+	}
+	rs := &RestServer{RestServerConfig: RestServerConfig{MaxHeaderCount: 10}}
+	if w := serve(rs, 10); w.Code != http.StatusOK {
+		t.Fatalf("expected a request at the limit to pass, got %d", w.Code)
+	}
+	decodeError(t, serve(rs, 11), http.StatusRequestHeaderFieldsTooLarge)
+}
@@ -0,0 +1,121 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Health.go implements the liveness and readiness probe endpoints.
+//
+//   - /healthz - Liveness: the process is up and serving HTTP
+//   - /readyz  - Readiness: the WebService VNic is connected and the web
+//     services required by RestServerConfig.RequiredServices (or, if none
+//     are configured, at least one web service) have been discovered
+//
+// Both endpoints are unauthenticated so they can be used by orchestrator probes.
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/saichler/l8types/go/ifs"
+)
+
+// readyVnic is the VNic of the activated WebService, used for readiness.
+var readyVnic ifs.IVNic
+
+// ReadyCheck is the result of a single readiness check, as listed by /readyz.
+type ReadyCheck struct {
+	Name   string `json:"name"`             // Check name ("vnic" or "services")
+	Ready  bool   `json:"ready"`            // Whether the check passed
+	Detail string `json:"detail,omitempty"` // Reason the check did not pass
+}
+
+// ReadyChecks evaluates the readiness checks: that the WebService VNic is
+// connected and that the server's RequiredServices have been discovered.
+func (this *RestServer) ReadyChecks() []*ReadyCheck {
+	vnicCheck := &ReadyCheck{Name: "vnic"}
+	mtx.Lock()
+	vnic := readyVnic
+	mtx.Unlock()
+	if vnic == nil {
+		vnicCheck.Detail = "web service not activated"
+	} else if !vnic.Running() {
+		vnicCheck.Detail = "vnic not connected"
+	} else {
+		vnicCheck.Ready = true
+	}
+
+	servicesCheck := &ReadyCheck{Name: "services"}
+	discovered := map[string]bool{}
+	for _, route := range Routes() {
+		if route.ServiceName != "" {
+			discovered[route.ServiceName] = true
+		}
+	}
+	if len(this.RequiredServices) == 0 {
+		if len(discovered) == 0 {
+			servicesCheck.Detail = "no web services discovered"
+		} else {
+			servicesCheck.Ready = true
+		}
+	} else {
+		missing := make([]string, 0)
+		for _, name := range this.RequiredServices {
+			if !discovered[name] {
+				missing = append(missing, name)
+			}
+		}
+		if len(missing) > 0 {
+			servicesCheck.Detail = "waiting for " + strings.Join(missing, ", ")
+		} else {
+			servicesCheck.Ready = true
+		}
+	}
+	return []*ReadyCheck{vnicCheck, servicesCheck}
+}
+
+// Ready reports whether all readiness checks pass.
+func (this *RestServer) Ready() bool {
+	for _, check := range this.ReadyChecks() {
+		if !check.Ready {
+			return false
+		}
+	}
+	return true
+}
+
+// healthz handles the /healthz liveness endpoint. It always returns 200.
+func healthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ok"))
+}
+
+// readyz handles the /readyz readiness endpoint, returning the readiness
+// checks as JSON with 200 when all pass and 503 otherwise.
+func (this *RestServer) readyz(w http.ResponseWriter, r *http.Request) {
+	checks := this.ReadyChecks()
+	status := http.StatusOK
+	for _, check := range checks {
+		if !check.Ready {
+			status = http.StatusServiceUnavailable
+			break
+		}
+	}
+	byt, _ := json.Marshal(checks)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(byt)
+}
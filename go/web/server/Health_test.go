@@ -0,0 +1,80 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/saichler/l8types/go/ifs"
+)
+
+// runningVnic is an ifs.IVNic that only answers Running.
+type runningVnic struct {
+	ifs.IVNic
+	running bool
+}
+
+func (this *runningVnic) Running() bool {
+	return this.running
+}
+
+func readyzStatus(rs *RestServer) int {
+	w := httptest.NewRecorder()
+	rs.readyz(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	return w.Code
+}
+
+func TestHealth_Readyz(t *testing.T) {
+	defer func() {
+		endPoints.Clean()
+		readyVnic = nil
+	}()
+	rs := &RestServer{}
+
+	w := httptest.NewRecorder()
+	healthz(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected liveness 200, got %d", w.Code)
+	}
+
+	if readyzStatus(rs) != http.StatusServiceUnavailable {
+		t.Fatal("expected 503 before activation")
+	}
+
+	vnic := &runningVnic{}
+	readyVnic = vnic
+	endPoints.Put("/test/0/Tests", &RouteInfo{Path: "/test/0/Tests", ServiceName: "Tests"})
+	if readyzStatus(rs) != http.StatusServiceUnavailable {
+		t.Fatal("expected 503 while the vnic is not connected")
+	}
+
+	vnic.running = true
+	if readyzStatus(rs) != http.StatusOK || !rs.Ready() {
+		t.Fatal("expected ready once connected and a service is discovered")
+	}
+
+	rs.RequiredServices = []string{"Tests", "Users"}
+	checks := rs.ReadyChecks()
+	if checks[1].Ready || checks[1].Detail != "waiting for Users" {
+		t.Fatalf("expected to wait for Users, got %+v", checks[1])
+	}
+	endPoints.Put("/test/0/Users", &RouteInfo{Path: "/test/0/Users", ServiceName: "Users"})
+	if readyzStatus(rs) != http.StatusOK {
+		t.Fatal("expected ready once all required services are discovered")
+	}
+}
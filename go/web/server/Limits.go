@@ -0,0 +1,138 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Limits.go bounds the number of requests a web service handles at once, so a
+// backend that only copes with limited concurrency gets backpressure at the
+// HTTP edge instead of every request being sent through the VNic immediately.
+//
+// Requests beyond a service's MaxInFlight wait in a bounded queue for a slot.
+// A request that finds the queue full, or waits longer than QueueTimeout, is
+// answered with 503 Service Unavailable and a Retry-After header. A slot is
+// held until the backend answers, even if the client has gone away.
+//
+// The limit is per service, not per path: the handlers of a service mounted
+// under several prefixes, or replaced by ReplaceWebServices, share it.
+
+package server
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultQueueTimeout is how long a request waits for a slot when
+// ServiceLimit.QueueTimeout is not set.
+const DefaultQueueTimeout = time.Second
+
+// ServiceLimit caps the concurrent requests of a web service.
+type ServiceLimit struct {
+	ServiceName  string        // Name of the web service
+	ServiceArea  byte          // Service area of the web service
+	MaxInFlight  int           // Requests sent to the backend at once; 0 disables the limit
+	MaxQueue     int           // Requests waiting for a slot; beyond it they get 503 at once
+	QueueTimeout time.Duration // Longest wait for a slot (default: DefaultQueueTimeout)
+}
+
+var (
+	errQueueFull    = errors.New("too many requests queued")
+	errQueueTimeout = errors.New("timed out waiting for a free slot")
+)
+
+// serviceLimiter is a semaphore with a bounded wait queue.
+type serviceLimiter struct {
+	slots    chan struct{} // Holds a token per request in flight
+	waiting  atomic.Int32  // Requests waiting for a slot
+	maxQueue int32
+	timeout  time.Duration
+}
+
+// applyServiceLimits sets the handler's limiter from the last ServiceLimits
+// entry for its service.
+func (this *RestServer) applyServiceLimits(handler *ServiceHandler) {
+	var limit *ServiceLimit
+	for i := range this.ServiceLimits {
+		if this.ServiceLimits[i].ServiceName == handler.serviceName && this.ServiceLimits[i].ServiceArea == handler.serviceArea {
+			limit = &this.ServiceLimits[i]
+		}
+	}
+	if limit == nil {
+		return
+	}
+	key := strconv.Itoa(int(handler.serviceArea)) + "/" + handler.serviceName
+	this.webServerMtx.Lock()
+	defer this.webServerMtx.Unlock()
+	if this.limiters == nil {
+		this.limiters = map[string]*serviceLimiter{}
+	}
+	if _, ok := this.limiters[key]; !ok {
+		this.limiters[key] = newServiceLimiter(*limit)
+	}
+	handler.limiter = this.limiters[key]
+}
+
+// newServiceLimiter returns the limiter for limit, or nil if it sets no limit.
+func newServiceLimiter(limit ServiceLimit) *serviceLimiter {
+	if limit.MaxInFlight <= 0 {
+		return nil
+	}
+	timeout := limit.QueueTimeout
+	if timeout <= 0 {
+		timeout = DefaultQueueTimeout
+	}
+	return &serviceLimiter{
+		slots:    make(chan struct{}, limit.MaxInFlight),
+		maxQueue: int32(limit.MaxQueue),
+		timeout:  timeout,
+	}
+}
+
+// acquire takes a slot, waiting in the queue if none is free. It returns
+// errQueueFull or errQueueTimeout if the request should be turned away, or
+// the context's error if it is done first. A nil limiter never blocks.
+func (this *serviceLimiter) acquire(ctx context.Context) error {
+	if this == nil {
+		return nil
+	}
+	select {
+	case this.slots <- struct{}{}:
+		return nil
+	default:
+	}
+	if this.waiting.Add(1) > this.maxQueue {
+		this.waiting.Add(-1)
+		return errQueueFull
+	}
+	defer this.waiting.Add(-1)
+	timer := time.NewTimer(this.timeout)
+	defer timer.Stop()
+	select {
+	case this.slots <- struct{}{}:
+		return nil
+	case <-timer.C:
+		return errQueueTimeout
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release frees a slot taken by acquire.
+func (this *serviceLimiter) release() {
+	if this != nil {
+		<-this.slots
+	}
+}
@@ -0,0 +1,82 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8types/go/types/l8api"
+)
+
+// gatedVnic is an echoVnic whose leader requests wait for release.
+type gatedVnic struct {
+	echoVnic
+	started chan struct{}
+	release chan struct{}
+}
+
+func (this *gatedVnic) LeaderRequest(serviceName string, serviceArea byte, action ifs.Action, body interface{}, timeout int, tokens ...string) ifs.IElements {
+	this.started <- struct{}{}
+	<-this.release
+	return &echoElements{query: body.(*l8api.L8Query)}
+}
+
+func TestLimits_Queue(t *testing.T) {
+	rs := &RestServer{}
+	rs.ServiceLimits = []ServiceLimit{{ServiceName: "Tests", MaxInFlight: 1, MaxQueue: 1, QueueTimeout: 50 * time.Millisecond}}
+	vnic := &gatedVnic{started: make(chan struct{}, 2), release: make(chan struct{})}
+	handler := &ServiceHandler{serviceName: "Tests", webService: &echoService{}, vnic: vnic}
+	rs.applyServiceLimits(handler)
+	other := &ServiceHandler{serviceName: "Tests"}
+	rs.applyServiceLimits(other)
+	if handler.limiter == nil || other.limiter != handler.limiter {
+		t.Fatal("expected the handlers of a service to share its limiter")
+	}
+
+	send := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.serveHttp(w, httptest.NewRequest(http.MethodGet, "/0/Tests", strings.NewReader(`{"text":"x"}`)))
+		return w
+	}
+	inFlight := make(chan *httptest.ResponseRecorder)
+	go func() { inFlight <- send() }()
+	<-vnic.started
+
+	queued := make(chan *httptest.ResponseRecorder)
+	go func() { queued <- send() }()
+	for handler.limiter.waiting.Load() != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	w := send()
+	decodeError(t, w, http.StatusServiceUnavailable)
+	if w.Header().Get("Retry-After") == "" {
+		t.Fatal("expected a Retry-After header for a full queue")
+	}
+	decodeError(t, <-queued, http.StatusServiceUnavailable)
+
+	close(vnic.release)
+	if w = <-inFlight; w.Code != http.StatusOK {
+		t.Fatalf("expected the in-flight request to complete, got %d", w.Code)
+	}
+	if w = send(); w.Code != http.StatusOK {
+		t.Fatalf("expected the slot to be free again, got %d", w.Code)
+	}
+}
@@ -16,13 +16,34 @@
 // LoadWebUI.go provides web UI file serving functionality for the REST server.
 // It dynamically scans a "web" directory and registers HTTP handlers for all
 // files found, with special handling for:
-//   - index.html files at directory roots (registered as directory paths)
+//   - index files at directory roots (registered as directory paths); the index
+//     file names are configured by RestServerConfig.IndexFiles, default index.html
 //   - HTML files (registered with cache-busting headers)
-//   - Static assets (CSS, JS, images, etc.)
+//   - Static assets (CSS, JS, images, etc.), cached for a year when their file
+//     name is fingerprinted with a content hash, e.g. app.4f3a2b.js
+//   - Audio and video files (cacheable, so Range requests and seeking work)
 //
 // The smart root handler provides SPA (Single Page Application) support by
 // serving index.html for unmatched routes, while still correctly routing
 // API endpoints based on the configured prefix.
+//
+// Only files that resolve inside the web directory are served; symlinks that
+// point outside it are skipped when scanning and rejected when serving.
+//
+// With RestServerConfig.UIBasePath set, e.g. "/app/", the files are served
+// under that path instead of the root: the handlers are registered with the
+// base path prepended, while webUIFileMap keeps paths relative to the web
+// directory, so "/app/" serves the root index file and "/app/js/main.js" the
+// file mapped at "/js/main.js".
+//
+// With RestServerConfig.HostWebDirs set, requests whose Host has a web
+// directory of its own are served from that directory instead, so one process
+// can serve a distinct front-end per domain. Handlers are registered for the
+// paths of every directory and look the file up by Host when serving.
+//
+// With RestServerConfig.WebDirRetryInterval set, a web directory missing at
+// startup, e.g. one mounted later by a sidecar, is rechecked periodically and
+// the UI is loaded once it appears.
 
 package server
 
@@ -30,17 +51,44 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"sync"
+	"time"
 )
 
+// DefaultIndexFile is the directory index file name used when
+// RestServerConfig.IndexFiles is not set.
+const DefaultIndexFile = "index.html"
+
+// DefaultFingerprintPattern matches file names fingerprinted with a content
+// hash by SPA bundlers: a dot or dash separated run of at least 6 lowercase
+// hex digits before the extension, e.g. app.4f3a2b.js or chunk-9e1c07d2.css.
+// It is used when RestServerConfig.FingerprintPattern is not set.
+var DefaultFingerprintPattern = regexp.MustCompile(`[.-][0-9a-f]{6,}\.[0-9A-Za-z]+$`)
+
+// immutableCacheControl is the Cache-Control of fingerprinted files: a new
+// build changes their name, so a cached copy never needs revalidation.
+const immutableCacheControl = "public, max-age=31536000, immutable"
+
+// serviceWorkerFiles lists the service worker file names, which are never
+// cached long-term even if fingerprinted: browsers must pick up a new one
+// to learn about new builds.
+var serviceWorkerFiles = map[string]bool{
+	"sw.js": true, "service-worker.js": true, "serviceworker.js": true,
+}
+
 var (
 	// webUIFileMap maps URL paths to filesystem paths for web UI files.
 	webUIFileMap = make(map[string]string)
-	// webUIFileMapMutex protects concurrent access to webUIFileMap.
+	// webUIRoot is the absolute, symlink-resolved web directory. Files outside
+	// it are never served. Protected by webUIFileMapMutex.
+	webUIRoot = ""
+	// webUIFileMapMutex protects concurrent access to webUIFileMap and webUIRoot.
 	webUIFileMapMutex sync.RWMutex
 	// webUIHandlerRegistry tracks registered HTTP handlers to prevent duplicates.
 	webUIHandlerRegistry = make(map[string]http.HandlerFunc)
@@ -48,8 +96,18 @@ var (
 	webUIHandlerRegistryMutex sync.RWMutex
 	// rootHandlerRegistered tracks whether the root "/" handler has been registered.
 	rootHandlerRegistered = false
+	// hostWebUIs maps lowercased host names, without port, to the web UI
+	// loaded from their HostWebDirs entry. Protected by webUIFileMapMutex.
+	hostWebUIs = make(map[string]*hostWebUI)
 )
 
+// hostWebUI is the web directory served to one host, see
+// RestServerConfig.HostWebDirs.
+type hostWebUI struct {
+	root  string            // Absolute, symlink-resolved web directory
+	files map[string]string // URL paths to filesystem paths, like webUIFileMap
+}
+
 // LoadWebUI scans the web directory and registers HTTP handlers for all files.
 // It clears the file map (for hot-reload) but preserves handler registrations
 // since Go's ServeMux doesn't support handler removal. In proxy mode, the root
@@ -57,18 +115,20 @@ var (
 func (this *RestServer) LoadWebUI() {
 	fmt.Println("Loading UI...")
 
+	// Determine the web directory path
+	webDir := this.getWebDirectory()
+
 	// Clear and reload web UI file mappings (but keep handler registry intact)
 	webUIFileMapMutex.Lock()
 	webUIFileMap = make(map[string]string)
+	webUIRoot = webRootOf(webDir)
 	webUIFileMapMutex.Unlock()
 
 	// DO NOT clear handler registry - handlers remain registered in ServeMux
 
-	// Determine the web directory path
-	webDir := this.getWebDirectory()
-
 	// Scan and register all web files (non-root index.html files get handlers here)
 	this.loadWebDir("/", webDir)
+	this.loadHostWebUIs()
 
 	// Register all .html files (except root index.html) before the root handler
 	this.registerHTMLHandlers()
@@ -76,7 +136,7 @@ func (this *RestServer) LoadWebUI() {
 	// Register smart root handler LAST (only once) so specific paths are matched first
 	// Skip in proxy mode - the proxy handles the root path
 	if !rootHandlerRegistered && !proxyMode {
-		http.HandleFunc("/", this.smartRootHandler)
+		http.HandleFunc(this.uiBasePath(), this.smartRootHandler)
 		rootHandlerRegistered = true
 	}
 }
@@ -85,99 +145,193 @@ func (this *RestServer) LoadWebUI() {
 // It checks: "web", "./web", "../web", "../../web" and returns the first
 // found path. Defaults to "web" if none are found.
 func (this *RestServer) getWebDirectory() string {
+	webDir, _ := this.findWebDirectory()
+	return webDir
+}
+
+// findWebDirectory returns the web directory getWebDirectory would use and
+// whether it exists.
+func (this *RestServer) findWebDirectory() (string, bool) {
 	// Try to find web directory in various locations
 	possiblePaths := []string{
-		"web",           // Current directory
-		"./web",         // Relative to current
-		"../web",        // Up one level
-		"../../web",     // Up two levels
+		"web",       // Current directory
+		"./web",     // Relative to current
+		"../web",    // Up one level
+		"../../web", // Up two levels
 	}
-	
+
 	for _, path := range possiblePaths {
 		if _, err := os.Stat(path); err == nil {
-			return path
+			return path, true
 		}
 	}
-	
+
 	// Default to "web" if not found
-	return "web"
+	return "web", false
+}
+
+// waitForWebDirectory rechecks every WebDirRetryInterval for a web directory
+// that was missing at startup, logging each attempt, and loads the web UI
+// once it appears. It gives up when the server is stopped.
+func (this *RestServer) waitForWebDirectory() {
+	ticker := time.NewTicker(this.WebDirRetryInterval)
+	defer ticker.Stop()
+	var done <-chan struct{}
+	if this.BaseContext != nil {
+		done = this.BaseContext.Done()
+	}
+	for attempt := 1; ; attempt++ {
+		select {
+		case <-ticker.C:
+		case <-done:
+			return
+		}
+		this.webServerMtx.Lock()
+		stopped := this.stopped
+		this.webServerMtx.Unlock()
+		if stopped {
+			return
+		}
+		if webDir, found := this.findWebDirectory(); found {
+			fmt.Println("Web directory", webDir, "found after", attempt, "retries")
+			this.LoadWebUI()
+			return
+		}
+		fmt.Println("Web directory not found (retry", attempt, "), retrying in", this.WebDirRetryInterval)
+	}
+}
+
+// webRootOf returns the absolute, symlink-resolved path of a web directory,
+// or the path as is if it cannot be resolved.
+func webRootOf(webDir string) string {
+	root, err := filepath.Abs(webDir)
+	if err == nil {
+		resolved, err := filepath.EvalSymlinks(root)
+		if err == nil {
+			root = resolved
+		}
+	}
+	return root
 }
 
-// loadWebDir recursively scans a directory and registers file handlers.
-// For index.html files, it registers the directory path as the URL.
-// For other files, it registers the full file path. Non-HTML files get
-// handlers immediately; HTML files are registered later in registerHTMLHandlers.
+// loadWebDir recursively scans a directory into webUIFileMap and registers
+// file handlers. Non-HTML files and directory index paths get handlers
+// immediately; HTML files are registered later in registerHTMLHandlers, and
+// the root index is served by smartRootHandler.
 func (this *RestServer) loadWebDir(path string, webDir string) {
+	webUIFileMapMutex.RLock()
+	root := webUIRoot
+	webUIFileMapMutex.RUnlock()
+
+	files := make(map[string]string)
+	this.scanWebDir(path, webDir, root, files)
+
+	// Store mappings
+	webUIFileMapMutex.Lock()
+	for webPath, filePath := range files {
+		webUIFileMap[webPath] = filePath
+	}
+	webUIFileMapMutex.Unlock()
+
+	for webPath := range files {
+		fmt.Println("Loaded file:", webPath)
+		if webPath != "/" && !strings.HasSuffix(webPath, ".html") {
+			this.registerWebUIHandler(webPath)
+		}
+	}
+}
+
+// loadHostWebUIs scans the HostWebDirs into hostWebUIs, replacing the ones
+// loaded before, and registers handlers for their files.
+func (this *RestServer) loadHostWebUIs() {
+	hosts := make(map[string]*hostWebUI)
+	for host, webDir := range this.HostWebDirs {
+		ui := &hostWebUI{root: webRootOf(webDir), files: make(map[string]string)}
+		this.scanWebDir("/", webDir, ui.root, ui.files)
+		hosts[webUIHost(host)] = ui
+		fmt.Println("Loaded web UI for host", host, "from", webDir, "with", len(ui.files), "files")
+	}
+
+	webUIFileMapMutex.Lock()
+	hostWebUIs = hosts
+	webUIFileMapMutex.Unlock()
+
+	for _, ui := range hosts {
+		for webPath := range ui.files {
+			if webPath != "/" {
+				this.registerWebUIHandler(webPath)
+			}
+		}
+	}
+}
+
+// scanWebDir recursively maps the files of a web directory into files by URL
+// path. A directory's index file is mapped at the directory path, except the
+// root index in proxy mode, which is mapped at "/index.html". Files that do
+// not resolve inside root are skipped.
+func (this *RestServer) scanWebDir(path, webDir, root string, files map[string]string) {
 	dirName := concat(webDir, path)
-	files, err := os.ReadDir(dirName)
+	entries, err := os.ReadDir(dirName)
 	if err != nil {
 		fmt.Println("Error loading web UI:", err)
 		return
 	}
 
-	for _, file := range files {
+	indexFile := this.indexFileOf(entries)
+	for _, file := range entries {
 		webPath := concat(path, file.Name())
 		if file.IsDir() {
-			this.loadWebDir(concat(webPath, "/"), webDir)
-		} else {
-			fullFilePath := filepath.Join(webDir, path, file.Name())
-			if file.Name() == "index.html" {
-				indexPath := path
-				if indexPath != "/" && !strings.HasSuffix(indexPath, "/") {
-					indexPath += "/"
-				}
-				// In proxy mode, register root index.html as "/index.html" instead of "/"
-				if proxyMode && indexPath == "/" {
-					indexPath = "/index.html"
-				}
-				fmt.Println("Loaded index.html at path:", indexPath)
-				// Store mapping
-				webUIFileMapMutex.Lock()
-				webUIFileMap[indexPath] = fullFilePath
-				webUIFileMapMutex.Unlock()
-
-				// Don't register handlers for index.html files - let smartRootHandler handle them
-				// Only register specific handlers for non-root index.html files (or proxy mode root)
-				if indexPath != "/" {
-					webUIHandlerRegistryMutex.RLock()
-					_, exists := webUIHandlerRegistry[indexPath]
-					webUIHandlerRegistryMutex.RUnlock()
-
-					if !exists {
-						handler := this.createDynamicHandler(indexPath)
-						webUIHandlerRegistryMutex.Lock()
-						webUIHandlerRegistry[indexPath] = handler
-						webUIHandlerRegistryMutex.Unlock()
-						http.HandleFunc(indexPath, handler)
-					}
-				}
-			} else {
-				fmt.Println("Loaded file:", webPath)
-				// Store mapping
-				webUIFileMapMutex.Lock()
-				webUIFileMap[webPath] = fullFilePath
-				webUIFileMapMutex.Unlock()
-
-				// Register handlers for all non-HTML files immediately
-				// HTML files (except index.html) will be registered in registerHTMLHandlers
-				if !strings.HasSuffix(webPath, ".html") {
-					webUIHandlerRegistryMutex.RLock()
-					_, exists := webUIHandlerRegistry[webPath]
-					webUIHandlerRegistryMutex.RUnlock()
-
-					if !exists {
-						handler := this.createDynamicHandler(webPath)
-						webUIHandlerRegistryMutex.Lock()
-						webUIHandlerRegistry[webPath] = handler
-						webUIHandlerRegistryMutex.Unlock()
-						http.HandleFunc(webPath, handler)
-					}
-				}
+			this.scanWebDir(concat(webPath, "/"), webDir, root, files)
+			continue
+		}
+		fullFilePath := filepath.Join(webDir, path, file.Name())
+		if !insideWebRoot(root, fullFilePath) {
+			fmt.Println("Skipping file outside the web directory:", webPath)
+			continue
+		}
+		if file.Name() == indexFile {
+			webPath = path
+			if webPath != "/" && !strings.HasSuffix(webPath, "/") {
+				webPath += "/"
+			}
+			// In proxy mode, register the root index as "/index.html" instead of "/"
+			if proxyMode && webPath == "/" {
+				webPath = "/index.html"
 			}
 		}
+		files[webPath] = fullFilePath
 	}
 }
 
+// registerWebUIHandler registers the dynamic handler for a web UI path, once.
+func (this *RestServer) registerWebUIHandler(webPath string) {
+	webUIHandlerRegistryMutex.Lock()
+	defer webUIHandlerRegistryMutex.Unlock()
+	if _, exists := webUIHandlerRegistry[webPath]; exists {
+		return
+	}
+	handler := this.createDynamicHandler(webPath)
+	webUIHandlerRegistry[webPath] = handler
+	http.HandleFunc(this.uiPath(webPath), handler)
+}
+
+// indexFileOf returns the name of the directory's index file: the first of
+// the configured IndexFiles present among files, or "" if there is none.
+func (this *RestServer) indexFileOf(files []os.DirEntry) string {
+	indexFiles := this.IndexFiles
+	if len(indexFiles) == 0 {
+		indexFiles = []string{DefaultIndexFile}
+	}
+	for _, indexFile := range indexFiles {
+		for _, file := range files {
+			if !file.IsDir() && file.Name() == indexFile {
+				return indexFile
+			}
+		}
+	}
+	return ""
+}
+
 // registerHTMLHandlers registers HTTP handlers for all .html files (except
 // index.html files which are handled by loadWebDir). This is called after
 // loadWebDir to ensure HTML handlers are registered before the root handler.
@@ -188,18 +342,7 @@ func (this *RestServer) registerHTMLHandlers() {
 	for webPath := range webUIFileMap {
 		// Only register handlers for .html files (excluding index.html paths)
 		if strings.HasSuffix(webPath, ".html") && !strings.HasSuffix(webPath, "/") {
-			webUIHandlerRegistryMutex.RLock()
-			_, exists := webUIHandlerRegistry[webPath]
-			webUIHandlerRegistryMutex.RUnlock()
-
-			if !exists {
-				handler := this.createDynamicHandler(webPath)
-				webUIHandlerRegistryMutex.Lock()
-				webUIHandlerRegistry[webPath] = handler
-				webUIHandlerRegistryMutex.Unlock()
-				http.HandleFunc(webPath, handler)
-				fmt.Println("Registered HTML handler:", webPath)
-			}
+			this.registerWebUIHandler(webPath)
 		}
 	}
 }
@@ -210,31 +353,22 @@ func (this *RestServer) registerHTMLHandlers() {
 func (this *RestServer) createDynamicHandler(path string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Dynamically look up the current file path
-		webUIFileMapMutex.RLock()
-		filePath, exists := webUIFileMap[path]
-		webUIFileMapMutex.RUnlock()
-		
+		filePath, exists := webUIFile(r.Host, path)
 		if exists {
-			// Add cache-busting headers
-			w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
-			w.Header().Set("Pragma", "no-cache")
-			w.Header().Set("Expires", "0")
+			this.setCacheHeaders(w, filePath)
 			http.ServeFile(w, r, filePath)
 		} else {
-			// Custom 404 response
-			w.Header().Set("Content-Type", "text/plain")
-			w.WriteHeader(http.StatusNotFound)
-			w.Write([]byte("File Not Found"))
+			this.webUINotFound(w, r)
 		}
 	}
 }
 
-// smartRootHandler is the catch-all handler for the root path and unmatched routes.
+// smartRootHandler is the catch-all handler for the UI base path (the root by
+// default) and unmatched routes under it.
 // It provides SPA (Single Page Application) support by:
 // 1. Passing through API endpoints (those with the configured prefix) to return 404
-// 2. Serving exact file matches from the web UI map
-// 3. Serving index.html for the root path
-// 4. Returning 404 for all other unmatched paths
+// 2. Serving exact file matches from the web UI map, including the index file for the base path
+// 3. Returning 404 for all other unmatched paths, through NotFoundHandler if configured
 func (this *RestServer) smartRootHandler(w http.ResponseWriter, r *http.Request) {
 	// Check if this looks like an API endpoint (has prefix)
 	if this.Prefix != "" && strings.HasPrefix(r.URL.Path, this.Prefix) {
@@ -242,45 +376,149 @@ func (this *RestServer) smartRootHandler(w http.ResponseWriter, r *http.Request)
 		http.NotFound(w, r)
 		return
 	}
-	
-	webUIFileMapMutex.RLock()
-	
-	// Check for exact file match first
-	filePath, exists := webUIFileMap[r.URL.Path]
-	if exists {
-		webUIFileMapMutex.RUnlock()
-		// Add cache-busting headers
-		w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
-		w.Header().Set("Pragma", "no-cache")
-		w.Header().Set("Expires", "0")
-		http.ServeFile(w, r, filePath)
+
+	base := this.uiBasePath()
+	if !strings.HasPrefix(r.URL.Path, base) {
+		this.webUINotFound(w, r)
 		return
 	}
-	
-	// Check for root index.html if requesting root
-	if r.URL.Path == "/" {
-		rootIndexPath, hasRootIndex := webUIFileMap["/"]
-		if hasRootIndex {
-			webUIFileMapMutex.RUnlock()
-			// Add cache-busting headers
-			w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
-			w.Header().Set("Pragma", "no-cache")
-			w.Header().Set("Expires", "0")
-			http.ServeFile(w, r, rootIndexPath)
-			return
-		}
+
+	// The root index file is mapped at "/", so an exact match covers it too
+	filePath, exists := webUIFile(r.Host, "/"+strings.TrimPrefix(r.URL.Path, base))
+	if !exists {
+		this.webUINotFound(w, r)
+		return
+	}
+	this.setCacheHeaders(w, filePath)
+	http.ServeFile(w, r, filePath)
+}
+
+// uiBasePath returns UIBasePath with a leading and trailing slash, or "/" if
+// it is not set.
+func (this *RestServer) uiBasePath() string {
+	base := strings.Trim(this.UIBasePath, "/")
+	if base == "" {
+		return "/"
+	}
+	return "/" + base + "/"
+}
+
+// uiPath returns the URL path serving the web UI file mapped at webPath.
+func (this *RestServer) uiPath(webPath string) string {
+	return this.uiBasePath() + strings.TrimPrefix(webPath, "/")
+}
+
+// webUIFile returns the file mapped to a URL path, in the web UI of host if it
+// has one of its own and in the default web UI otherwise. The read lock is held only
+// for the lookup, never while the file is served, so slow disk I/O can't stall
+// a LoadWebUI reload waiting on the write lock.
+//
+// Only paths found by scanning the web directory are in the map, so request
+// paths with ".." (encoded or not) never match. The file is also checked to
+// still resolve inside the web directory, in case it was replaced by a symlink
+// after it was scanned.
+func webUIFile(host, path string) (string, bool) {
+	webUIFileMapMutex.RLock()
+	files, root := webUIFileMap, webUIRoot
+	if ui, ok := hostWebUIs[webUIHost(host)]; ok {
+		files, root = ui.files, ui.root
 	}
-	
+	filePath, exists := files[path]
 	webUIFileMapMutex.RUnlock()
-	
-	// Custom 404 response for everything else
+	if !exists || !insideWebRoot(root, filePath) {
+		return "", false
+	}
+	return filePath, true
+}
+
+// webUIHost returns a request or HostWebDirs host in the form hostWebUIs is
+// keyed by: lowercased, without a port.
+func webUIHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(host)
+}
+
+// insideWebRoot reports whether filePath, with symlinks resolved, is inside
+// the web directory root.
+func insideWebRoot(root, filePath string) bool {
+	if root == "" {
+		return false
+	}
+	resolved, err := filepath.EvalSymlinks(filePath)
+	if err != nil {
+		return false
+	}
+	resolved, err = filepath.Abs(resolved)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(root, resolved)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// mediaExtensions lists the audio and video file extensions that browsers
+// fetch with Range requests, e.g. when seeking in a <video> element.
+var mediaExtensions = map[string]bool{
+	".mp4": true, ".m4v": true, ".webm": true, ".ogv": true, ".mov": true,
+	".mp3": true, ".m4a": true, ".ogg": true, ".oga": true, ".wav": true,
+	".flac": true, ".aac": true, ".opus": true,
+}
+
+// setCacheHeaders adds the caching headers for a web UI file. Files are
+// served with cache-busting headers so browsers always fetch the current
+// version, except:
+//   - fingerprinted files, whose name changes with their content, are cached
+//     for a year without revalidation; HTML and service worker files never are
+//   - media files: no-store prevents the browser from caching the byte ranges
+//     it seeks through, so they are only required to revalidate. http.ServeFile
+//     answers Range requests with 206 Partial Content either way.
+func (this *RestServer) setCacheHeaders(w http.ResponseWriter, filePath string) {
+	if this.fingerprinted(filePath) {
+		w.Header().Set("Cache-Control", immutableCacheControl)
+		return
+	}
+	if mediaExtensions[strings.ToLower(filepath.Ext(filePath))] {
+		w.Header().Set("Cache-Control", "no-cache")
+		return
+	}
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	w.Header().Set("Pragma", "no-cache")
+	w.Header().Set("Expires", "0")
+}
+
+// fingerprinted reports whether a web UI file may be cached long-term: its
+// name matches the FingerprintPattern and it is neither an HTML file nor a
+// service worker.
+func (this *RestServer) fingerprinted(filePath string) bool {
+	name := filepath.Base(filePath)
+	ext := strings.ToLower(filepath.Ext(name))
+	if ext == ".html" || ext == ".htm" || serviceWorkerFiles[strings.ToLower(name)] {
+		return false
+	}
+	pattern := this.FingerprintPattern
+	if pattern == nil {
+		pattern = DefaultFingerprintPattern
+	}
+	return pattern.MatchString(name)
+}
+
+// webUINotFound answers a request for a path with no web UI file, delegating
+// to the configured NotFoundHandler if there is one, or writing a plain text 404.
+func (this *RestServer) webUINotFound(w http.ResponseWriter, r *http.Request) {
+	if this.NotFoundHandler != nil {
+		this.NotFoundHandler(w, r)
+		return
+	}
 	w.Header().Set("Content-Type", "text/plain")
 	w.WriteHeader(http.StatusNotFound)
 	w.Write([]byte("File Not Found"))
 }
 
-
-
 // UpdateLoginJsonPrefix reads the web/login.json file, updates the apiPrefix
 // field under the "app" section with the given prefix, and writes it back.
 func UpdateLoginJsonPrefix(prefix string) error {
@@ -0,0 +1,376 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+)
+
+// setWebUIFiles replaces the web UI file map with files written to a temp dir.
+func setWebUIFiles(t *testing.T, files map[string]string) string {
+	dir, _ := filepath.EvalSymlinks(t.TempDir())
+	fileMap := make(map[string]string)
+	for urlPath, content := range files {
+		name := filepath.Join(dir, filepath.FromSlash(urlPath))
+		if urlPath == "/" || urlPath[len(urlPath)-1] == '/' {
+			name = filepath.Join(name, "index.html")
+		}
+		os.MkdirAll(filepath.Dir(name), 0755)
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		fileMap[urlPath] = name
+	}
+	webUIFileMapMutex.Lock()
+	webUIFileMap = fileMap
+	webUIRoot = dir
+	webUIFileMapMutex.Unlock()
+	t.Cleanup(func() {
+		webUIFileMapMutex.Lock()
+		webUIFileMap = make(map[string]string)
+		webUIRoot = ""
+		webUIFileMapMutex.Unlock()
+	})
+	return dir
+}
+
+func serveWebUI(handler http.HandlerFunc, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w
+}
+
+func TestLoadWebUI_SmartRootHandler(t *testing.T) {
+	setWebUIFiles(t, map[string]string{"/": "root", "/app.js": "js"})
+	rs := &RestServer{RestServerConfig: RestServerConfig{Prefix: "/api/"}}
+
+	if w := serveWebUI(rs.smartRootHandler, "/"); w.Code != http.StatusOK || w.Body.String() != "root" {
+		t.Fatalf("expected root index, got %d %q", w.Code, w.Body.String())
+	}
+	if w := serveWebUI(rs.smartRootHandler, "/app.js"); w.Body.String() != "js" || w.Header().Get("Cache-Control") == "" {
+		t.Fatalf("expected app.js with cache headers, got %q %v", w.Body.String(), w.Header())
+	}
+	if w := serveWebUI(rs.smartRootHandler, "/missing"); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+	if w := serveWebUI(rs.smartRootHandler, "/api/0/Users"); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for API path, got %d", w.Code)
+	}
+}
+
+func TestLoadWebUI_ConcurrentReload(t *testing.T) {
+	setWebUIFiles(t, map[string]string{"/": "root"})
+	rs := &RestServer{}
+	handler := rs.createDynamicHandler("/")
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				serveWebUI(rs.smartRootHandler, "/")
+				serveWebUI(handler, "/")
+			}
+		}()
+	}
+	for j := 0; j < 50; j++ {
+		webUIFileMapMutex.Lock()
+		fileMap := make(map[string]string)
+		for k, v := range webUIFileMap {
+			fileMap[k] = v
+		}
+		webUIFileMap = fileMap
+		webUIFileMapMutex.Unlock()
+	}
+	wg.Wait()
+}
+
+func TestLoadWebUI_PathTraversal(t *testing.T) {
+	dir := setWebUIFiles(t, map[string]string{"/": "root", "/app.js": "js"})
+	rs := &RestServer{}
+
+	outside := filepath.Join(t.TempDir(), "secret.txt")
+	os.WriteFile(outside, []byte("secret"), 0644)
+
+	paths := []string{
+		"/../../etc/passwd",
+		"/%2e%2e/%2e%2e/etc/passwd",
+		"/..%2f..%2fetc%2fpasswd",
+		"/%2e%2e%5c%2e%2e%5cetc%5cpasswd",
+		"/app.js/../../secret.txt",
+	}
+	for _, path := range paths {
+		w := httptest.NewRecorder()
+		rs.smartRootHandler(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("expected 404 for %s, got %d", path, w.Code)
+		}
+	}
+
+	// A mapped file later replaced by a symlink out of the web directory
+	// must not be served.
+	link := filepath.Join(dir, "app.js")
+	os.Remove(link)
+	if err := os.Symlink(outside, link); err != nil {
+		t.Skip("symlinks not supported:", err)
+	}
+	if w := serveWebUI(rs.smartRootHandler, "/app.js"); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for symlink outside the web directory, got %d", w.Code)
+	}
+	if w := serveWebUI(rs.createDynamicHandler("/app.js"), "/app.js"); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 from the dynamic handler, got %d", w.Code)
+	}
+
+	// Scanning skips such symlinks altogether.
+	webUIFileMapMutex.Lock()
+	webUIFileMap = make(map[string]string)
+	webUIFileMapMutex.Unlock()
+	rs.loadWebDir("/", dir)
+	webUIFileMapMutex.RLock()
+	_, mapped := webUIFileMap["/app.js"]
+	webUIFileMapMutex.RUnlock()
+	if mapped {
+		t.Fatal("expected the symlink to be skipped when scanning")
+	}
+}
+
+func TestLoadWebUI_IndexFiles(t *testing.T) {
+	dir := setWebUIFiles(t, map[string]string{})
+	for _, name := range []string{"index.html", "index.htm", "app.html"} {
+		os.WriteFile(filepath.Join(dir, name), []byte(name), 0644)
+	}
+
+	cases := []struct {
+		indexFiles []string
+		expected   string
+	}{
+		{nil, "index.html"},
+		{[]string{"app.html", "index.htm"}, "app.html"},
+		{[]string{"default.html", "index.htm"}, "index.htm"},
+	}
+	for _, c := range cases {
+		rs := &RestServer{RestServerConfig: RestServerConfig{IndexFiles: c.indexFiles}}
+		webUIFileMapMutex.Lock()
+		webUIFileMap = make(map[string]string)
+		webUIFileMapMutex.Unlock()
+		rs.loadWebDir("/", dir)
+		if w := serveWebUI(rs.smartRootHandler, "/"); w.Body.String() != c.expected {
+			t.Fatalf("expected %s as the index for %v, got %q", c.expected, c.indexFiles, w.Body.String())
+		}
+	}
+}
+
+func TestLoadWebUI_MediaRange(t *testing.T) {
+	setWebUIFiles(t, map[string]string{"/intro.mp4": "0123456789", "/app.js": "js"})
+	rs := &RestServer{}
+
+	r := httptest.NewRequest(http.MethodGet, "/intro.mp4", nil)
+	r.Header.Set("Range", "bytes=2-5")
+	w := httptest.NewRecorder()
+	rs.smartRootHandler(w, r)
+	if w.Code != http.StatusPartialContent || w.Body.String() != "2345" {
+		t.Fatalf("expected 206 with bytes 2-5, got %d %q", w.Code, w.Body.String())
+	}
+	if w.Header().Get("Cache-Control") != "no-cache" || w.Header().Get("Pragma") != "" {
+		t.Fatalf("expected media to be cacheable with revalidation, got %v", w.Header())
+	}
+
+	w = serveWebUI(rs.smartRootHandler, "/app.js")
+	if w.Header().Get("Cache-Control") != "no-cache, no-store, must-revalidate" {
+		t.Fatalf("expected cache-busting headers for app.js, got %v", w.Header())
+	}
+}
+
+func TestLoadWebUI_NotFoundHandler(t *testing.T) {
+	setWebUIFiles(t, map[string]string{"/": "root"})
+	rs := &RestServer{RestServerConfig: RestServerConfig{
+		Prefix: "/api/",
+		NotFoundHandler: func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("<h1>Lost?</h1>"))
+		},
+	}}
+
+	if w := serveWebUI(rs.smartRootHandler, "/missing"); w.Code != http.StatusNotFound || w.Body.String() != "<h1>Lost?</h1>" {
+		t.Fatalf("expected the custom 404, got %d %q", w.Code, w.Body.String())
+	}
+	if w := serveWebUI(rs.createDynamicHandler("/gone.js"), "/gone.js"); w.Body.String() != "<h1>Lost?</h1>" {
+		t.Fatalf("expected the custom 404 from the dynamic handler, got %q", w.Body.String())
+	}
+	if w := serveWebUI(rs.smartRootHandler, "/api/0/Users"); w.Body.String() == "<h1>Lost?</h1>" {
+		t.Fatal("API paths must not use the web UI 404 handler")
+	}
+}
+
+func TestLoadWebUI_BasePath(t *testing.T) {
+	setWebUIFiles(t, map[string]string{"/": "root", "/js/app.js": "js"})
+	rs := &RestServer{RestServerConfig: RestServerConfig{Prefix: "/api/", UIBasePath: "app"}}
+
+	if path := rs.uiPath("/js/app.js"); path != "/app/js/app.js" {
+		t.Fatalf("expected /app/js/app.js, got %s", path)
+	}
+	if w := serveWebUI(rs.smartRootHandler, "/app/"); w.Code != http.StatusOK || w.Body.String() != "root" {
+		t.Fatalf("expected root index at the base path, got %d %q", w.Code, w.Body.String())
+	}
+	if w := serveWebUI(rs.smartRootHandler, "/app/js/app.js"); w.Body.String() != "js" {
+		t.Fatalf("expected app.js under the base path, got %q", w.Body.String())
+	}
+	if w := serveWebUI(rs.smartRootHandler, "/js/app.js"); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 outside the base path, got %d", w.Code)
+	}
+
+	rs.UIBasePath = "/"
+	if path := rs.uiPath("/js/app.js"); path != "/js/app.js" {
+		t.Fatalf("expected /js/app.js for the root base path, got %s", path)
+	}
+}
+
+func TestLoadWebUI_HostWebDirs(t *testing.T) {
+	setWebUIFiles(t, map[string]string{"/": "layer8", "/app.js": "layer8 js"})
+	probler := t.TempDir()
+	os.WriteFile(filepath.Join(probler, "index.html"), []byte("probler"), 0644)
+	os.WriteFile(filepath.Join(probler, "probler.js"), []byte("probler js"), 0644)
+	rs := &RestServer{RestServerConfig: RestServerConfig{HostWebDirs: map[string]string{"Probler.dev": probler}}}
+	rs.loadHostWebUIs()
+	t.Cleanup(func() {
+		webUIFileMapMutex.Lock()
+		hostWebUIs = make(map[string]*hostWebUI)
+		webUIFileMapMutex.Unlock()
+	})
+
+	serve := func(handler http.HandlerFunc, host, path string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.Host = host
+		w := httptest.NewRecorder()
+		handler(w, r)
+		return w
+	}
+	if w := serve(rs.smartRootHandler, "probler.dev:443", "/"); w.Body.String() != "probler" {
+		t.Fatalf("expected the probler.dev index, got %q", w.Body.String())
+	}
+	if w := serve(rs.createDynamicHandler("/probler.js"), "probler.dev", "/probler.js"); w.Body.String() != "probler js" {
+		t.Fatalf("expected probler.js for probler.dev, got %q", w.Body.String())
+	}
+	if w := serve(rs.createDynamicHandler("/app.js"), "probler.dev", "/app.js"); w.Code != http.StatusNotFound {
+		t.Fatalf("expected the default UI's files not to leak into probler.dev, got %d", w.Code)
+	}
+	if w := serve(rs.smartRootHandler, "layer-8.dev", "/"); w.Body.String() != "layer8" {
+		t.Fatalf("expected the default index for other hosts, got %q", w.Body.String())
+	}
+	if w := serve(rs.createDynamicHandler("/probler.js"), "layer-8.dev", "/probler.js"); w.Code != http.StatusNotFound {
+		t.Fatalf("expected probler.js to be 404 for other hosts, got %d", w.Code)
+	}
+}
+
+func TestLoadWebUI_Fingerprinted(t *testing.T) {
+	setWebUIFiles(t, map[string]string{
+		"/app.4f3a2b.js":        "js",
+		"/chunk-9e1c07d2.css":   "css",
+		"/app.js":               "js",
+		"/page.4f3a2b.html":     "html",
+		"/service-worker.js":    "sw",
+		"/assets/logo-x1Y2.png": "png",
+	})
+	rs := &RestServer{}
+
+	for path, immutable := range map[string]bool{
+		"/app.4f3a2b.js":        true,
+		"/chunk-9e1c07d2.css":   true,
+		"/app.js":               false,
+		"/page.4f3a2b.html":     false,
+		"/service-worker.js":    false,
+		"/assets/logo-x1Y2.png": false,
+	} {
+		w := serveWebUI(rs.smartRootHandler, path)
+		if cc := w.Header().Get("Cache-Control"); (cc == "public, max-age=31536000, immutable") != immutable {
+			t.Fatalf("%s: expected immutable %v, got Cache-Control %q", path, immutable, cc)
+		}
+	}
+
+	rs.FingerprintPattern = regexp.MustCompile(`-[0-9A-Za-z]{4}\.png$`)
+	if w := serveWebUI(rs.smartRootHandler, "/assets/logo-x1Y2.png"); w.Header().Get("Cache-Control") != "public, max-age=31536000, immutable" {
+		t.Fatalf("expected a custom pattern to be honored, got %v", w.Header())
+	}
+	if w := serveWebUI(rs.smartRootHandler, "/app.4f3a2b.js"); w.Header().Get("Pragma") != "no-cache" {
+		t.Fatalf("expected a custom pattern to replace the default, got %v", w.Header())
+	}
+}
+
+func TestLoadWebUI_WebDirRetry(t *testing.T) {
+	wd, _ := os.Getwd()
+	dir := t.TempDir()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	mux, rootRegistered := http.DefaultServeMux, rootHandlerRegistered
+	http.DefaultServeMux = http.NewServeMux()
+	t.Cleanup(func() {
+		os.Chdir(wd)
+		http.DefaultServeMux, rootHandlerRegistered = mux, rootRegistered
+		webUIFileMapMutex.Lock()
+		webUIFileMap = make(map[string]string)
+		webUIRoot = ""
+		webUIFileMapMutex.Unlock()
+	})
+	if _, found := (&RestServer{}).findWebDirectory(); found {
+		t.Skip("a web directory exists above the temp dir")
+	}
+
+	rs := &RestServer{RestServerConfig: RestServerConfig{WebDirRetryInterval: 10 * time.Millisecond}}
+	finished := make(chan struct{})
+	go func() {
+		rs.waitForWebDirectory()
+		close(finished)
+	}()
+	time.Sleep(30 * time.Millisecond)
+	os.MkdirAll(filepath.Join(dir, "web"), 0755)
+	os.WriteFile(filepath.Join(dir, "web", "retry.js"), []byte("js"), 0644)
+
+	select {
+	case <-finished:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the web UI to load once the directory appeared")
+	}
+	webUIFileMapMutex.RLock()
+	_, loaded := webUIFileMap["/retry.js"]
+	webUIFileMapMutex.RUnlock()
+	if !loaded {
+		t.Fatal("expected retry.js to be loaded")
+	}
+
+	// A stopped server stops retrying.
+	rs = &RestServer{RestServerConfig: RestServerConfig{WebDirRetryInterval: 10 * time.Millisecond}}
+	os.Chdir(t.TempDir())
+	rs.stopped = true
+	finished = make(chan struct{})
+	go func() {
+		rs.waitForWebDirectory()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the retries to end for a stopped server")
+	}
+}
@@ -0,0 +1,152 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Metrics.go records per service request counts, response sizes and durations,
+// and logs requests slower than the slow request threshold.
+//
+// The counters are kept per web service for the life of the process, so the
+// services returning the largest payloads or answering slowest can be found
+// with Metrics, e.g. to spot unbounded lists or N+1 queries in a backend.
+
+package server
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultSlowRequestThreshold is the duration after which a service request
+// is logged as slow when RestServerConfig.SlowRequestThreshold is not set.
+const DefaultSlowRequestThreshold = 2 * time.Second
+
+// ServiceMetrics holds the request counters of one web service.
+type ServiceMetrics struct {
+	ServiceName      string
+	ServiceArea      byte
+	Requests         int64         // Requests served, including rejected ones
+	Errors           int64         // Requests answered with a 4xx or 5xx status
+	SlowRequests     int64         // Requests slower than the slow request threshold
+	ResponseBytes    int64         // Response body bytes written, before gzip compression
+	MaxResponseBytes int64         // Largest response body written
+	TotalDuration    time.Duration // Time spent serving all requests
+	MaxDuration      time.Duration // Slowest request
+}
+
+// metricsMtx guards serviceMetrics.
+var metricsMtx sync.Mutex
+
+// serviceMetrics holds the metrics of each web service, keyed by area and name.
+var serviceMetrics = map[string]*ServiceMetrics{}
+
+// resetMetrics clears the metrics of every web service.
+func resetMetrics() {
+	metricsMtx.Lock()
+	defer metricsMtx.Unlock()
+	serviceMetrics = map[string]*ServiceMetrics{}
+}
+
+// Metrics returns a snapshot of the metrics of every web service that has
+// served a request, sorted by service area and name.
+func Metrics() []ServiceMetrics {
+	metricsMtx.Lock()
+	defer metricsMtx.Unlock()
+	result := make([]ServiceMetrics, 0, len(serviceMetrics))
+	for _, metrics := range serviceMetrics {
+		result = append(result, *metrics)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].ServiceArea != result[j].ServiceArea {
+			return result[i].ServiceArea < result[j].ServiceArea
+		}
+		return result[i].ServiceName < result[j].ServiceName
+	})
+	return result
+}
+
+// metricsWriter measures the response of a service request.
+type metricsWriter struct {
+	http.ResponseWriter
+	start  time.Time
+	status int
+	bytes  int64
+}
+
+func newMetricsWriter(w http.ResponseWriter) *metricsWriter {
+	return &metricsWriter{ResponseWriter: w, start: time.Now()}
+}
+
+func (this *metricsWriter) WriteHeader(status int) {
+	if this.status == 0 {
+		this.status = status
+	}
+	this.ResponseWriter.WriteHeader(status)
+}
+
+func (this *metricsWriter) Write(data []byte) (int, error) {
+	if this.status == 0 {
+		this.status = http.StatusOK
+	}
+	n, err := this.ResponseWriter.Write(data)
+	this.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the wrapped writer, e.g. to flush.
+func (this *metricsWriter) Unwrap() http.ResponseWriter {
+	return this.ResponseWriter
+}
+
+// recordMetrics adds the measured request to the service's metrics, and logs
+// it as a warning if it was slower than the slow request threshold.
+func (this *ServiceHandler) recordMetrics(m *metricsWriter, r *http.Request, reqID, aaaid string) {
+	duration := time.Since(m.start)
+	threshold := this.slowAfter
+	if threshold == 0 {
+		threshold = DefaultSlowRequestThreshold
+	}
+	slow := threshold > 0 && duration > threshold
+
+	key := strconv.Itoa(int(this.serviceArea)) + "/" + this.serviceName
+	metricsMtx.Lock()
+	metrics, ok := serviceMetrics[key]
+	if !ok {
+		metrics = &ServiceMetrics{ServiceName: this.serviceName, ServiceArea: this.serviceArea}
+		serviceMetrics[key] = metrics
+	}
+	metrics.Requests++
+	if m.status >= http.StatusBadRequest {
+		metrics.Errors++
+	}
+	if slow {
+		metrics.SlowRequests++
+	}
+	metrics.ResponseBytes += m.bytes
+	if m.bytes > metrics.MaxResponseBytes {
+		metrics.MaxResponseBytes = m.bytes
+	}
+	metrics.TotalDuration += duration
+	if duration > metrics.MaxDuration {
+		metrics.MaxDuration = duration
+	}
+	metricsMtx.Unlock()
+
+	if slow && this.vnic != nil {
+		this.vnic.Resources().Logger().Warning("[", reqID, "] Slow request ", r.Method, " ", r.URL.Path, " to ", this.serviceName,
+			" area ", this.serviceArea, " user ", aaaid, " took ", duration, " status ", m.status, " bytes ", m.bytes)
+	}
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saichler/l8types/go/ifs"
+)
+
+// warningLogger records the warnings it is given.
+type warningLogger struct {
+	quietLogger
+	warnings []string
+}
+
+func (this *warningLogger) Warning(args ...interface{}) {
+	this.warnings = append(this.warnings, fmt.Sprint(args...))
+}
+
+type warningResources struct {
+	quietResources
+	logger *warningLogger
+}
+
+func (this *warningResources) Logger() ifs.ILogger { return this.logger }
+
+// warningVnic is an echoVnic logging to a warningLogger.
+type warningVnic struct {
+	echoVnic
+	resources *warningResources
+}
+
+func (this *warningVnic) Resources() ifs.IResources { return this.resources }
+
+func metricsOf(serviceName string) ServiceMetrics {
+	for _, metrics := range Metrics() {
+		if metrics.ServiceName == serviceName {
+			return metrics
+		}
+	}
+	return ServiceMetrics{}
+}
+
+func TestMetrics_Record(t *testing.T) {
+	resetMetrics()
+	handler := &ServiceHandler{serviceName: "Metered", serviceArea: 4, webService: &echoService{}, vnic: &echoVnic{}}
+	for _, body := range []string{`{"text":"select * from a"}`, `{"text":"fail"}`} {
+		r := httptest.NewRequest(http.MethodPost, "/4/Metered", strings.NewReader(body))
+		handler.serveHttp(httptest.NewRecorder(), r)
+	}
+
+	metrics := metricsOf("Metered")
+	if metrics.ServiceArea != 4 || metrics.Requests != 2 || metrics.Errors != 1 || metrics.SlowRequests != 0 {
+		t.Fatalf("unexpected counters %+v", metrics)
+	}
+	if metrics.ResponseBytes == 0 || metrics.MaxResponseBytes == 0 || metrics.MaxResponseBytes > metrics.ResponseBytes {
+		t.Fatalf("unexpected response sizes %+v", metrics)
+	}
+	if metrics.TotalDuration <= 0 || metrics.MaxDuration > metrics.TotalDuration {
+		t.Fatalf("unexpected durations %+v", metrics)
+	}
+}
+
+func TestMetrics_SlowRequest(t *testing.T) {
+	resetMetrics()
+	logger := &warningLogger{}
+	vnic := &warningVnic{resources: &warningResources{logger: logger}}
+	handler := &ServiceHandler{serviceName: "Sluggish", webService: &echoService{}, vnic: vnic}
+	request := func() *http.Request {
+		return httptest.NewRequest(http.MethodPost, "/0/Sluggish", strings.NewReader(`{"text":"select * from a"}`))
+	}
+
+	handler.slowAfter = -1
+	handler.serveHttp(httptest.NewRecorder(), request())
+	if len(logger.warnings) != 0 {
+		t.Fatalf("expected no slow request log when disabled, got %v", logger.warnings)
+	}
+
+	handler.slowAfter = time.Nanosecond
+	handler.serveHttp(httptest.NewRecorder(), request())
+	if len(logger.warnings) != 1 || !strings.Contains(logger.warnings[0], "Slow request POST /0/Sluggish to Sluggish") {
+		t.Fatalf("expected a slow request warning, got %v", logger.warnings)
+	}
+	if metrics := metricsOf("Sluggish"); metrics.Requests != 2 || metrics.SlowRequests != 1 {
+		t.Fatalf("unexpected counters %+v", metrics)
+	}
+}
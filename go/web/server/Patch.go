@@ -0,0 +1,123 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Patch.go handles PATCH request bodies sent as JSON Merge Patch (RFC 7386)
+// or JSON Patch (RFC 6902).
+//
+// Services receive a PATCH as an ifs.PATCH action whose body is a partial
+// element: only the fields being changed are set. Both patch formats are
+// converted to that representation before the body is parsed:
+//   - application/merge-patch+json is already a partial element and is passed
+//     through. Members set to null are left unset, since a Protocol Buffer
+//     field cannot express "remove"; they are not cleared on the backend.
+//   - application/json-patch+json is converted into the equivalent merge patch.
+//     Only "add" and "replace" operations on object member paths are supported;
+//     other operations and array index paths are rejected.
+
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MergePatchContentType is the media type of a JSON Merge Patch (RFC 7386).
+	MergePatchContentType = "application/merge-patch+json"
+	// JSONPatchContentType is the media type of a JSON Patch (RFC 6902).
+	JSONPatchContentType = "application/json-patch+json"
+)
+
+// patchContentType returns the request's patch media type, or "" if the body
+// is not a patch document.
+func patchContentType(r *http.Request) string {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return ""
+	}
+	if mediaType == MergePatchContentType || mediaType == JSONPatchContentType {
+		return mediaType
+	}
+	return ""
+}
+
+// patchToPartial converts a patch document of the given media type into the
+// partial element JSON delivered to the service.
+func patchToPartial(contentType string, data []byte) ([]byte, error) {
+	if contentType == MergePatchContentType {
+		var object map[string]interface{}
+		if err := json.Unmarshal(data, &object); err != nil || object == nil {
+			return nil, errors.New("merge patch must be a JSON object")
+		}
+		return data, nil
+	}
+
+	var operations []struct {
+		Op    string          `json:"op"`
+		Path  string          `json:"path"`
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(data, &operations); err != nil {
+		return nil, errors.New("JSON patch must be an array of operations: " + err.Error())
+	}
+	partial := map[string]interface{}{}
+	for i, operation := range operations {
+		index := strconv.Itoa(i)
+		if operation.Op != "add" && operation.Op != "replace" {
+			return nil, errors.New("JSON patch operation " + index + ": op '" + operation.Op + "' is not supported, only add and replace")
+		}
+		if operation.Value == nil {
+			return nil, errors.New("JSON patch operation " + index + ": missing value")
+		}
+		segments, err := jsonPointer(operation.Path)
+		if err != nil {
+			return nil, errors.New("JSON patch operation " + index + ": " + err.Error())
+		}
+		node := partial
+		for _, segment := range segments[:len(segments)-1] {
+			child, ok := node[segment].(map[string]interface{})
+			if !ok {
+				child = map[string]interface{}{}
+				node[segment] = child
+			}
+			node = child
+		}
+		node[segments[len(segments)-1]] = operation.Value
+	}
+	return json.Marshal(partial)
+}
+
+// jsonPointer splits an RFC 6901 JSON pointer into unescaped member names.
+// The root pointer and array index segments are rejected.
+func jsonPointer(path string) ([]string, error) {
+	if !strings.HasPrefix(path, "/") || path == "/" {
+		return nil, errors.New("path '" + path + "' must point to a member of the element")
+	}
+	segments := strings.Split(path[1:], "/")
+	for i, segment := range segments {
+		if segment == "-" {
+			return nil, errors.New("path '" + path + "': array index paths are not supported")
+		}
+		if _, err := strconv.Atoi(segment); err == nil {
+			return nil, errors.New("path '" + path + "': array index paths are not supported")
+		}
+		segments[i] = strings.ReplaceAll(strings.ReplaceAll(segment, "~1", "/"), "~0", "~")
+	}
+	return segments, nil
+}
@@ -0,0 +1,68 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPatch_ContentType(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPatch, "/0/Users", nil)
+	r.Header.Set("Content-Type", "application/merge-patch+json; charset=utf-8")
+	if patchContentType(r) != MergePatchContentType {
+		t.Fatalf("expected merge patch content type")
+	}
+	r.Header.Set("Content-Type", "application/json")
+	if patchContentType(r) != "" {
+		t.Fatalf("plain JSON is not a patch document")
+	}
+}
+
+func TestPatch_JSONPatchToPartial(t *testing.T) {
+	doc := `[{"op":"replace","path":"/name","value":"bob"},{"op":"add","path":"/address/city","value":"Paris"},{"op":"add","path":"/a~1b","value":1}]`
+	partial, err := patchToPartial(JSONPatchContentType, []byte(doc))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(partial) != `{"a/b":1,"address":{"city":"Paris"},"name":"bob"}` {
+		t.Fatalf("unexpected partial element %s", partial)
+	}
+
+	for _, bad := range []string{
+		`[{"op":"remove","path":"/name"}]`,
+		`[{"op":"add","path":"/tags/0","value":"x"}]`,
+		`[{"op":"replace","path":"/","value":{}}]`,
+		`{"op":"add"}`,
+	} {
+		if _, err := patchToPartial(JSONPatchContentType, []byte(bad)); err == nil {
+			t.Fatalf("expected error for %s", bad)
+		}
+	}
+}
+
+func TestPatch_MergePatch(t *testing.T) {
+	doc := `{"name":"bob","address":{"city":null}}`
+	partial, err := patchToPartial(MergePatchContentType, []byte(doc))
+	if err != nil || string(partial) != doc {
+		t.Fatalf("expected merge patch to pass through, got %s, %v", partial, err)
+	}
+	if _, err := patchToPartial(MergePatchContentType, []byte(`[1]`)); err == nil || !strings.Contains(err.Error(), "object") {
+		t.Fatalf("expected error for non-object merge patch, got %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// PreDispatch.go runs application hooks on a service request after its body
+// is parsed and before it is sent through the VNic, e.g. to reject malformed
+// filters or to scope a query to the caller's tenant. A hook may modify the
+// body; the backend receives it as the hook left it.
+
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// PreDispatchFunc validates or enriches a parsed service request. A non-nil
+// error rejects the request with 400 Bad Request, or with the status of a
+// *DispatchError.
+type PreDispatchFunc func(r *http.Request, body proto.Message) error
+
+// DispatchError is returned by a PreDispatchFunc to reject a request with a
+// status other than 400 Bad Request, e.g. 403 Forbidden for another
+// tenant's data. A Status outside 400-599 falls back to 400.
+type DispatchError struct {
+	Status  int    // HTTP status of the rejection
+	Message string // Reason sent in the error envelope
+}
+
+func (e *DispatchError) Error() string {
+	return e.Message
+}
+
+// ServicePreDispatch runs a PreDispatchFunc for the requests of one web service.
+type ServicePreDispatch struct {
+	ServiceName string          // Name of the web service
+	ServiceArea byte            // Service area of the web service
+	Hook        PreDispatchFunc // Hook run after the server-wide PreDispatch
+}
+
+// applyServicePreDispatch collects the handler's hooks: the server-wide
+// PreDispatch first, then the ServicePreDispatch entries for its service in
+// order.
+func (this *RestServer) applyServicePreDispatch(handler *ServiceHandler) {
+	if this.PreDispatch != nil {
+		handler.preDispatch = append(handler.preDispatch, this.PreDispatch)
+	}
+	for _, entry := range this.ServicePreDispatch {
+		if entry.ServiceName != handler.serviceName || entry.ServiceArea != handler.serviceArea || entry.Hook == nil {
+			continue
+		}
+		handler.preDispatch = append(handler.preDispatch, entry.Hook)
+	}
+}
+
+// runPreDispatch runs the handler's hooks in order until one fails. It returns
+// false after writing the failing hook's error.
+func (this *ServiceHandler) runPreDispatch(w http.ResponseWriter, r *http.Request, reqID string, body proto.Message) bool {
+	for _, hook := range this.preDispatch {
+		err := hook(r, body)
+		if err == nil {
+			continue
+		}
+		status := http.StatusBadRequest
+		var dispatchErr *DispatchError
+		if errors.As(err, &dispatchErr) && dispatchErr.Status >= http.StatusBadRequest && dispatchErr.Status <= 599 {
+			status = dispatchErr.Status
+		}
+		writeError(w, status, err.Error())
+		this.vnic.Resources().Logger().Debug("[", reqID, "] Rejected before dispatch: ", err.Error())
+		return false
+	}
+	return true
+}
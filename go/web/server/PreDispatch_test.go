@@ -0,0 +1,76 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/saichler/l8types/go/types/l8api"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestPreDispatch(t *testing.T) {
+	rs := &RestServer{}
+	rs.PreDispatch = func(r *http.Request, body proto.Message) error {
+		query := body.(*l8api.L8Query)
+		if strings.Contains(query.Text, "drop") {
+			return errors.New("malformed filter")
+		}
+		query.Text += " where tenant=" + r.Header.Get("X-Tenant")
+		return nil
+	}
+	rs.ServicePreDispatch = []ServicePreDispatch{
+		{ServiceName: "Tests", Hook: func(r *http.Request, body proto.Message) error {
+			if r.Header.Get("X-Tenant") == "" {
+				return &DispatchError{Status: http.StatusForbidden, Message: "no tenant"}
+			}
+			return nil
+		}},
+		{ServiceName: "Other", Hook: func(r *http.Request, body proto.Message) error {
+			return errors.New("wrong service")
+		}},
+	}
+	handler := &ServiceHandler{serviceName: "Tests", webService: &echoService{}, vnic: &echoVnic{}}
+	rs.applyServicePreDispatch(handler)
+	if len(handler.preDispatch) != 2 {
+		t.Fatalf("expected 2 hooks, got %d", len(handler.preDispatch))
+	}
+
+	send := func(text, tenant string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/0/Tests", strings.NewReader(`{"text": "`+text+`"}`))
+		if tenant != "" {
+			r.Header.Set("X-Tenant", tenant)
+		}
+		w := httptest.NewRecorder()
+		handler.serveHttp(w, r)
+		return w
+	}
+
+	w := send("select * from a", "acme")
+	query := &l8api.L8Query{}
+	if w.Code != http.StatusOK || protojson.Unmarshal(w.Body.Bytes(), query) != nil || query.Text != "select * from a where tenant=acme" {
+		t.Fatalf("expected the enriched query, got %d %s", w.Code, w.Body.String())
+	}
+	if detail := decodeError(t, send("drop a", "acme"), http.StatusBadRequest); detail.Message != "malformed filter" {
+		t.Fatalf("unexpected message %q", detail.Message)
+	}
+	decodeError(t, send("select * from a", ""), http.StatusForbidden)
+}
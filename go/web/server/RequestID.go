@@ -0,0 +1,79 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// RequestID.go provides request identifiers used to correlate an HTTP request
+// with the log lines of the service handler that processed it and, through a
+// request body implementing RequestIDCarrier, with the backend that served it.
+
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// RequestIDHeader is the header a client may use to supply a request id. The
+// id, supplied or generated, is echoed back in the same response header.
+var RequestIDHeader = "X-Request-ID"
+
+// RequestIDCarrier is implemented by a service request body that carries the
+// request id to the backend. The VNic request carries only the body and the
+// AAA id, so a generated Protocol Buffer type gets the id by declaring the
+// method in a file of its own package, typically over a string field:
+//
+//	func (this *Order) SetRequestID(id string) {
+//	    this.RequestId = id
+//	}
+type RequestIDCarrier interface {
+	// SetRequestID is called with the request id before the body is sent.
+	SetRequestID(id string)
+}
+
+// maxRequestIDLength bounds client supplied request ids.
+const maxRequestIDLength = 128
+
+// requestID returns the client supplied request id, or a new random id if the
+// header is missing or not a safe token (letters, digits, '-', '_', '.', ':').
+// Rejecting other characters keeps client input from forging log lines.
+func requestID(r *http.Request) string {
+	id := r.Header.Get(RequestIDHeader)
+	if id != "" && len(id) <= maxRequestIDLength && isSafeRequestID(id) {
+		return id
+	}
+	buff := make([]byte, 16)
+	rand.Read(buff)
+	return hex.EncodeToString(buff)
+}
+
+// forwardRequestID hands reqID to a body implementing RequestIDCarrier.
+func forwardRequestID(reqID string, body interface{}) {
+	if carrier, ok := body.(RequestIDCarrier); ok {
+		carrier.SetRequestID(reqID)
+	}
+}
+
+// isSafeRequestID checks that every character of id is allowed in a request id.
+func isSafeRequestID(id string) bool {
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
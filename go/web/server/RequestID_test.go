@@ -0,0 +1,41 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestID(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/0/Users", nil)
+	r.Header.Set(RequestIDHeader, "edge-7f3a:42")
+	if id := requestID(r); id != "edge-7f3a:42" {
+		t.Fatalf("expected supplied request id, got %q", id)
+	}
+
+	r.Header.Set(RequestIDHeader, "bad id\n[forged] log line")
+	if id := requestID(r); len(id) != 32 {
+		t.Fatalf("expected generated id for unsafe header, got %q", id)
+	}
+
+	r.Header.Del(RequestIDHeader)
+	first, second := requestID(r), requestID(r)
+	if len(first) != 32 || first == second {
+		t.Fatalf("expected unique generated ids, got %q and %q", first, second)
+	}
+}
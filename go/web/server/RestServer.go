@@ -23,10 +23,16 @@ package server
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"fmt"
+	"net"
 	"net/http"
+	"regexp"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/saichler/l8types/go/ifs"
@@ -34,14 +40,50 @@ import (
 )
 
 // endPoints tracks registered endpoint paths to prevent duplicate registrations.
+// Each path maps to its *RouteInfo.
 var endPoints = maps.NewSyncMap()
 
+// RouteInfo describes a path registered on the server through RegisterWebService,
+// Handle or RegisterHandler. It is listed as JSON by the /services endpoint,
+// when EnableServices is set.
+type RouteInfo struct {
+	Path        string `json:"path"`                  // Full URL path, including the server prefix
+	ServiceName string `json:"serviceName,omitempty"` // Layer 8 service name, empty for custom handlers
+	ServiceArea byte   `json:"serviceArea"`           // Layer 8 service area, 0 for custom handlers
+	Auth        bool   `json:"auth"`                  // Whether a bearer token is required
+	AliasOf     string `json:"aliasOf,omitempty"`     // Canonical {Prefix}{area}/{name} path, set for ServiceAliases paths
+
+	// AuthMethods lists the HTTP methods whose token requirement differs from Auth.
+	AuthMethods map[string]bool `json:"authMethods,omitempty"`
+}
+
+// ServiceAuth overrides the server-wide Authentication setting for a web
+// service, or for some of its HTTP methods, e.g. to serve public GETs from a
+// service whose writes require a bearer token.
+type ServiceAuth struct {
+	ServiceName string   // Name of the web service
+	ServiceArea byte     // Service area of the web service
+	Methods     []string // HTTP methods it applies to (e.g., "GET"), empty for every method
+	Required    bool     // Whether a bearer token is required
+}
+
+// ServiceAlias makes a web service reachable at an extra path that omits the
+// service area segment, e.g. "/api/v1/users" next to "/api/v1/0/Users".
+type ServiceAlias struct {
+	Path        string // Alias path relative to Prefix (e.g., "users")
+	ServiceName string // Name of the aliased web service
+	ServiceArea byte   // Service area of the aliased web service
+}
+
 // RestServer implements the ifs.IWebServer interface and provides HTTPS
 // server functionality with Layer 8 integration. It manages web service registration,
 // TLS configuration, and request routing.
 type RestServer struct {
-	webServer        *http.Server // The underlying Go HTTP server
-	RestServerConfig              // Embedded configuration
+	webServer        *http.Server               // The underlying Go HTTP server
+	webServerMtx     sync.Mutex                 // Guards webServer, set by Start/Serve and read by Stop, stopped and limiters
+	stopped          bool                       // Set by Stop, ends the web directory retries
+	limiters         map[string]*serviceLimiter // ServiceLimits limiters by "area/name", shared by a service's handlers
+	RestServerConfig                            // Embedded configuration
 }
 
 // RestServerConfig contains the configuration options for creating a REST server.
@@ -50,8 +92,174 @@ type RestServerConfig struct {
 	Port           int    // Port number to listen on
 	Authentication bool   // Enable bearer token authentication for endpoints
 	Prefix         string // URL prefix for all registered endpoints (e.g., "/api/v1/")
-	CertDomain     string // TLS certificate PEM (required)
-	CertPrivate    string // TLS private key PEM (required)
+	CertDomain     string // TLS certificate PEM
+	CertPrivate    string // TLS private key PEM
+	CertFile       string // Path to a PEM certificate file (e.g., a mounted tls.crt), reloaded when it changes
+	KeyFile        string // Path to the PEM private key file for CertFile (e.g., tls.key)
+	EnableRegistry bool   // Expose the /registry type list endpoint (default: off); it always requires a bearer token
+	EnableServices bool   // Expose the /services route list endpoint (default: off); it always requires a bearer token
+	EnableETags    bool   // Send ETag/Last-Modified on service GET responses and answer conditional GETs with 304
+	EnableGzip     bool   // Gzip service and built-in endpoint responses of GzipMinSize bytes or more for clients that accept it
+
+	// The built-in self-service endpoints are exposed by default. Deployments
+	// that don't use them should disable them to reduce the attack surface:
+	// each one is reachable without a bearer token.
+	DisableRegistration bool // Return 404 from /register (self-registration)
+	DisableTFA          bool // Return 404 from /tfaSetup, /tfaSetupVerify and /tfaVerify
+	DisableCaptcha      bool // Return 404 from /captcha
+
+	// IndexFiles lists the file names served as a directory's default document,
+	// in order of preference (e.g., "index.html", "index.htm", "app.html").
+	// Defaults to DefaultIndexFile.
+	IndexFiles []string
+
+	// UIBasePath serves the web UI under a path instead of the root (e.g.,
+	// "/app/"), for an app mounted below the domain root. The base path serves
+	// the root index file. Asset URLs in the UI should be relative.
+	UIBasePath string
+
+	// SecurityHeaders overrides DefaultSecurityHeaders on every response, e.g.
+	// to set a Content-Security-Policy. An empty value disables a header.
+	SecurityHeaders map[string]string
+
+	// WebDirRetryInterval, if set, rechecks for a web directory missing at
+	// startup at this interval, e.g. for UI assets mounted asynchronously by a
+	// sidecar, and loads the web UI once it appears. Retries stop when the
+	// server is stopped. Zero (the default) checks only once, at startup.
+	WebDirRetryInterval time.Duration
+
+	// HostWebDirs serves a distinct web UI per domain: requests whose Host
+	// (e.g., "probler.dev", matched without port and case-insensitively) has
+	// an entry are served from that web directory instead of the default one.
+	// UIBasePath, IndexFiles and NotFoundHandler apply to every directory.
+	HostWebDirs map[string]string
+
+	// FingerprintPattern matches web UI file names that embed a content hash,
+	// which are served with "Cache-Control: public, max-age=31536000,
+	// immutable" instead of no-store. HTML and service worker files are never
+	// cached this way. Defaults to DefaultFingerprintPattern, which also
+	// matches names like user-facade.js; set a stricter pattern if the UI
+	// has such files, or one that never matches to disable long-term caching.
+	FingerprintPattern *regexp.Regexp
+
+	// NotFoundHandler answers requests for paths with no web UI file, e.g. with
+	// a branded 404 page. Paths under Prefix are left to the API. Defaults to a
+	// plain text 404.
+	NotFoundHandler http.HandlerFunc
+
+	// AllowedOrigins lists the origins (e.g., "https://app.example.com") allowed
+	// to call the built-in endpoints cross-origin with credentials, or "*" for
+	// any origin without credentials.
+	AllowedOrigins []string
+
+	// ServiceAliases adds paths, relative to Prefix, that reach a web service
+	// without its area segment. They are registered along with the service's
+	// canonical {Prefix}{area}/{name} path and served by the same handler.
+	ServiceAliases []ServiceAlias
+
+	// ServiceAuth overrides Authentication per web service and HTTP method.
+	// Later entries take precedence over earlier ones for the same method.
+	ServiceAuth []ServiceAuth
+
+	// ServiceLimits caps the requests a web service handles at once, queueing
+	// a bounded number of the others for a short while and answering the rest
+	// with 503 Service Unavailable, to protect backends with limited
+	// concurrency. Later entries replace earlier ones for the same service.
+	ServiceLimits []ServiceLimit
+
+	// ServiceScopes requires scopes per web service and HTTP method, checked
+	// against a security provider implementing ScopeProvider. Methods with a
+	// scope always require a bearer token; users without one of the scopes get
+	// 403 Forbidden.
+	ServiceScopes []ServiceScope
+
+	// AuditHook, if set, is called with an AuditRecord after every POST, PUT,
+	// PATCH and DELETE service request, including rejected ones. It runs on
+	// its own goroutine and never delays or fails the request.
+	AuditHook func(AuditRecord)
+	// AuditRedactFields lists body field names (case-insensitive, at any
+	// depth) whose values are replaced with AuditRedacted in AuditRecord.Body,
+	// e.g. "password" or "token".
+	AuditRedactFields []string
+
+	// RequiredServices lists the web service names that must be discovered
+	// before /readyz reports ready. If empty, any discovered web service will do.
+	RequiredServices []string
+
+	// BaseContext ties the server's lifecycle to a parent context: when it is
+	// cancelled, the server is stopped as if Stop were called, and Start or
+	// Serve returns http.ErrServerClosed. Its values are visible to every
+	// request's context, but not its cancellation, so in-flight requests can
+	// complete during the shutdown.
+	BaseContext context.Context
+
+	// BearerCookieName names the HTTP-only cookie /auth stores the bearer token
+	// in and requests are authenticated with, e.g. to namespace the cookies of
+	// two servers in one process. Defaults to DefaultBearerCookieName.
+	BearerCookieName string
+
+	// AllowQueryToken accepts a bearer token in the "token" query parameter
+	// when there is no cookie or Authorization header, for redirect flows that
+	// need it. Off by default: a token in a URL leaks into access logs, the
+	// browser history and Referer headers.
+	AllowQueryToken bool
+
+	// HandlerTimeout bounds the time any handler, service or static file, may
+	// take: past it the request is answered with 503 and its connection freed.
+	// It is a safety net above the VNic request Timeout, and should be longer
+	// than MaxTimeout. Responses are buffered until the handler returns, so
+	// large downloads need a timeout long enough to produce them. Zero (the
+	// default) disables it.
+	HandlerTimeout time.Duration
+
+	// MaxHeaderBytes caps the size of a request's headers, request line
+	// included; larger ones are answered with 431 Request Header Fields Too
+	// Large. Zero means http.DefaultMaxHeaderBytes (1 MB). Raise it behind
+	// proxies that add many forwarding headers, lower it to harden the server.
+	MaxHeaderBytes int
+	// MaxHeaderCount caps the number of request header lines, counting each
+	// value of a repeated header; requests with more are answered with 431.
+	// Zero (the default) means no limit.
+	MaxHeaderCount int
+
+	// SlowRequestThreshold logs a service request taking longer as a warning,
+	// with its service and user (default: DefaultSlowRequestThreshold). A
+	// negative value disables slow request logging. See also Metrics.
+	SlowRequestThreshold time.Duration
+
+	// EnableCSRF requires state-changing requests authenticated by the bearer
+	// cookie to echo the CSRFCookieName cookie, set by /auth, in the
+	// CSRFHeader header. Requests with an Authorization header are exempt.
+	EnableCSRF bool
+
+	// ErrorMapper chooses the HTTP status of a service request whose backend
+	// answered with an error, e.g. 404 for a missing item or 409 for a
+	// conflict, so applications can map their own error taxonomy. A result
+	// outside 400-599 falls back to the default, 400 Bad Request, which is
+	// also used when ErrorMapper is not set.
+	ErrorMapper func(error) int
+
+	// ForwardHeaders lists the request headers passed to the backend in a
+	// request body implementing HeaderCarrier, e.g. "Accept-Language" or
+	// "X-Tenant". An entry ending in "*" matches a prefix, e.g. "X-Feature-*".
+	// Credential headers (Authorization, Proxy-Authorization, Cookie and
+	// CSRFHeader) are only forwarded when listed by their full name.
+	ForwardHeaders []string
+
+	// PreDispatch, if set, runs on every service request after its body is
+	// parsed and before it is sent to the backend, to validate or enrich it.
+	// A non-nil error rejects the request with 400 Bad Request, or with the
+	// status of a *DispatchError.
+	PreDispatch PreDispatchFunc
+	// ServicePreDispatch adds hooks for individual web services, run after
+	// PreDispatch in order.
+	ServicePreDispatch []ServicePreDispatch
+
+	// Certificate is an in-memory certificate to serve.
+	Certificate *tls.Certificate
+	// GetCertificate supplies the certificate per handshake, e.g. from a rotating
+	// source. It takes precedence over every other certificate option.
+	GetCertificate func(*tls.ClientHelloInfo) (*tls.Certificate, error)
 }
 
 // NewRestServerNoIndex creates a REST server in proxy mode, which disables
@@ -64,10 +272,14 @@ func NewRestServerNoIndex(config *RestServerConfig) (ifs.IWebServer, error) {
 
 // NewRestServer creates a new HTTPS REST server with the provided configuration.
 // It initializes the HTTP multiplexer and loads any web UI files.
-// CertDomain and CertPrivate are required — the server only supports HTTPS.
+// A certificate is required — the server only supports HTTPS. It is taken from,
+// in order of precedence: GetCertificate, Certificate, CertFile/KeyFile, or the
+// CertDomain/CertPrivate PEM strings.
 func NewRestServer(config *RestServerConfig) (ifs.IWebServer, error) {
-	if config.CertDomain == "" || config.CertPrivate == "" {
-		return nil, fmt.Errorf("CertDomain and CertPrivate are required: RestServer only supports HTTPS")
+	if config.GetCertificate == nil && config.Certificate == nil &&
+		(config.CertFile == "" || config.KeyFile == "") &&
+		(config.CertDomain == "" || config.CertPrivate == "") {
+		return nil, fmt.Errorf("a certificate is required (GetCertificate, Certificate, CertFile/KeyFile or CertDomain/CertPrivate): RestServer only supports HTTPS")
 	}
 	rs := &RestServer{}
 	rs.Authentication = config.Authentication
@@ -76,18 +288,62 @@ func NewRestServer(config *RestServerConfig) (ifs.IWebServer, error) {
 	rs.Prefix = config.Prefix
 	rs.CertDomain = config.CertDomain
 	rs.CertPrivate = config.CertPrivate
+	rs.CertFile = config.CertFile
+	rs.KeyFile = config.KeyFile
+	rs.Certificate = config.Certificate
+	rs.GetCertificate = config.GetCertificate
+	rs.EnableRegistry = config.EnableRegistry
+	rs.EnableServices = config.EnableServices
+	rs.EnableETags = config.EnableETags
+	rs.EnableGzip = config.EnableGzip
+	rs.RequiredServices = config.RequiredServices
+	rs.ServiceAliases = config.ServiceAliases
+	rs.ServiceAuth = config.ServiceAuth
+	rs.ServiceScopes = config.ServiceScopes
+	rs.ServiceLimits = config.ServiceLimits
+	rs.AuditHook = config.AuditHook
+	rs.AuditRedactFields = config.AuditRedactFields
+	rs.AllowedOrigins = config.AllowedOrigins
+	rs.IndexFiles = config.IndexFiles
+	rs.UIBasePath = config.UIBasePath
+	rs.HostWebDirs = config.HostWebDirs
+	rs.FingerprintPattern = config.FingerprintPattern
+	rs.SecurityHeaders = config.SecurityHeaders
+	rs.NotFoundHandler = config.NotFoundHandler
+	rs.DisableRegistration = config.DisableRegistration
+	rs.DisableTFA = config.DisableTFA
+	rs.DisableCaptcha = config.DisableCaptcha
+	rs.BaseContext = config.BaseContext
+	rs.BearerCookieName = config.BearerCookieName
+	rs.EnableCSRF = config.EnableCSRF
+	rs.AllowQueryToken = config.AllowQueryToken
+	rs.SlowRequestThreshold = config.SlowRequestThreshold
+	rs.HandlerTimeout = config.HandlerTimeout
+	rs.MaxHeaderBytes = config.MaxHeaderBytes
+	rs.MaxHeaderCount = config.MaxHeaderCount
+	rs.ErrorMapper = config.ErrorMapper
+	rs.ForwardHeaders = config.ForwardHeaders
+	rs.PreDispatch = config.PreDispatch
+	rs.ServicePreDispatch = config.ServicePreDispatch
+	rs.WebDirRetryInterval = config.WebDirRetryInterval
 
 	http.DefaultServeMux = http.NewServeMux()
+	http.DefaultServeMux.HandleFunc("/healthz", healthz)
+	http.DefaultServeMux.HandleFunc("/readyz", rs.readyz)
 	rs.LoadWebUI()
+	if _, found := rs.findWebDirectory(); !found && rs.WebDirRetryInterval > 0 {
+		fmt.Println("Web directory not found, retrying every", rs.WebDirRetryInterval)
+		go rs.waitForWebDirectory()
+	}
 	return rs, nil
 }
 
-// patternOf constructs the URL pattern for a service handler.
-// The pattern format is: {Prefix}{serviceArea}/{serviceName}
+// patternOf constructs the URL pattern for a service handler under prefix.
+// The pattern format is: {prefix}{serviceArea}/{serviceName}
 // For example: "/api/v1/100/UserService"
-func (this *RestServer) patternOf(handler *ServiceHandler) string {
+func (this *RestServer) patternOf(prefix string, handler *ServiceHandler) string {
 	buff := bytes.Buffer{}
-	buff.WriteString(this.Prefix)
+	buff.WriteString(prefix)
 	buff.WriteString(strconv.Itoa(int(handler.serviceArea)))
 	buff.WriteString("/")
 	buff.WriteString(handler.serviceName)
@@ -97,58 +353,224 @@ func (this *RestServer) patternOf(handler *ServiceHandler) string {
 
 // RegisterWebService registers a web service with the server, creating an HTTP handler
 // that routes requests through the Layer 8 VNic. Each service is assigned a unique
-// URL pattern based on its service area and name. Duplicate registrations are ignored.
+// URL pattern based on its service area and name, plus any ServiceAliases paths
+// configured for it, and each path gets a BatchSuffix batch endpoint and a
+// SchemaSuffix schema endpoint. Duplicate registrations are ignored. Use
+// ReplaceWebServices to swap the whole set of registered services at once.
 func (this *RestServer) RegisterWebService(ws ifs.IWebService, vnic ifs.IVNic) {
+	this.RegisterWebServiceAt(this.Prefix, ws, vnic)
+}
+
+// RegisterWebServiceAt is like RegisterWebService but mounts the service, and
+// its ServiceAliases paths, under prefix instead of the server's Prefix, e.g.
+// to serve an API version at "/api/v2/" next to "/api/v1/". The same service
+// may be registered under several prefixes.
+func (this *RestServer) RegisterWebServiceAt(prefix string, ws ifs.IWebService, vnic ifs.IVNic) {
 	authEnabled = this.Authentication
+	routeUpdateMtx.Lock()
+	defer routeUpdateMtx.Unlock()
+
+	table := currentRouteTable()
+	for _, route := range this.webServiceRoutes(prefix, ws, vnic) {
+		_, ok := endPoints.Get(route.info.Path)
+		if ok {
+			continue
+		}
+		endPoints.Put(route.info.Path, route.info)
+		if route.info.AliasOf != "" {
+			fmt.Println("Registering alias path=", route.info.Path, " for ", route.info.AliasOf)
+		} else {
+			fmt.Println("Registering path=", route.info.Path)
+		}
+		addRoute(table, route)
+		mountRoute(route.info.Path)
+	}
+	swapRouteTable(table)
+}
+
+// webServiceRoutes creates the handler for a web service and returns its
+// canonical route under prefix followed by its ServiceAliases routes.
+func (this *RestServer) webServiceRoutes(prefix string, ws ifs.IWebService, vnic ifs.IVNic) []*serviceRoute {
 	handler := &ServiceHandler{authEnabled: this.Authentication}
 	handler.serviceName = ws.ServiceName()
 	handler.serviceArea = ws.ServiceArea()
 	handler.vnic = vnic
 	handler.webService = ws
+	handler.errorMapper = this.ErrorMapper
+	handler.forwarder = newHeaderForwarder(this.ForwardHeaders)
+	handler.slowAfter = this.SlowRequestThreshold
+	if this.EnableETags {
+		handler.etags = newETagCache()
+	}
+	if this.AuditHook != nil {
+		handler.audit = newAuditor(this.AuditHook, this.AuditRedactFields)
+	}
+	this.applyServiceAuth(handler)
+	this.applyServiceScopes(handler)
+	this.applyServicePreDispatch(handler)
+	this.applyServiceLimits(handler)
+
+	path := this.patternOf(prefix, handler)
+	serve := withGzip(this.EnableGzip, handler.serveHttp)
+	batch := withGzip(this.EnableGzip, handler.serveBatch)
+	schema := withGzip(this.EnableGzip, handler.serveSchema)
+	routes := []*serviceRoute{{
+		info: &RouteInfo{
+			Path:        path,
+			ServiceName: handler.serviceName,
+			ServiceArea: handler.serviceArea,
+			Auth:        handler.authEnabled,
+			AuthMethods: handler.authMethods,
+		},
+		serve:  serve,
+		batch:  batch,
+		schema: schema,
+	}}
+
+	for _, alias := range this.ServiceAliases {
+		if alias.ServiceName != handler.serviceName || alias.ServiceArea != handler.serviceArea {
+			continue
+		}
+		routes = append(routes, &serviceRoute{
+			info: &RouteInfo{
+				Path:        prefix + alias.Path,
+				ServiceName: handler.serviceName,
+				ServiceArea: handler.serviceArea,
+				Auth:        handler.authEnabled,
+				AliasOf:     path,
+				AuthMethods: handler.authMethods,
+			},
+			serve:  serve,
+			batch:  batch,
+			schema: schema,
+		})
+	}
+	return routes
+}
 
-	path := this.patternOf(handler)
-	_, ok := endPoints.Get(path)
-	if !ok {
-		endPoints.Put(path, true)
-		fmt.Println("Registering path=", path)
-		http.DefaultServeMux.HandleFunc(this.patternOf(handler), handler.serveHttp)
+// applyServiceAuth applies the ServiceAuth entries for the handler's service:
+// an entry without methods sets the handler's default, and an entry with
+// methods sets those methods only.
+func (this *RestServer) applyServiceAuth(handler *ServiceHandler) {
+	for _, auth := range this.ServiceAuth {
+		if auth.ServiceName != handler.serviceName || auth.ServiceArea != handler.serviceArea {
+			continue
+		}
+		if len(auth.Methods) == 0 {
+			handler.authEnabled = auth.Required
+			handler.authMethods = nil
+			continue
+		}
+		if handler.authMethods == nil {
+			handler.authMethods = map[string]bool{}
+		}
+		for _, method := range auth.Methods {
+			handler.authMethods[strings.ToUpper(method)] = auth.Required
+		}
+	}
+	for method, required := range handler.authMethods {
+		if required == handler.authEnabled {
+			delete(handler.authMethods, method)
+		}
 	}
 }
 
 // Start begins listening for HTTPS requests. This method blocks until
-// the server is stopped.
+// the server is stopped, by Stop or by cancelling BaseContext. There is no
+// plain HTTP fallback: a certificate that cannot be loaded, e.g. a CertFile
+// that is not mounted yet, is returned as an error rather than a silent
+// downgrade.
 func (this *RestServer) Start() error {
-	this.webServer = &http.Server{
-		Addr:    this.Host + ":" + strconv.Itoa(this.Port),
-		Handler: http.DefaultServeMux,
-	}
+	return this.serve(nil)
+}
 
-	cert, err := tls.X509KeyPair([]byte(this.CertDomain), []byte(this.CertPrivate))
+// Serve is like Start but accepts HTTPS connections on an existing listener
+// instead of listening on Host and Port, e.g. one bound to an ephemeral port.
+func (this *RestServer) Serve(listener net.Listener) error {
+	return this.serve(listener)
+}
+
+// serve configures the HTTP server and serves HTTPS on listener, or on
+// Host:Port when listener is nil.
+func (this *RestServer) serve(listener net.Listener) error {
+	tlsConfig, err := this.tlsConfig()
 	if err != nil {
-		panic(fmt.Sprintf("failed to parse TLS certificate: %v", err))
+		return fmt.Errorf("failed to load TLS certificate: %w", err)
 	}
-	this.webServer.TLSConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
-	return this.webServer.ListenAndServeTLS("", "")
+	webServer := &http.Server{
+		Addr:           this.Host + ":" + strconv.Itoa(this.Port),
+		Handler:        this.withSecurityHeaders(this.withHeaderLimit(this.withHandlerTimeout(http.DefaultServeMux))),
+		MaxHeaderBytes: this.MaxHeaderBytes,
+	}
+	this.webServerMtx.Lock()
+	this.webServer = webServer
+	this.webServerMtx.Unlock()
+
+	if this.BaseContext != nil {
+		baseContext := context.WithoutCancel(this.BaseContext)
+		webServer.BaseContext = func(net.Listener) context.Context {
+			return baseContext
+		}
+		// Serve returns as soon as Stop shuts the server down, so wait for Stop
+		// to finish cleaning up before returning to the caller.
+		stopped := make(chan struct{})
+		stop := context.AfterFunc(this.BaseContext, func() {
+			defer close(stopped)
+			this.Stop()
+		})
+		defer func() {
+			if !stop() {
+				<-stopped
+			}
+		}()
+	}
+
+	webServer.TLSConfig = tlsConfig
+	if listener != nil {
+		return webServer.ServeTLS(listener, "", "")
+	}
+	return webServer.ListenAndServeTLS("", "")
 }
 
 // RegisterHandler registers a custom HTTP handler at the given path,
 // prefixed with the server's URL prefix. Use this for webhook endpoints
 // and other custom handlers that don't follow the service area/name pattern.
+// It is the same as Handle.
 func (this *RestServer) RegisterHandler(path string, handler http.Handler) {
-	fullPath := this.Prefix + path
-	_, ok := endPoints.Get(fullPath)
-	if !ok {
-		endPoints.Put(fullPath, true)
-		fmt.Println("Registering path=", fullPath)
-		http.DefaultServeMux.Handle(fullPath, handler)
-	}
+	this.Handle(path, handler)
+}
+
+// Routes returns the paths registered through RegisterWebService and
+// RegisterHandler, sorted by path.
+func Routes() []*RouteInfo {
+	routes := make([]*RouteInfo, 0)
+	endPoints.Iterate(func(k, v interface{}) {
+		route, ok := v.(*RouteInfo)
+		if ok {
+			routes = append(routes, route)
+		}
+	})
+	sort.Slice(routes, func(i, j int) bool {
+		return routes[i].Path < routes[j].Path
+	})
+	return routes
 }
 
 // Stop gracefully shuts down the server and cleans up registered endpoints.
 // It uses the RestServer itself as the context for shutdown coordination.
 func (this *RestServer) Stop() {
-	this.webServer.Shutdown(this)
+	this.webServerMtx.Lock()
+	webServer := this.webServer
+	this.stopped = true
+	this.webServerMtx.Unlock()
+	if webServer != nil {
+		webServer.Shutdown(this)
+	}
 	endPoints.Clean()
+	swapRouteTable(map[string]http.HandlerFunc{})
+	mtx.Lock()
+	readyVnic = nil
+	mtx.Unlock()
 	fmt.Println("Cleaned!")
 }
 
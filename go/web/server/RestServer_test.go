@@ -0,0 +1,204 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saichler/l8types/go/ifs"
+)
+
+// usersService is an ifs.IWebService named "Users" in service area 3.
+type usersService struct {
+	ifs.IWebService
+}
+
+func (this *usersService) ServiceName() string { return "Users" }
+func (this *usersService) ServiceArea() byte   { return 3 }
+
+func TestRestServer_ServiceAliases(t *testing.T) {
+	defer endPoints.Clean()
+	http.DefaultServeMux = http.NewServeMux()
+	rs := &RestServer{}
+	rs.Prefix = "/api/v1/"
+	rs.ServiceAliases = []ServiceAlias{
+		{Path: "users", ServiceName: "Users", ServiceArea: 3},
+		{Path: "orders", ServiceName: "Orders", ServiceArea: 3},
+	}
+	rs.RegisterWebService(&usersService{}, nil)
+
+	for _, path := range []string{"/api/v1/3/Users", "/api/v1/users"} {
+		r := httptest.NewRequest(http.MethodPost, path, strings.NewReader("<xml/>"))
+		r.Header.Set("Content-Type", "application/xml")
+		if _, pattern := http.DefaultServeMux.Handler(r); pattern != path {
+			t.Fatalf("expected %s to be registered, got pattern %q", path, pattern)
+		}
+		// Only the service handler answers with a 415 envelope.
+		w := httptest.NewRecorder()
+		http.DefaultServeMux.ServeHTTP(w, r)
+		decodeError(t, w, http.StatusUnsupportedMediaType)
+	}
+
+	routes := Routes()
+	if len(routes) != 2 {
+		t.Fatalf("expected the canonical path and one alias, got %d routes", len(routes))
+	}
+	alias := routes[1]
+	if alias.Path != "/api/v1/users" || alias.ServiceName != "Users" || alias.AliasOf != "/api/v1/3/Users" {
+		t.Fatalf("unexpected alias route %+v", alias)
+	}
+	if routes[0].AliasOf != "" {
+		t.Fatalf("expected the canonical route to have no AliasOf, got %q", routes[0].AliasOf)
+	}
+}
+
+func TestRestServer_ServiceAuth(t *testing.T) {
+	defer endPoints.Clean()
+	http.DefaultServeMux = http.NewServeMux()
+	rs := &RestServer{}
+	rs.Prefix = "/api/v1/"
+	rs.Authentication = true
+	rs.ServiceAuth = []ServiceAuth{
+		{ServiceName: "Users", ServiceArea: 3, Methods: []string{"get", "POST"}, Required: false},
+		{ServiceName: "Users", ServiceArea: 3, Methods: []string{"POST"}, Required: true},
+		{ServiceName: "Orders", ServiceArea: 3, Required: false},
+	}
+	rs.RegisterWebService(&usersService{}, nil)
+
+	// An unsupported body gets past authentication with a 415, or not with a 401.
+	for method, status := range map[string]int{
+		http.MethodGet:    http.StatusUnsupportedMediaType,
+		http.MethodPost:   http.StatusUnauthorized,
+		http.MethodDelete: http.StatusUnauthorized,
+	} {
+		r := httptest.NewRequest(method, "/api/v1/3/Users", strings.NewReader("<xml/>"))
+		r.Header.Set("Content-Type", "application/xml")
+		w := httptest.NewRecorder()
+		http.DefaultServeMux.ServeHTTP(w, r)
+		decodeError(t, w, status)
+	}
+
+	routes := Routes()
+	if len(routes) != 1 || !routes[0].Auth || len(routes[0].AuthMethods) != 1 || routes[0].AuthMethods["GET"] {
+		t.Fatalf("unexpected route %+v", routes[0])
+	}
+
+	handler := &ServiceHandler{serviceName: "Orders", serviceArea: 3, authEnabled: true}
+	rs.applyServiceAuth(handler)
+	if handler.authRequired(http.MethodPost) || handler.authMethods != nil {
+		t.Fatal("expected Orders to be public")
+	}
+}
+
+func TestRestServer_RegisterWebServiceAt(t *testing.T) {
+	defer endPoints.Clean()
+	http.DefaultServeMux = http.NewServeMux()
+	rs := &RestServer{}
+	rs.Prefix = "/api/v1/"
+	rs.ServiceAliases = []ServiceAlias{{Path: "users", ServiceName: "Users", ServiceArea: 3}}
+	rs.RegisterWebService(&usersService{}, nil)
+	rs.RegisterWebServiceAt("/api/v2/", &usersService{}, nil)
+
+	for _, path := range []string{"/api/v1/3/Users", "/api/v1/users", "/api/v2/3/Users", "/api/v2/users"} {
+		r := httptest.NewRequest(http.MethodPost, path, strings.NewReader("<xml/>"))
+		r.Header.Set("Content-Type", "application/xml")
+		w := httptest.NewRecorder()
+		http.DefaultServeMux.ServeHTTP(w, r)
+		decodeError(t, w, http.StatusUnsupportedMediaType)
+	}
+
+	routes := Routes()
+	if len(routes) != 4 || routes[2].Path != "/api/v2/3/Users" || routes[3].AliasOf != "/api/v2/3/Users" {
+		t.Fatalf("expected both versions of the service and their aliases, got %d routes", len(routes))
+	}
+}
+
+type baseContextKey struct{}
+
+func TestRestServer_BaseContext(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	writeTestCert(t, certFile, keyFile, "base", time.Now())
+
+	http.DefaultServeMux = http.NewServeMux()
+	values := make(chan interface{}, 1)
+	http.DefaultServeMux.HandleFunc("/value", func(w http.ResponseWriter, r *http.Request) {
+		values <- r.Context().Value(baseContextKey{})
+	})
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), baseContextKey{}, "parent"))
+	defer cancel()
+	rs := &RestServer{RestServerConfig: RestServerConfig{CertFile: certFile, KeyFile: keyFile, BaseContext: ctx}}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	served := make(chan error, 1)
+	go func() { served <- rs.Serve(listener) }()
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	resp, err := client.Get("https://" + listener.Addr().String() + "/value")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if value := <-values; value != "parent" {
+		t.Fatalf("expected the base context value in the request, got %v", value)
+	}
+
+	cancel()
+	select {
+	case err := <-served:
+		if err != http.ErrServerClosed {
+			t.Fatalf("expected http.ErrServerClosed, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected cancelling the base context to stop the server")
+	}
+}
+
+func TestRestServer_TrailingSlash(t *testing.T) {
+	defer endPoints.Clean()
+	http.DefaultServeMux = http.NewServeMux()
+	rs := &RestServer{}
+	rs.Prefix = "/api/v1/"
+	rs.ServiceAliases = []ServiceAlias{{Path: "users", ServiceName: "Users", ServiceArea: 3}}
+	rs.RegisterWebService(&usersService{}, nil)
+
+	for _, path := range []string{"/api/v1/3/Users", "/api/v1/3/Users/", "/api/v1/users", "/api/v1/users/"} {
+		r := httptest.NewRequest(http.MethodPost, path, strings.NewReader("<xml/>"))
+		r.Header.Set("Content-Type", "application/xml")
+		w := httptest.NewRecorder()
+		http.DefaultServeMux.ServeHTTP(w, r)
+		decodeError(t, w, http.StatusUnsupportedMediaType)
+	}
+
+	// Only the trailing slash is normalized, not paths below the service.
+	w := httptest.NewRecorder()
+	http.DefaultServeMux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/3/Users/42", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 below the service path, got %d", w.Code)
+	}
+}
@@ -0,0 +1,167 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// RouteTable.go holds the web service routes in a table that can be replaced
+// as a whole, so the set of served services can be swapped at once.
+//
+// Go's ServeMux can't remove or replace a handler, so each service path is
+// mounted on the mux once, with a handler that looks the path up in the
+// current table. Replacing the table is a single pointer swap: a request sees
+// either the old or the new set of services, never a mix, and requests that
+// already started keep running on the handler they were dispatched to.
+
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/saichler/l8types/go/ifs"
+)
+
+// WebServiceRegistration is a web service and the VNic its requests are sent
+// through, as passed to RegisterWebService, and the prefix it is mounted
+// under, as passed to RegisterWebServiceAt.
+type WebServiceRegistration struct {
+	Service ifs.IWebService
+	VNic    ifs.IVNic
+	Prefix  string // URL prefix to mount the service under (default: the server's Prefix)
+}
+
+// serviceRoute is a path serving a web service, with its batch and schema
+// endpoints.
+type serviceRoute struct {
+	info   *RouteInfo
+	serve  http.HandlerFunc
+	batch  http.HandlerFunc
+	schema http.HandlerFunc
+}
+
+var (
+	// routeTable maps each service path, and its BatchSuffix and SchemaSuffix paths, to its
+	// handler. It is never modified, only replaced. Protected by routeTableMtx.
+	routeTable = map[string]http.HandlerFunc{}
+	// routeTableMtx protects the routeTable pointer.
+	routeTableMtx sync.RWMutex
+	// routeUpdateMtx serializes route table updates and mounting.
+	routeUpdateMtx sync.Mutex
+	// mountedRoutes lists the paths mounted on mountedMux. It is reset when
+	// http.DefaultServeMux is replaced, as NewRestServer does.
+	mountedRoutes = map[string]bool{}
+	mountedMux    *http.ServeMux
+)
+
+// ReplaceWebServices atomically replaces every web service registered through
+// RegisterWebService with services. Paths of services that are not in the new
+// set answer 404 from the moment of the swap; requests already in progress
+// finish on the handlers they started on. Handlers registered through
+// RegisterHandler are not affected.
+func (this *RestServer) ReplaceWebServices(services []WebServiceRegistration) {
+	authEnabled = this.Authentication
+	routeUpdateMtx.Lock()
+	defer routeUpdateMtx.Unlock()
+
+	table := map[string]http.HandlerFunc{}
+	infos := map[string]*RouteInfo{}
+	for _, service := range services {
+		prefix := service.Prefix
+		if prefix == "" {
+			prefix = this.Prefix
+		}
+		for _, route := range this.webServiceRoutes(prefix, service.Service, service.VNic) {
+			if _, ok := infos[route.info.Path]; ok {
+				continue
+			}
+			infos[route.info.Path] = route.info
+			addRoute(table, route)
+			mountRoute(route.info.Path)
+		}
+	}
+	swapRouteTable(table)
+
+	endPoints.Iterate(func(k, v interface{}) {
+		route, ok := v.(*RouteInfo)
+		if ok && route.ServiceName != "" && infos[route.Path] == nil {
+			endPoints.Delete(k)
+		}
+	})
+	for path, info := range infos {
+		endPoints.Put(path, info)
+	}
+	fmt.Println("Replaced web services, now serving", len(infos), "paths")
+}
+
+// addRoute adds route and its batch and schema endpoints to table.
+func addRoute(table map[string]http.HandlerFunc, route *serviceRoute) {
+	table[route.info.Path] = route.serve
+	table[route.info.Path+BatchSuffix] = route.batch
+	table[route.info.Path+SchemaSuffix] = route.schema
+}
+
+// mountRoute mounts path and its batch and schema endpoints on http.DefaultServeMux, unless
+// they already are. The path is also mounted with a trailing slash, which
+// ServeMux would otherwise treat as another path, so a client appending one
+// reaches the same service. Must be called with routeUpdateMtx held.
+func mountRoute(path string) {
+	if mountedMux != http.DefaultServeMux {
+		mountedMux = http.DefaultServeMux
+		mountedRoutes = map[string]bool{}
+	}
+	if mountedRoutes[path] {
+		return
+	}
+	mountedRoutes[path] = true
+	http.DefaultServeMux.HandleFunc(path, dispatchRoute(path))
+	if !strings.HasSuffix(path, "/") {
+		http.DefaultServeMux.HandleFunc(path+"/{$}", dispatchRoute(path))
+	}
+	http.DefaultServeMux.HandleFunc(path+BatchSuffix, dispatchRoute(path+BatchSuffix))
+	http.DefaultServeMux.HandleFunc(path+SchemaSuffix, dispatchRoute(path+SchemaSuffix))
+}
+
+// dispatchRoute returns the mux handler for path, which serves requests with
+// the path's handler in the current route table, or 404 if it has none.
+func dispatchRoute(path string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		routeTableMtx.RLock()
+		handler := routeTable[path]
+		routeTableMtx.RUnlock()
+		if handler == nil {
+			http.NotFound(w, r)
+			return
+		}
+		handler(w, r)
+	}
+}
+
+// currentRouteTable returns a copy of the route table, to modify and swap in.
+func currentRouteTable() map[string]http.HandlerFunc {
+	routeTableMtx.RLock()
+	defer routeTableMtx.RUnlock()
+	table := make(map[string]http.HandlerFunc, len(routeTable))
+	for path, handler := range routeTable {
+		table[path] = handler
+	}
+	return table
+}
+
+// swapRouteTable makes table the current route table.
+func swapRouteTable(table map[string]http.HandlerFunc) {
+	routeTableMtx.Lock()
+	routeTable = table
+	routeTableMtx.Unlock()
+}
@@ -0,0 +1,112 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/saichler/l8types/go/ifs"
+	"google.golang.org/protobuf/proto"
+)
+
+// ordersService is an echoService named "Orders" in service area 3.
+type ordersService struct {
+	echoService
+}
+
+func (this *ordersService) ServiceName() string { return "Orders" }
+func (this *ordersService) ServiceArea() byte   { return 3 }
+
+// gatedUsersService is a "Users" echoService whose requests wait for release.
+type gatedUsersService struct {
+	usersService
+	started chan struct{}
+	release chan struct{}
+}
+
+func (this *gatedUsersService) Protos(data string, action ifs.Action) (proto.Message, proto.Message, error) {
+	this.started <- struct{}{}
+	<-this.release
+	return (&echoService{}).Protos(data, action)
+}
+
+func routeStatus(method, path string) int {
+	w := httptest.NewRecorder()
+	http.DefaultServeMux.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(`{"text":"x"}`)))
+	return w.Code
+}
+
+func TestRouteTable_ReplaceWebServices(t *testing.T) {
+	defer func() {
+		endPoints.Clean()
+		swapRouteTable(map[string]http.HandlerFunc{})
+	}()
+	http.DefaultServeMux = http.NewServeMux()
+	rs := &RestServer{}
+	rs.Prefix = "/api/v1/"
+	rs.ServiceAliases = []ServiceAlias{{Path: "users", ServiceName: "Users", ServiceArea: 3}}
+	users := &gatedUsersService{started: make(chan struct{}), release: make(chan struct{})}
+	rs.RegisterWebService(users, &echoVnic{})
+	rs.RegisterHandler("hooks", http.NotFoundHandler())
+
+	// A request in progress finishes on the handler it started on.
+	inFlight := make(chan int)
+	go func() { inFlight <- routeStatus(http.MethodPost, "/api/v1/users") }()
+	select {
+	case <-users.started:
+	case status := <-inFlight:
+		t.Fatalf("expected the request to reach the users service, got %d", status)
+	}
+
+	rs.ReplaceWebServices([]WebServiceRegistration{{Service: &ordersService{}, VNic: &echoVnic{}}})
+	close(users.release)
+	if status := <-inFlight; status != http.StatusOK {
+		t.Fatalf("expected the in-flight request to complete, got %d", status)
+	}
+
+	for path, status := range map[string]int{
+		"/api/v1/3/Users":        http.StatusNotFound,
+		"/api/v1/users":          http.StatusNotFound,
+		"/api/v1/3/Users:batch":  http.StatusNotFound,
+		"/api/v1/3/Orders":       http.StatusOK,
+		"/api/v1/3/Orders:batch": http.StatusBadRequest,
+	} {
+		if got := routeStatus(http.MethodPost, path); got != status {
+			t.Fatalf("%s: expected %d, got %d", path, status, got)
+		}
+	}
+
+	paths := []string{}
+	for _, route := range Routes() {
+		paths = append(paths, route.Path)
+	}
+	if len(paths) != 2 || paths[0] != "/api/v1/3/Orders" || paths[1] != "/api/v1/hooks" {
+		t.Fatalf("expected the Orders service and the custom handler, got %v", paths)
+	}
+
+	// Swapping back mounts nothing twice and serves the new handlers.
+	rs.ReplaceWebServices([]WebServiceRegistration{{Service: users, VNic: &echoVnic{}}})
+	if status := routeStatus(http.MethodPost, "/api/v1/3/Orders"); status != http.StatusNotFound {
+		t.Fatalf("expected Orders to be gone, got %d", status)
+	}
+	go func() { <-users.started }()
+	if status := routeStatus(http.MethodPost, "/api/v1/users"); status != http.StatusOK {
+		t.Fatalf("expected the users alias to be served again, got %d", status)
+	}
+}
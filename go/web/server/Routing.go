@@ -0,0 +1,46 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Routing.go lets a client choose, per request, how the VNic routes the
+// request to a service instance, overriding the package-wide Method.
+
+package server
+
+import (
+	"net/http"
+	"strings"
+)
+
+// RoutingHeader is the header a client may use to choose the routing method
+// of a single request: "leader", "local" or "proximity" (case-insensitive).
+var RoutingHeader = "X-L8-Routing"
+
+// Routing hint values accepted in RoutingHeader.
+const (
+	RoutingLeader    = "leader"    // The service's leader instance (ifs.M_Leader)
+	RoutingLocal     = "local"     // The instance on the local VNet (ifs.M_Local)
+	RoutingProximity = "proximity" // The nearest instance (ifs.M_Proximity)
+)
+
+// routingHint returns the lower case RoutingHeader value of r, "" if it has
+// none, and false if the value is not a known routing hint.
+func routingHint(r *http.Request) (string, bool) {
+	hint := strings.ToLower(strings.TrimSpace(r.Header.Get(RoutingHeader)))
+	switch hint {
+	case "", RoutingLeader, RoutingLocal, RoutingProximity:
+		return hint, true
+	}
+	return hint, false
+}
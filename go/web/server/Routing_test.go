@@ -0,0 +1,76 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/saichler/l8types/go/ifs"
+)
+
+// routingVnic is an echoVnic that records which request method was used.
+type routingVnic struct {
+	echoVnic
+	used string
+}
+
+func (this *routingVnic) LeaderRequest(serviceName string, serviceArea byte, action ifs.Action, body interface{}, timeout int, tokens ...string) ifs.IElements {
+	this.used = RoutingLeader
+	return this.echoVnic.LeaderRequest(serviceName, serviceArea, action, body, timeout, tokens...)
+}
+
+func (this *routingVnic) LocalRequest(serviceName string, serviceArea byte, action ifs.Action, body interface{}, timeout int, tokens ...string) ifs.IElements {
+	this.used = RoutingLocal
+	return this.echoVnic.LeaderRequest(serviceName, serviceArea, action, body, timeout, tokens...)
+}
+
+func (this *routingVnic) ProximityRequest(serviceName string, serviceArea byte, action ifs.Action, body interface{}, timeout int, tokens ...string) ifs.IElements {
+	this.used = RoutingProximity
+	return this.echoVnic.LeaderRequest(serviceName, serviceArea, action, body, timeout, tokens...)
+}
+
+func TestRouting_Header(t *testing.T) {
+	vnic := &routingVnic{}
+	handler := &ServiceHandler{serviceName: "Tests", webService: &echoService{}, vnic: vnic}
+	for hint, used := range map[string]string{
+		"":           RoutingLeader,
+		"local":      RoutingLocal,
+		" Proximity": RoutingProximity,
+		"LEADER":     RoutingLeader,
+	} {
+		vnic.used = ""
+		r := httptest.NewRequest(http.MethodGet, "/0/Tests", strings.NewReader(`{"text":"a"}`))
+		r.Header.Set(RoutingHeader, hint)
+		w := httptest.NewRecorder()
+		handler.serveHttp(w, r)
+		if w.Code != http.StatusOK || vnic.used != used {
+			t.Fatalf("%q: expected %s routing, got %d %q", hint, used, w.Code, vnic.used)
+		}
+	}
+
+	vnic.used = ""
+	r := httptest.NewRequest(http.MethodGet, "/0/Tests", strings.NewReader(`{"text":"a"}`))
+	r.Header.Set(RoutingHeader, "nearest")
+	w := httptest.NewRecorder()
+	handler.serveHttp(w, r)
+	decodeError(t, w, http.StatusBadRequest)
+	if vnic.used != "" {
+		t.Fatal("expected no request for an unknown routing hint")
+	}
+}
@@ -0,0 +1,177 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Schema.go serves the ":schema" sub-path of every web service. A GET to
+// {service path}:schema describes the request and response types of each
+// HTTP method as JSON Schema, derived from the Protocol Buffer descriptors
+// the service's Protos returns, e.g. to generate forms or API documentation.
+//
+// Message types are listed once under "$defs", keyed by their full name, and
+// referenced with "$ref", so recursive types are described too. The schema
+// follows the server's JSON encoding: field JSON names, enums as numbers and
+// 64-bit integers as strings, which protojson accepts in either form.
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// SchemaSuffix is appended to a service path to reach its schema endpoint,
+// e.g. "/api/v1/0/Users:schema".
+const SchemaSuffix = ":schema"
+
+// ServiceSchema describes the types a web service exchanges.
+type ServiceSchema struct {
+	ServiceName string                   `json:"serviceName"`
+	ServiceArea byte                     `json:"serviceArea"`
+	Methods     map[string]*MethodSchema `json:"methods"` // Per HTTP method, for the methods the service accepts
+	Schema      map[string]interface{}   `json:"schema"`  // JSON Schema document with the message types in "$defs"
+}
+
+// MethodSchema names the request and response message types of a method.
+// The names are keys of the ServiceSchema's "$defs".
+type MethodSchema struct {
+	Request  string `json:"request,omitempty"`
+	Response string `json:"response,omitempty"`
+}
+
+// schemaMethods are the HTTP methods described by the schema endpoint.
+var schemaMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// serveSchema handles a GET to the service's schema endpoint. It requires the
+// same authentication and scopes as a GET to the service path, and returns
+// HTTP 405 for other methods.
+func (this *ServiceHandler) serveSchema(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+	if _, ok := this.authorize(w, r, http.MethodGet); !ok {
+		return
+	}
+	byt, err := json.Marshal(this.schema())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to marshal schema: "+err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(byt)
+}
+
+// schema builds the ServiceSchema from the types Protos returns for an empty
+// body of each method. Methods Protos fails for are left out.
+func (this *ServiceHandler) schema() *ServiceSchema {
+	defs := map[string]interface{}{}
+	result := &ServiceSchema{
+		ServiceName: this.serviceName,
+		ServiceArea: this.serviceArea,
+		Methods:     map[string]*MethodSchema{},
+		Schema: map[string]interface{}{
+			"$schema": "https://json-schema.org/draft/2020-12/schema",
+			"$defs":   defs,
+		},
+	}
+	for _, method := range schemaMethods {
+		body, resp, err := this.webService.Protos("{}", methodToAction(method, nil))
+		if err != nil {
+			continue
+		}
+		result.Methods[method] = &MethodSchema{
+			Request:  defineMessage(body, defs),
+			Response: defineMessage(resp, defs),
+		}
+	}
+	return result
+}
+
+// defineMessage adds the schema of msg's type, and the types it uses, to defs
+// and returns its full name, or "" for a nil msg.
+func defineMessage(msg proto.Message, defs map[string]interface{}) string {
+	if msg == nil {
+		return ""
+	}
+	descriptor := msg.ProtoReflect().Descriptor()
+	messageSchema(descriptor, defs)
+	return string(descriptor.FullName())
+}
+
+// messageSchema adds the object schema of a message type to defs, unless it is
+// already there, and returns a reference to it.
+func messageSchema(descriptor protoreflect.MessageDescriptor, defs map[string]interface{}) map[string]interface{} {
+	name := string(descriptor.FullName())
+	ref := map[string]interface{}{"$ref": "#/$defs/" + name}
+	if _, ok := defs[name]; ok {
+		return ref
+	}
+	properties := map[string]interface{}{}
+	object := map[string]interface{}{"type": "object", "properties": properties}
+	// Registered before the fields so a recursive field finds it.
+	defs[name] = object
+
+	fields := descriptor.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		switch {
+		case field.IsMap():
+			properties[field.JSONName()] = map[string]interface{}{
+				"type":                 "object",
+				"additionalProperties": fieldSchema(field.MapValue(), defs),
+			}
+		case field.IsList():
+			properties[field.JSONName()] = map[string]interface{}{
+				"type":  "array",
+				"items": fieldSchema(field, defs),
+			}
+		default:
+			properties[field.JSONName()] = fieldSchema(field, defs)
+		}
+	}
+	return ref
+}
+
+// fieldSchema returns the schema of a single value of field.
+func fieldSchema(field protoreflect.FieldDescriptor, defs map[string]interface{}) map[string]interface{} {
+	switch field.Kind() {
+	case protoreflect.BoolKind:
+		return map[string]interface{}{"type": "boolean"}
+	case protoreflect.StringKind:
+		return map[string]interface{}{"type": "string"}
+	case protoreflect.BytesKind:
+		return map[string]interface{}{"type": "string", "contentEncoding": "base64"}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return map[string]interface{}{"type": "integer"}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return map[string]interface{}{"type": []string{"string", "integer"}, "format": "int64"}
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return map[string]interface{}{"type": "number"}
+	case protoreflect.EnumKind:
+		values := field.Enum().Values()
+		numbers := make([]int32, values.Len())
+		for i := range numbers {
+			numbers[i] = int32(values.Get(i).Number())
+		}
+		return map[string]interface{}{"type": "integer", "enum": numbers}
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return messageSchema(field.Message(), defs)
+	}
+	return map[string]interface{}{}
+}
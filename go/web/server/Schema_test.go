@@ -0,0 +1,103 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8types/go/types/l8api"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// schemaService queries with L8Query into a Struct, and posts a Struct that
+// answers a Duration. It accepts no other method.
+type schemaService struct {
+	ifs.IWebService
+}
+
+func (this *schemaService) Protos(data string, action ifs.Action) (proto.Message, proto.Message, error) {
+	switch action {
+	case ifs.GET:
+		return &l8api.L8Query{}, &structpb.Struct{}, nil
+	case ifs.POST:
+		return &structpb.Struct{}, nil, nil
+	}
+	return nil, nil, errors.New("unsupported")
+}
+
+func TestSchema_Service(t *testing.T) {
+	handler := &ServiceHandler{serviceName: "Docs", serviceArea: 2, webService: &schemaService{}}
+	w := httptest.NewRecorder()
+	handler.serveSchema(w, httptest.NewRequest(http.MethodGet, "/2/Docs:schema", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	schema := &ServiceSchema{}
+	if err := json.Unmarshal(w.Body.Bytes(), schema); err != nil {
+		t.Fatal(err)
+	}
+
+	query := string((&l8api.L8Query{}).ProtoReflect().Descriptor().FullName())
+	if len(schema.Methods) != 2 || schema.ServiceName != "Docs" || schema.ServiceArea != 2 ||
+		*schema.Methods["GET"] != (MethodSchema{Request: query, Response: "google.protobuf.Struct"}) ||
+		*schema.Methods["POST"] != (MethodSchema{Request: "google.protobuf.Struct"}) {
+		t.Fatalf("unexpected methods %s", w.Body.String())
+	}
+
+	defs := schema.Schema["$defs"].(map[string]interface{})
+	property := func(message, field string) map[string]interface{} {
+		def, ok := defs[message].(map[string]interface{})
+		if !ok {
+			t.Fatalf("expected %s in $defs, got %s", message, w.Body.String())
+		}
+		return def["properties"].(map[string]interface{})[field].(map[string]interface{})
+	}
+	if property(query, "text")["type"] != "string" {
+		t.Fatalf("expected a string text, got %v", property(query, "text"))
+	}
+	if values := property("google.protobuf.Struct", "fields")["additionalProperties"].(map[string]interface{}); values["$ref"] != "#/$defs/google.protobuf.Value" {
+		t.Fatalf("expected a map of Value, got %v", values)
+	}
+	if property("google.protobuf.Value", "structValue")["$ref"] != "#/$defs/google.protobuf.Struct" {
+		t.Fatal("expected the recursive Struct to be referenced")
+	}
+	if enum := property("google.protobuf.Value", "nullValue")["enum"].([]interface{}); len(enum) != 1 || enum[0] != float64(0) {
+		t.Fatalf("expected the NullValue enum numbers, got %v", enum)
+	}
+	if items := property("google.protobuf.ListValue", "values")["items"].(map[string]interface{}); items["$ref"] != "#/$defs/google.protobuf.Value" {
+		t.Fatalf("expected an array of Value, got %v", items)
+	}
+	if seconds := fieldSchema((&durationpb.Duration{}).ProtoReflect().Descriptor().Fields().ByName("seconds"), defs); seconds["format"] != "int64" {
+		t.Fatalf("expected int64 seconds, got %v", seconds)
+	}
+
+	w = httptest.NewRecorder()
+	handler.serveSchema(w, httptest.NewRequest(http.MethodPost, "/2/Docs:schema", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", w.Code)
+	}
+	handler.authEnabled = true
+	w = httptest.NewRecorder()
+	handler.serveSchema(w, httptest.NewRequest(http.MethodGet, "/2/Docs:schema", nil))
+	decodeError(t, w, http.StatusUnauthorized)
+}
@@ -0,0 +1,95 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Scopes.go checks that an authenticated user holds the scopes (or roles)
+// RestServerConfig.ServiceScopes requires for a web service and HTTP method.
+//
+// ifs.ISecurityProvider.ValidateToken only returns the user's id, so the scopes
+// are taken from a security provider that also implements ScopeProvider. If it
+// doesn't, requests that need a scope are refused.
+
+package server
+
+import (
+	"strings"
+
+	"github.com/saichler/l8types/go/ifs"
+)
+
+// ScopeProvider is implemented by security providers that can report the scopes
+// granted to a user, for example from the claims of the user's token.
+type ScopeProvider interface {
+	// Scopes returns the scopes of the user with the id returned by ValidateToken.
+	Scopes(aaaid string, vnic ifs.IVNic) []string
+}
+
+// ServiceScope requires a scope for a web service, or for some of its HTTP
+// methods, e.g. "orders:write" for the POST, PUT, PATCH and DELETE methods.
+type ServiceScope struct {
+	ServiceName string   // Name of the web service
+	ServiceArea byte     // Service area of the web service
+	Methods     []string // HTTP methods it applies to, empty for every method
+	Scopes      []string // Accepted scopes; holding any one of them is enough
+}
+
+// applyServiceScopes collects the ServiceScopes entries for the handler's
+// service, keyed by HTTP method, with "" for entries that apply to every method.
+func (this *RestServer) applyServiceScopes(handler *ServiceHandler) {
+	for _, scope := range this.ServiceScopes {
+		if scope.ServiceName != handler.serviceName || scope.ServiceArea != handler.serviceArea || len(scope.Scopes) == 0 {
+			continue
+		}
+		if handler.scopes == nil {
+			handler.scopes = map[string][]string{}
+		}
+		methods := scope.Methods
+		if len(methods) == 0 {
+			methods = []string{""}
+		}
+		for _, method := range methods {
+			method = strings.ToUpper(method)
+			handler.scopes[method] = append(handler.scopes[method], scope.Scopes...)
+		}
+	}
+}
+
+// requiredScopes returns the scopes accepted for requests with the given HTTP
+// method, or nil if the method needs none.
+func (this *ServiceHandler) requiredScopes(method string) []string {
+	if scopes, ok := this.scopes[method]; ok {
+		return scopes
+	}
+	return this.scopes[""]
+}
+
+// hasScope reports whether the user holds one of the scopes required for method.
+func (this *ServiceHandler) hasScope(aaaid, method string) bool {
+	required := this.requiredScopes(method)
+	if len(required) == 0 {
+		return true
+	}
+	provider, ok := this.vnic.Resources().Security().(ScopeProvider)
+	if !ok {
+		return false
+	}
+	for _, granted := range provider.Scopes(aaaid, this.vnic) {
+		for _, scope := range required {
+			if granted == scope {
+				return true
+			}
+		}
+	}
+	return false
+}
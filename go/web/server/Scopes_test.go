@@ -0,0 +1,111 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/saichler/l8types/go/ifs"
+)
+
+// tokenSecurity accepts any "Bearer <user>" token as the user's id.
+type tokenSecurity struct {
+	ifs.ISecurityProvider
+}
+
+func (this *tokenSecurity) ValidateToken(token string, vnic ifs.IVNic) (string, bool) {
+	return strings.TrimPrefix(token, "Bearer "), true
+}
+
+// scopedSecurity is a tokenSecurity that grants each user its listed scopes.
+type scopedSecurity struct {
+	tokenSecurity
+	scopes map[string][]string
+}
+
+func (this *scopedSecurity) Scopes(aaaid string, vnic ifs.IVNic) []string {
+	return this.scopes[aaaid]
+}
+
+type securityResources struct {
+	quietResources
+	security ifs.ISecurityProvider
+}
+
+func (this *securityResources) Security() ifs.ISecurityProvider {
+	return this.security
+}
+
+type securityVnic struct {
+	ifs.IVNic
+	resources ifs.IResources
+}
+
+func (this *securityVnic) Resources() ifs.IResources {
+	return this.resources
+}
+
+// scopeStatus sends an unsupported body for user, so a request that passes
+// the scope check ends with 415.
+func scopeStatus(handler *ServiceHandler, method, user string) int {
+	r := httptest.NewRequest(method, "/0/Users", strings.NewReader("<xml/>"))
+	r.Header.Set("Content-Type", "application/xml")
+	if user != "" {
+		r.Header.Set("Authorization", "Bearer "+user)
+	}
+	w := httptest.NewRecorder()
+	handler.serveHttp(w, r)
+	return w.Code
+}
+
+func TestScopes_Forbidden(t *testing.T) {
+	rs := &RestServer{}
+	rs.ServiceScopes = []ServiceScope{
+		{ServiceName: "Users", Scopes: []string{"users:read", "admin"}},
+		{ServiceName: "Users", Methods: []string{"delete"}, Scopes: []string{"admin"}},
+	}
+	security := &scopedSecurity{scopes: map[string][]string{
+		"reader": {"users:read"},
+		"admin":  {"admin"},
+	}}
+	handler := &ServiceHandler{serviceName: "Users", vnic: &securityVnic{resources: &securityResources{security: security}}}
+	rs.applyServiceScopes(handler)
+
+	for _, test := range []struct {
+		method, user string
+		status       int
+	}{
+		{http.MethodGet, "", http.StatusUnauthorized},
+		{http.MethodGet, "guest", http.StatusForbidden},
+		{http.MethodGet, "reader", http.StatusUnsupportedMediaType},
+		{http.MethodGet, "admin", http.StatusUnsupportedMediaType},
+		{http.MethodDelete, "reader", http.StatusForbidden},
+		{http.MethodDelete, "admin", http.StatusUnsupportedMediaType},
+	} {
+		if status := scopeStatus(handler, test.method, test.user); status != test.status {
+			t.Fatalf("%s as %q: expected %d, got %d", test.method, test.user, test.status, status)
+		}
+	}
+
+	// A provider that can't report scopes grants none.
+	handler.vnic = &securityVnic{resources: &securityResources{security: &tokenSecurity{}}}
+	if status := scopeStatus(handler, http.MethodGet, "admin"); status != http.StatusForbidden {
+		t.Fatalf("expected 403 without a ScopeProvider, got %d", status)
+	}
+}
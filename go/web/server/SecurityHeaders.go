@@ -0,0 +1,63 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// SecurityHeaders.go adds security response headers to everything the server
+// serves: the web UI files as well as the API and built-in endpoints.
+
+package server
+
+import (
+	"net/http"
+)
+
+// DefaultSecurityHeaders are sent on every response unless overridden by
+// RestServerConfig.SecurityHeaders. Content-Security-Policy is not set by
+// default, since a useful policy depends on the web UI; set it in
+// SecurityHeaders.
+var DefaultSecurityHeaders = map[string]string{
+	"X-Content-Type-Options":    "nosniff",
+	"X-Frame-Options":           "SAMEORIGIN",
+	"Referrer-Policy":           "strict-origin-when-cross-origin",
+	"Strict-Transport-Security": "max-age=31536000",
+}
+
+// securityHeaders returns the headers to send: DefaultSecurityHeaders merged
+// with the configured SecurityHeaders, where an empty value disables a header.
+func (this *RestServer) securityHeaders() map[string]string {
+	headers := make(map[string]string)
+	for name, value := range DefaultSecurityHeaders {
+		headers[http.CanonicalHeaderKey(name)] = value
+	}
+	for name, value := range this.SecurityHeaders {
+		if value == "" {
+			delete(headers, http.CanonicalHeaderKey(name))
+		} else {
+			headers[http.CanonicalHeaderKey(name)] = value
+		}
+	}
+	return headers
+}
+
+// withSecurityHeaders wraps a handler, setting the security headers on every
+// response before the handler runs.
+func (this *RestServer) withSecurityHeaders(next http.Handler) http.Handler {
+	headers := this.securityHeaders()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for name, value := range headers {
+			w.Header().Set(name, value)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,50 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSecurityHeaders(t *testing.T) {
+	setWebUIFiles(t, map[string]string{"/": "root"})
+	rs := &RestServer{RestServerConfig: RestServerConfig{SecurityHeaders: map[string]string{
+		"content-security-policy": "default-src 'self'",
+		"X-Frame-Options":         "DENY",
+		"Referrer-Policy":         "",
+	}}}
+	handler := rs.withSecurityHeaders(http.HandlerFunc(rs.smartRootHandler))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	expected := map[string]string{
+		"X-Content-Type-Options":    "nosniff",
+		"Strict-Transport-Security": "max-age=31536000",
+		"Content-Security-Policy":   "default-src 'self'",
+		"X-Frame-Options":           "DENY",
+		"Referrer-Policy":           "",
+	}
+	for name, value := range expected {
+		if w.Header().Get(name) != value {
+			t.Fatalf("expected %s: %q, got %q", name, value, w.Header().Get(name))
+		}
+	}
+	if w.Body.String() != "root" {
+		t.Fatalf("expected the wrapped handler to serve the file, got %q", w.Body.String())
+	}
+}
@@ -20,13 +20,17 @@
 package server
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/saichler/l8types/go/types/l8health"
 	"github.com/saichler/l8types/go/types/l8services"
 	"io"
 	"net/http"
 	"reflect"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/saichler/l8bus/go/overlay/health"
 	"github.com/saichler/l8types/go/ifs"
@@ -39,11 +43,20 @@ import (
 // through the Layer 8 VNic to the appropriate service implementation. It manages
 // authentication validation, request parsing, and response serialization.
 type ServiceHandler struct {
-	serviceName string          // Name of the service being handled
-	serviceArea byte            // Service area identifier for routing
-	vnic        ifs.IVNic       // Layer 8 Virtual Network Interface for communication
-	webService  ifs.IWebService // The web service implementation
-	authEnabled bool            // Whether authentication is required for this handler
+	serviceName string              // Name of the service being handled
+	serviceArea byte                // Service area identifier for routing
+	vnic        ifs.IVNic           // Layer 8 Virtual Network Interface for communication
+	webService  ifs.IWebService     // The web service implementation
+	authEnabled bool                // Whether authentication is required for this handler
+	authMethods map[string]bool     // Per HTTP method overrides of authEnabled, from ServiceAuth
+	scopes      map[string][]string // Scopes required per HTTP method ("" for every method), from ServiceScopes
+	etags       *etagCache          // Conditional GET state, nil when ETags are disabled
+	audit       *auditor            // Audit hook settings, nil when auditing is disabled
+	errorMapper func(error) int     // Status of backend errors, from ErrorMapper
+	preDispatch []PreDispatchFunc   // Hooks run before dispatch, from PreDispatch and ServicePreDispatch
+	limiter     *serviceLimiter     // Concurrency limit, from ServiceLimits, nil when unlimited
+	forwarder   *headerForwarder    // Headers passed to a HeaderCarrier body, from ForwardHeaders
+	slowAfter   time.Duration       // Slow request threshold, from SlowRequestThreshold
 }
 
 // ServiceAction encapsulates request and response Protocol Buffer messages
@@ -54,14 +67,22 @@ type ServiceAction struct {
 }
 
 // Timeout specifies the default request timeout in seconds for VNic operations.
+// A request may raise it through TimeoutHeader, up to MaxTimeout, and its
+// context deadline lowers it.
 var Timeout = 30
 
 // Target specifies a specific service instance UUID to route requests to.
 // If empty, requests are routed based on the Method setting.
 var Target = ""
 
+// RetryAfter is the Retry-After delay, in seconds, sent with the 503 Service
+// Unavailable answered while the VNic is not connected to the overlay, or
+// while a service is at its ServiceLimits.
+var RetryAfter = 5
+
 // Method specifies the routing method for requests: M_Leader (leader-based),
-// M_Local (local service), or M_Proximity (proximity-based routing).
+// M_Local (local service), or M_Proximity (proximity-based routing). A request
+// may choose another method through RoutingHeader.
 var Method = ifs.M_Leader
 
 // ServiceName returns the name of the service this handler manages.
@@ -74,62 +95,117 @@ func (this *ServiceHandler) ServiceArea() byte {
 	return this.serviceArea
 }
 
-// serveHttp is the main HTTP handler function that processes incoming requests.
-// It performs the following steps:
-// 1. Validates bearer token authentication if enabled
-// 2. Reads and parses the request body (supports query parameter for GET requests)
-// 3. Routes the request through the Layer 8 VNic based on routing method
-// 4. Serializes and returns the response as JSON
-//
-// Authentication tokens are checked in the following order:
-// - Authorization header (Bearer token)
-// - Adjacent token mapping (for cross-VNet requests)
+// authRequired reports whether requests with the given HTTP method need a
+// bearer token. Methods that require a scope always do.
+func (this *ServiceHandler) authRequired(method string) bool {
+	if len(this.requiredScopes(method)) > 0 {
+		return true
+	}
+	if required, ok := this.authMethods[method]; ok {
+		return required
+	}
+	return this.authEnabled
+}
+
+// serveHttp handles a request to the service's path as a pipeline:
+//  1. authorize checks the bearer token and the ServiceScopes of the method
+//  2. the body is parsed for the method (BodyToProto.go, Patch.go, Form.go, DeleteQuery.go)
+//  3. the body goes to the HeaderCarrier and RequestIDCarrier hooks, then PreDispatch
+//  4. the request waits for a ServiceLimits slot (Limits.go)
+//  5. the body is sent through the VNic with a timeout derived from the request (Deadline.go)
+//  6. the answer is written: 204 for a void write, else BinaryResource bytes
+//     (Binary.go), an NDJSON stream (Stream.go) or JSON, with ETags (ETag.go)
+//     and CreatedResource (Created.go)
 //
-// Returns HTTP 401 Unauthorized if authentication fails, HTTP 400 Bad Request
-// for parsing errors, or HTTP 200 OK with JSON response on success.
+// Every response carries the RequestIDHeader, failures are written as an
+// ErrorResponse envelope (Errors.go), and mutating requests are reported to
+// the AuditHook (Audit.go).
 func (this *ServiceHandler) serveHttp(w http.ResponseWriter, r *http.Request) {
-	aaaid := ""
-	if this.authEnabled {
-		bearer := r.Header.Get("Authorization")
-		if bearer == "" {
-			w.WriteHeader(http.StatusUnauthorized)
-			return
-		}
-		id, ok := this.vnic.Resources().Security().ValidateToken(bearer, this.vnic)
-		if !ok && (id == "Token Setup TFA" || id == "Token Need TFA Verification") {
-			w.WriteHeader(http.StatusUnauthorized)
-			w.Write([]byte(id))
-			return
-		}
-		if !ok {
-			w.WriteHeader(http.StatusUnauthorized)
-			return
-		}
-		aaaid = id
+	reqID := requestID(r)
+	w.Header().Set(RequestIDHeader, reqID)
+	var aaaid string
+	metrics := newMetricsWriter(w)
+	w = metrics
+	defer func() {
+		this.recordMetrics(metrics, r, reqID, aaaid)
+	}()
+	audit := this.audit.start(w, r, this, reqID)
+	if audit != nil {
+		w = audit
+		defer audit.done()
+	}
+	aaaid, ok := this.authorize(w, r, r.Method)
+	audit.setUser(aaaid)
+	if !ok {
+		return
+	}
+
+	routing, ok := routingHint(r)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Unknown "+RoutingHeader+" value "+routing+", expected leader, local or proximity")
+		return
 	}
 
 	data, err := io.ReadAll(r.Body)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte("Failed to read body for method " + r.Method + "\n"))
-		w.Write([]byte(err.Error()))
-		fmt.Println("Failed to read body for method " + r.Method + "\n")
+		writeError(w, http.StatusBadRequest, "Failed to read body for method "+r.Method+": "+err.Error())
+		fmt.Println("[" + reqID + "] Failed to read body for method " + r.Method + "\n")
 		return
 	}
+	audit.setBody(data)
 
 	if strings.ToLower(r.Method) == "get" && (data == nil || len(data) == 0) {
 		qData := r.URL.Query().Get("body")
 		data = []byte(qData)
 	}
 
+	patchType := patchContentType(r)
+	formBody := isFormContentType(r)
+	if patchType == "" && !formBody && !isJSONContentType(r) {
+		writeError(w, http.StatusUnsupportedMediaType, "Unsupported Content-Type "+r.Header.Get("Content-Type")+", expected "+JSONContentType)
+		return
+	}
+	if patchType != "" {
+		if r.Method != http.MethodPatch {
+			writeError(w, http.StatusUnsupportedMediaType, patchType+" is only accepted for PATCH")
+			return
+		}
+		data, err = patchToPartial(patchType, data)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid patch document: "+err.Error())
+			fmt.Println("[" + reqID + "] Invalid patch document: " + err.Error())
+			return
+		}
+	}
+
 	action := methodToAction(r.Method, nil)
-	body, _, err := this.webService.Protos(string(data), action)
+	var body proto.Message
+	bulkDelete := isBulkDelete(r, data)
+	if bulkDelete {
+		body, err = this.deleteQuery(r.URL.Query())
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid delete filter: "+err.Error())
+			fmt.Println("[" + reqID + "] Invalid delete filter: " + err.Error())
+			return
+		}
+	} else if formBody {
+		// Create an empty body of the service's type and fill it from the form.
+		body, _, err = this.webService.Protos("{}", action)
+		if err == nil {
+			err = formToProto(data, body)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "Invalid form body: "+err.Error())
+				fmt.Println("[" + reqID + "] Invalid form body: " + err.Error())
+				return
+			}
+		}
+	} else {
+		body, _, err = this.webService.Protos(string(data), action)
+	}
 
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte("Cannot find pb for method " + r.Method + "\n"))
-		w.Write([]byte(err.Error()))
-		fmt.Println("Cannot find pb for method " + r.Method + "\n")
+		writeError(w, http.StatusBadRequest, "Cannot find pb for method "+r.Method+": "+err.Error())
+		fmt.Println("[" + reqID + "] Cannot find pb for method " + r.Method + "\n")
 		return
 	}
 
@@ -137,54 +213,101 @@ func (this *ServiceHandler) serveHttp(w http.ResponseWriter, r *http.Request) {
 	if q, ok := body.(*l8api.L8Query); ok && aaaid != "" {
 		q.AaaId = aaaid
 	}
-	var elems ifs.IElements
+	this.forwarder.forward(r, body)
+	forwardRequestID(reqID, body)
+	if !this.runPreDispatch(w, r, reqID, body) {
+		return
+	}
 
-	dest := this.vnic.Resources().SysConfig().RemoteUuid
-	if this.serviceName == health.ServiceName {
-		h, ok := body.(*l8health.L8Health)
-		if ok {
-			this.vnic.Resources().Logger().Info("Sending to destination ", h.Alias, " - ", h.AUuid)
-			elems = this.vnic.Request(h.AUuid, this.serviceName, this.serviceArea, action, body, Timeout)
-		} else {
-			this.vnic.Resources().Logger().Info("Sending to vnet")
-			elems = this.vnic.Request(dest, this.serviceName, this.serviceArea, action, body, Timeout)
-		}
-	} else {
-		if Target != "" {
-			elems = this.vnic.Request(Target, this.serviceName, this.serviceArea, action, body, Timeout, aaaid)
-		} else {
-			if Method == ifs.M_Leader {
-				elems = this.vnic.LeaderRequest(this.serviceName, this.serviceArea, action, body, Timeout, aaaid)
-			} else if Method == ifs.M_Local {
-				elems = this.vnic.LocalRequest(this.serviceName, this.serviceArea, action, body, Timeout, aaaid)
-			} else {
-				elems = this.vnic.ProximityRequest(this.serviceName, this.serviceArea, action, body, Timeout, aaaid)
-			}
+	// Don't start a backend round trip for a client that already went away.
+	if r.Context().Err() != nil {
+		this.abandoned(w, r, reqID)
+		return
+	}
+	// A VNic that is not connected to the overlay, during startup or while it
+	// reconnects, would hold the request for the whole timeout.
+	if !this.vnic.Running() {
+		w.Header().Set("Retry-After", strconv.Itoa(RetryAfter))
+		writeError(w, http.StatusServiceUnavailable, "Not connected to the overlay, retry later")
+		fmt.Println("[" + reqID + "] VNic not connected")
+		return
+	}
+	timeout := requestTimeout(r)
+
+	err = this.limiter.acquire(r.Context())
+	if err != nil {
+		if r.Context().Err() != nil {
+			this.abandoned(w, r, reqID)
+			return
 		}
+		w.Header().Set("Retry-After", strconv.Itoa(RetryAfter))
+		writeError(w, http.StatusServiceUnavailable, "Service "+this.serviceName+" is busy: "+err.Error())
+		fmt.Println("[" + reqID + "] Service busy: " + err.Error())
+		return
+	}
+
+	// The request id reaches the backend only in a RequestIDCarrier body; it is
+	// logged here with the service, area and action so requests with other
+	// bodies can still be matched against backend service logs.
+	this.vnic.Resources().Logger().Debug("[", reqID, "] ", r.Method, " ", r.URL.Path, " -> ", this.serviceName, " area ", this.serviceArea, " action ", action, " timeout ", timeout)
+
+	// The VNic request can't be cancelled, so it runs aside and the handler
+	// returns as soon as the client disconnects or its deadline passes.
+	done := make(chan ifs.IElements, 1)
+	go func() {
+		// The slot is held until the backend answers, even for an abandoned request
+		defer this.limiter.release()
+		done <- this.request(body, action, aaaid, timeout, reqID, routing)
+	}()
+	var elems ifs.IElements
+	select {
+	case elems = <-done:
+	case <-r.Context().Done():
+		this.abandoned(w, r, reqID)
+		return
 	}
 
 	if elems.Error() != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte("Error from single request:"))
-		w.Write([]byte(elems.Error().Error()))
-		fmt.Println("Error from single request:")
+		writeError(w, this.errorStatus(elems.Error()), "Error from single request: "+elems.Error().Error())
+		fmt.Println("[" + reqID + "] Error from single request:")
 		fmt.Println(elems.Error().Error())
 		return
 	}
 
 	trans, ok := elems.Element().(*l8services.L8Transaction)
 	if ok && trans.ErrMsg != "" {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte("Validation Error"))
-		w.Write([]byte(trans.ErrMsg))
-		fmt.Println("Validation Error")
+		writeError(w, http.StatusBadRequest, "Validation Error: "+trans.ErrMsg)
+		fmt.Println("[" + reqID + "] Validation Error")
 		fmt.Println(trans.ErrMsg)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
+	if bulkDelete {
+		writeDeleteResult(w, elems)
+		return
+	}
+
+	// A write the service answered without any element has nothing to return.
+	// GETs always get a body, "{}" for an empty result, which is how an empty
+	// list message marshals, so an empty result is not mistaken for a void one.
+	if r.Method != http.MethodGet && isVoid(elems) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if binary, ok := elems.Element().(BinaryResource); ok {
+		this.writeBinary(w, r, createdStatus(w, r, elems), binary)
+		return
+	}
+
+	if acceptsStream(r) {
+		this.stream(w, reqID, elems)
+		return
+	}
+
 	response, e := elems.AsList(this.vnic.Resources().Registry())
 	if e != nil {
+		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("{}"))
 		/*
 			w.Write([]byte("Erorr as list:"))
@@ -198,14 +321,100 @@ func (this *ServiceHandler) serveHttp(w http.ResponseWriter, r *http.Request) {
 	}
 	j, e := marshalOptions.Marshal(response.(proto.Message))
 	if e != nil {
-		w.Write([]byte("Erorr marshaling:" + reflect.ValueOf(response).Elem().Type().Name()))
-		w.Write([]byte(e.Error()))
-		fmt.Println("Erorr marshaling:" + reflect.ValueOf(response).Elem().Type().Name())
+		writeError(w, http.StatusInternalServerError, "Erorr marshaling "+reflect.ValueOf(response).Elem().Type().Name()+": "+e.Error())
+		fmt.Println("[" + reqID + "] Erorr marshaling:" + reflect.ValueOf(response).Elem().Type().Name())
 	} else {
+		if this.etags != nil && r.Method == http.MethodGet && this.etags.notModified(w, r, j) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.WriteHeader(createdStatus(w, r, elems))
 		w.Write(j)
 	}
 }
 
+// authorize checks the bearer token of a request with the given HTTP method,
+// if the service requires one for it, and the user's scopes. It returns the
+// authenticated user id, "" without authentication, and false after writing
+// HTTP 401 or 403 if the request is not allowed.
+func (this *ServiceHandler) authorize(w http.ResponseWriter, r *http.Request, method string) (string, bool) {
+	if !this.authRequired(method) {
+		return "", true
+	}
+	bearer := r.Header.Get("Authorization")
+	if bearer == "" {
+		writeError(w, http.StatusUnauthorized, "missing bearer token")
+		return "", false
+	}
+	id, ok := this.vnic.Resources().Security().ValidateToken(bearer, this.vnic)
+	if !ok && (id == "Token Setup TFA" || id == "Token Need TFA Verification") {
+		writeError(w, http.StatusUnauthorized, id)
+		return "", false
+	}
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "invalid bearer token")
+		return "", false
+	}
+	if !this.hasScope(id, method) {
+		writeError(w, http.StatusForbidden, "missing required scope for "+method+" "+this.serviceName)
+		return id, false
+	}
+	return id, true
+}
+
+// isVoid reports whether the service answered without any element.
+func isVoid(elems ifs.IElements) bool {
+	return elems.Element() == nil && len(elems.Elements()) == 0
+}
+
+// request sends body to the handler's service through the VNic, routed by the
+// health target, Target, or the routing hint and else the Method setting, and
+// waits up to timeout seconds.
+func (this *ServiceHandler) request(body proto.Message, action ifs.Action, aaaid string, timeout int, reqID string, routing string) ifs.IElements {
+	dest := this.vnic.Resources().SysConfig().RemoteUuid
+	if this.serviceName == health.ServiceName {
+		h, ok := body.(*l8health.L8Health)
+		if ok {
+			this.vnic.Resources().Logger().Info("[", reqID, "] Sending to destination ", h.Alias, " - ", h.AUuid)
+			return this.vnic.Request(h.AUuid, this.serviceName, this.serviceArea, action, body, timeout)
+		} else {
+			this.vnic.Resources().Logger().Info("[", reqID, "] Sending to vnet")
+			return this.vnic.Request(dest, this.serviceName, this.serviceArea, action, body, timeout)
+		}
+	} else {
+		if Target != "" {
+			return this.vnic.Request(Target, this.serviceName, this.serviceArea, action, body, timeout, aaaid)
+		} else {
+			method := Method
+			switch routing {
+			case RoutingLeader:
+				method = ifs.M_Leader
+			case RoutingLocal:
+				method = ifs.M_Local
+			case RoutingProximity:
+				method = ifs.M_Proximity
+			}
+			if method == ifs.M_Leader {
+				return this.vnic.LeaderRequest(this.serviceName, this.serviceArea, action, body, timeout, aaaid)
+			} else if method == ifs.M_Local {
+				return this.vnic.LocalRequest(this.serviceName, this.serviceArea, action, body, timeout, aaaid)
+			} else {
+				return this.vnic.ProximityRequest(this.serviceName, this.serviceArea, action, body, timeout, aaaid)
+			}
+		}
+	}
+}
+
+// abandoned ends a request whose context is done before the VNic answered. A
+// passed deadline gets HTTP 504 Gateway Timeout; a client that disconnected gets
+// nothing, as there is nobody left to read it.
+func (this *ServiceHandler) abandoned(w http.ResponseWriter, r *http.Request, reqID string) {
+	fmt.Println("[" + reqID + "] Request abandoned: " + r.Context().Err().Error())
+	if errors.Is(r.Context().Err(), context.DeadlineExceeded) {
+		writeError(w, http.StatusGatewayTimeout, "Request deadline exceeded")
+	}
+}
+
 // methodToAction converts an HTTP method string to a Layer 8 Action constant.
 // If the request body contains an L8Query with "mapreduce" in the text, it returns
 // the MapReduce variant of the action for distributed query execution.
@@ -0,0 +1,96 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/saichler/l8types/go/ifs"
+)
+
+// voidElements is a service answer without any element.
+type voidElements struct {
+	ifs.IElements
+}
+
+func (this *voidElements) Error() error            { return nil }
+func (this *voidElements) Element() interface{}    { return nil }
+func (this *voidElements) Elements() []interface{} { return nil }
+func (this *voidElements) AsList(ifs.IRegistry) (interface{}, error) {
+	return nil, errors.New("no elements")
+}
+
+type voidVnic struct {
+	echoVnic
+}
+
+func (this *voidVnic) LeaderRequest(serviceName string, serviceArea byte, action ifs.Action, body interface{}, timeout int, tokens ...string) ifs.IElements {
+	return &voidElements{}
+}
+
+func TestServiceHandler_NoContent(t *testing.T) {
+	handler := &ServiceHandler{serviceName: "Tests", webService: &echoService{}, vnic: &voidVnic{}}
+	for method, status := range map[string]int{
+		http.MethodDelete: http.StatusNoContent,
+		http.MethodPut:    http.StatusNoContent,
+		http.MethodGet:    http.StatusOK,
+	} {
+		w := httptest.NewRecorder()
+		handler.serveHttp(w, httptest.NewRequest(method, "/0/Tests", strings.NewReader(`{"text":"a"}`)))
+		if w.Code != status {
+			t.Fatalf("%s: expected %d, got %d", method, status, w.Code)
+		}
+		if status == http.StatusNoContent && w.Body.Len() != 0 {
+			t.Fatalf("%s: expected no body, got %q", method, w.Body.String())
+		}
+		if status == http.StatusOK && w.Body.String() != "{}" {
+			t.Fatalf("%s: expected an empty result, got %q", method, w.Body.String())
+		}
+	}
+}
+
+// disconnectedVnic is an echoVnic that is not connected to the overlay.
+type disconnectedVnic struct {
+	echoVnic
+	requests int
+}
+
+func (this *disconnectedVnic) Running() bool {
+	return false
+}
+
+func (this *disconnectedVnic) LeaderRequest(serviceName string, serviceArea byte, action ifs.Action, body interface{}, timeout int, tokens ...string) ifs.IElements {
+	this.requests++
+	return this.echoVnic.LeaderRequest(serviceName, serviceArea, action, body, timeout, tokens...)
+}
+
+func TestServiceHandler_NotConnected(t *testing.T) {
+	vnic := &disconnectedVnic{}
+	handler := &ServiceHandler{serviceName: "Tests", webService: &echoService{}, vnic: vnic}
+	w := httptest.NewRecorder()
+	handler.serveHttp(w, httptest.NewRequest(http.MethodGet, "/0/Tests", strings.NewReader(`{"text":"a"}`)))
+	decodeError(t, w, http.StatusServiceUnavailable)
+	if w.Header().Get("Retry-After") != "5" {
+		t.Fatalf("expected Retry-After 5, got %q", w.Header().Get("Retry-After"))
+	}
+	if vnic.requests != 0 {
+		t.Fatal("expected no request to be sent through a disconnected VNic")
+	}
+}
@@ -0,0 +1,134 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Stream.go sends service responses as newline-delimited JSON, one element
+// per line, flushing after each line so clients see partial results as soon
+// as they are written rather than once the whole response is marshaled.
+//
+// A GET request opts in with "Accept: application/x-ndjson". Flushing is best
+// effort: when the ResponseWriter, or a writer wrapping it such as the
+// HandlerTimeout one, doesn't support it, the lines are delivered when the
+// response completes instead.
+//
+// Errors, void writes and BinaryResource elements are answered as for any
+// other request, and a streamed response carries no ETag.
+
+package server
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/saichler/l8types/go/ifs"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// StreamContentType is the media type of streamed responses, one JSON
+// element per line.
+const StreamContentType = "application/x-ndjson"
+
+// Stream writes Protocol Buffer messages to a response as newline-delimited
+// JSON, flushing each one. It can also be used by handlers registered with
+// RegisterHandler to send progressively computed results.
+type Stream struct {
+	w         http.ResponseWriter
+	rc        *http.ResponseController
+	started   bool // Set once the header is written
+	flushable bool // Cleared once flushing is found unsupported
+}
+
+// NewStream creates a Stream writing to w.
+func NewStream(w http.ResponseWriter) *Stream {
+	return &Stream{w: w, rc: http.NewResponseController(w), flushable: true}
+}
+
+// Send writes message as one line and flushes it. The first call sends the
+// header with status 200 and StreamContentType.
+func (this *Stream) Send(message proto.Message) error {
+	if !this.started {
+		this.w.Header().Set("Content-Type", StreamContentType)
+		// Asks buffering reverse proxies such as nginx to pass lines through
+		this.w.Header().Set("X-Accel-Buffering", "no")
+		this.w.WriteHeader(http.StatusOK)
+		this.started = true
+	}
+	j, err := protojson.MarshalOptions{UseEnumNumbers: true}.Marshal(message)
+	if err != nil {
+		return err
+	}
+	_, err = this.w.Write(append(j, '\n'))
+	if err != nil {
+		return err
+	}
+	return this.Flush()
+}
+
+// Flush sends the lines written so far to the client. It returns nil without
+// flushing when the writer doesn't support it.
+func (this *Stream) Flush() error {
+	if !this.flushable {
+		return nil
+	}
+	err := this.rc.Flush()
+	if errors.Is(err, http.ErrNotSupported) {
+		this.flushable = false
+		return nil
+	}
+	return err
+}
+
+// acceptsStream reports whether a request asks for a streamed response.
+func acceptsStream(r *http.Request) bool {
+	if r.Method != http.MethodGet {
+		return false
+	}
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accept))
+		if err == nil && mediaType == StreamContentType {
+			return true
+		}
+	}
+	return false
+}
+
+// stream writes the elements of a service response to a Stream, one line
+// per element. A response of a single element is streamed as one line.
+func (this *ServiceHandler) stream(w http.ResponseWriter, reqID string, elems ifs.IElements) {
+	elements := elems.Elements()
+	if len(elements) == 0 && elems.Element() != nil {
+		elements = []interface{}{elems.Element()}
+	}
+	stream := NewStream(w)
+	for _, element := range elements {
+		message, ok := element.(proto.Message)
+		if !ok {
+			fmt.Println("["+reqID+"] Skipping streamed element that is not a proto message:", element)
+			continue
+		}
+		if err := stream.Send(message); err != nil {
+			// The header is sent, so the client can only see a truncated stream
+			fmt.Println("["+reqID+"] Error streaming response:", err)
+			return
+		}
+	}
+	if !stream.started {
+		w.Header().Set("Content-Type", StreamContentType)
+		w.WriteHeader(http.StatusOK)
+	}
+}
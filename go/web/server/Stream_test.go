@@ -0,0 +1,112 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8types/go/types/l8api"
+)
+
+// listElements answers a request with several elements.
+type listElements struct {
+	echoElements
+	elements []interface{}
+}
+
+func (this *listElements) Error() error            { return nil }
+func (this *listElements) Element() interface{}    { return this.elements[0] }
+func (this *listElements) Elements() []interface{} { return this.elements }
+
+// listVnic is an ifs.IVNic whose leader requests answer three elements.
+type listVnic struct {
+	echoVnic
+}
+
+func (this *listVnic) LeaderRequest(serviceName string, serviceArea byte, action ifs.Action, body interface{}, timeout int, tokens ...string) ifs.IElements {
+	return &listElements{elements: []interface{}{
+		&l8api.L8Query{Text: "a"}, &l8api.L8Query{Text: "b"}, &l8api.L8Query{Text: "c"},
+	}}
+}
+
+// plainWriter hides the Flush method of the recorder it wraps.
+type plainWriter struct {
+	http.ResponseWriter
+}
+
+func streamRequest() *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/0/Tests", strings.NewReader(`{"text":"select * from a"}`))
+	r.Header.Set("Accept", StreamContentType+"; charset=utf-8")
+	return r
+}
+
+const streamed = `{"text":"a"}` + "\n" + `{"text":"b"}` + "\n" + `{"text":"c"}` + "\n"
+
+func TestStream_NDJSON(t *testing.T) {
+	handler := &ServiceHandler{serviceName: "Tests", webService: &echoService{}, vnic: &listVnic{}}
+	w := httptest.NewRecorder()
+	handler.serveHttp(w, streamRequest())
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != StreamContentType {
+		t.Fatalf("expected a 200 stream, got %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	if w.Body.String() != streamed {
+		t.Fatalf("expected one element per line, got %q", w.Body.String())
+	}
+	if !w.Flushed {
+		t.Fatal("expected the elements to be flushed")
+	}
+
+	// Without the Accept header the response is a single JSON document.
+	w = httptest.NewRecorder()
+	handler.serveHttp(w, httptest.NewRequest(http.MethodGet, "/0/Tests", strings.NewReader(`{"text":"a"}`)))
+	if w.Header().Get("Content-Type") == StreamContentType || w.Flushed {
+		t.Fatalf("expected no stream without Accept: %s", StreamContentType)
+	}
+}
+
+func TestStream_NoFlusher(t *testing.T) {
+	handler := &ServiceHandler{serviceName: "Tests", webService: &echoService{}, vnic: &listVnic{}}
+	recorder := httptest.NewRecorder()
+	handler.serveHttp(&plainWriter{recorder}, streamRequest())
+	if recorder.Body.String() != streamed || recorder.Flushed {
+		t.Fatalf("expected the full stream without flushing, got %q", recorder.Body.String())
+	}
+}
+
+func TestStream_Gzip(t *testing.T) {
+	handler := &ServiceHandler{serviceName: "Tests", webService: &echoService{}, vnic: &listVnic{}}
+	r := streamRequest()
+	r.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	withGzip(true, handler.serveHttp)(w, r)
+	if !w.Flushed || w.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("expected a flushed gzip stream, got %v", w.Header())
+	}
+	reader, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil || string(data) != streamed {
+		t.Fatalf("unexpected decompressed stream %q: %v", data, err)
+	}
+}
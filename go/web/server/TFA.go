@@ -38,7 +38,15 @@ import (
 // It expects a POST request with a user ID and returns a secret key and QR code
 // URL that can be scanned by authenticator apps (Google Authenticator, Authy, etc.).
 // The QR code encodes a TOTP URI that authenticator apps can use to generate codes.
+// Returns 404 if RestServerConfig.DisableTFA is set, and 405 for methods other than POST.
 func (this *WebService) TFASetup(w http.ResponseWriter, r *http.Request) {
+	if this.tfaDisabled() {
+		http.NotFound(w, r)
+		return
+	}
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
 	body := &l8api.L8TFASetup{}
 	if !bodyToProto(w, r, "POST", body) {
 		return
@@ -46,8 +54,7 @@ func (this *WebService) TFASetup(w http.ResponseWriter, r *http.Request) {
 
 	secret, qr, err := this.vnic.Resources().Security().TFASetup(body.UserId, this.vnic)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte(err.Error()))
+		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
@@ -56,8 +63,7 @@ func (this *WebService) TFASetup(w http.ResponseWriter, r *http.Request) {
 	resp.Qr = qr
 	respData, err := protojson.Marshal(resp)
 	if err != nil {
-		w.WriteHeader(http.StatusServiceUnavailable)
-		w.Write([]byte(err.Error()))
+		writeError(w, http.StatusServiceUnavailable, err.Error())
 		return
 	}
 
@@ -68,23 +74,29 @@ func (this *WebService) TFASetup(w http.ResponseWriter, r *http.Request) {
 // TFAVerify handles the /tfaVerify and /tfaSetupVerify endpoints for TOTP code verification.
 // It expects a POST request with user ID, the 6-digit TOTP code, and optionally a bearer token.
 // On success, it returns ok=true. This is used both for initial TFA setup verification
-// and for validating TFA codes during login.
+// and for validating TFA codes during login. Returns 404 if RestServerConfig.DisableTFA
+// is set, and 405 for methods other than POST.
 func (this *WebService) TFAVerify(w http.ResponseWriter, r *http.Request) {
+	if this.tfaDisabled() {
+		http.NotFound(w, r)
+		return
+	}
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
 	body := &l8api.L8TFAVerify{}
 	if !bodyToProto(w, r, "POST", body) {
 		return
 	}
 	authtoken, ok := this.faTokens.Load(body.UserId)
 	if !ok {
-		w.WriteHeader(http.StatusUnauthorized)
-		w.Write([]byte("unauthorized, invalid hash"))
+		writeError(w, http.StatusUnauthorized, "unauthorized, invalid hash")
 		return
 	}
 	token := authtoken.(*faTokenHash).authToken.Token
 	err := this.vnic.Resources().Security().TFAVerify(body.UserId, body.Code, token, this.vnic)
 	if err != nil {
-		w.WriteHeader(http.StatusUnauthorized)
-		w.Write([]byte(err.Error()))
+		writeError(w, http.StatusUnauthorized, err.Error())
 		return
 	}
 	this.faTokens.Delete(body.UserId)
@@ -93,8 +105,7 @@ func (this *WebService) TFAVerify(w http.ResponseWriter, r *http.Request) {
 	resp.Token = token
 	respData, err := protojson.Marshal(resp)
 	if err != nil {
-		w.WriteHeader(http.StatusServiceUnavailable)
-		w.Write([]byte(err.Error()))
+		writeError(w, http.StatusServiceUnavailable, err.Error())
 		return
 	}
 
@@ -105,16 +116,23 @@ func (this *WebService) TFAVerify(w http.ResponseWriter, r *http.Request) {
 // Captcha handles the /captcha endpoint for generating CAPTCHA challenges.
 // It returns a CAPTCHA string that must be included in registration requests
 // to prevent automated bot registrations. The CAPTCHA is typically displayed
-// as an image challenge that users must solve.
+// as an image challenge that users must solve. Accepts GET and POST. Returns
+// 404 if RestServerConfig.DisableCaptcha is set.
 func (this *WebService) Captcha(w http.ResponseWriter, r *http.Request) {
+	if this.captchaDisabled() {
+		http.NotFound(w, r)
+		return
+	}
+	if !allowMethod(w, r, http.MethodGet, http.MethodPost) {
+		return
+	}
 	cp := this.vnic.Resources().Security().Captcha()
 	resp := &l8api.Captcha{}
 	resp.Captcha = cp
 
 	respData, err := protojson.Marshal(resp)
 	if err != nil {
-		w.WriteHeader(http.StatusServiceUnavailable)
-		w.Write([]byte(err.Error()))
+		writeError(w, http.StatusServiceUnavailable, err.Error())
 		return
 	}
 
@@ -125,19 +143,25 @@ func (this *WebService) Captcha(w http.ResponseWriter, r *http.Request) {
 // Register handles the /register endpoint for new user registration.
 // It expects a POST request with username, password, and a valid CAPTCHA response.
 // The CAPTCHA must match one previously obtained from the /captcha endpoint.
-// Returns HTTP 200 on success or HTTP 401 if registration fails (invalid CAPTCHA,
-// duplicate user, etc.).
+// Returns HTTP 204 No Content on success or HTTP 401 if registration fails (invalid CAPTCHA,
+// duplicate user, etc.). Returns 404 if RestServerConfig.DisableRegistration is set,
+// and 405 for methods other than POST.
 func (this *WebService) Register(w http.ResponseWriter, r *http.Request) {
+	if this.registrationDisabled() {
+		http.NotFound(w, r)
+		return
+	}
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
 	body := &l8api.AuthUser{}
 	if !bodyToProto(w, r, "POST", body) {
 		return
 	}
 	err := this.vnic.Resources().Security().Register(body.User, body.Pass, body.Captcha, this.vnic)
 	if err != nil {
-		w.WriteHeader(http.StatusUnauthorized)
-		w.Write([]byte(err.Error()))
+		writeError(w, http.StatusUnauthorized, err.Error())
 		return
 	}
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("{}"))
+	w.WriteHeader(http.StatusNoContent)
 }
@@ -19,17 +19,27 @@
 //
 // Built-in HTTP endpoints registered by this service:
 //   - /auth         - User authentication (returns bearer token)
-//   - /registry     - Type registry access
+//   - /registry     - Type registry access (opt-in via RestServerConfig.EnableRegistry)
+//   - /services     - Registered service paths and handlers (opt-in via RestServerConfig.EnableServices)
 //   - /tfaSetup     - Two-Factor Authentication setup (returns QR code)
 //   - /tfaSetupVerify - TFA verification
 //   - /tfaVerify    - TFA code verification during login
 //   - /captcha      - CAPTCHA challenge generation
 //   - /register     - User registration with CAPTCHA
 //   - /permissions  - Per-type allowed actions for the authenticated user
+//
+// The TFA, CAPTCHA and registration endpoints can be disabled through
+// RestServerConfig, in which case they return 404.
+//
+// All of them answer OPTIONS preflight requests and send CORS headers to the
+// origins in RestServerConfig.AllowedOrigins (see CORS.go).
+//
+// The /healthz and /readyz probes are registered by NewRestServer (see Health.go).
 
 package server
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -103,6 +113,11 @@ func (this *WebService) Activate(sla *ifs.ServiceLevelAgreement, vnic ifs.IVNic)
 	mtx.Lock()
 	defer mtx.Unlock()
 
+	// Adjacent VNics re-activate the same SLA; readiness follows the primary one.
+	if readyVnic == nil {
+		readyVnic = vnic
+	}
+
 	if !registeredAuth {
 		registeredAuth = true
 		if len(sla.Args()) > 1 {
@@ -112,16 +127,21 @@ func (this *WebService) Activate(sla *ifs.ServiceLevelAgreement, vnic ifs.IVNic)
 				proxy.RegisterHandlers(nil)
 			}
 		}
-		http.DefaultServeMux.HandleFunc("/auth", this.Auth)
-		http.DefaultServeMux.HandleFunc("/registry", this.Registry)
-		http.DefaultServeMux.HandleFunc("/tfaSetup", this.TFASetup)
-		http.DefaultServeMux.HandleFunc("/tfaSetupVerify", this.TFAVerify)
-		http.DefaultServeMux.HandleFunc("/tfaVerify", this.TFAVerify)
-		http.DefaultServeMux.HandleFunc("/captcha", this.Captcha)
-		http.DefaultServeMux.HandleFunc("/register", this.Register)
-		http.DefaultServeMux.HandleFunc("/permissions", this.Permissions)
+		origins := this.allowedOrigins()
+		compress := this.gzipEnabled()
+		http.DefaultServeMux.HandleFunc("/auth", withPreflight(origins, http.MethodPost, withGzip(compress, this.Auth)))
+		http.DefaultServeMux.HandleFunc("/registry", withPreflight(origins, http.MethodGet, withGzip(compress, this.Registry)))
+		http.DefaultServeMux.HandleFunc("/services", withPreflight(origins, http.MethodGet, withGzip(compress, this.Services)))
+		http.DefaultServeMux.HandleFunc("/tfaSetup", withPreflight(origins, http.MethodPost, withGzip(compress, this.TFASetup)))
+		http.DefaultServeMux.HandleFunc("/tfaSetupVerify", withPreflight(origins, http.MethodPost, withGzip(compress, this.TFAVerify)))
+		http.DefaultServeMux.HandleFunc("/tfaVerify", withPreflight(origins, http.MethodPost, withGzip(compress, this.TFAVerify)))
+		http.DefaultServeMux.HandleFunc("/captcha", withPreflight(origins, http.MethodGet+", "+http.MethodPost, withGzip(compress, this.Captcha)))
+		http.DefaultServeMux.HandleFunc("/register", withPreflight(origins, http.MethodPost, withGzip(compress, this.Register)))
+		http.DefaultServeMux.HandleFunc("/permissions", withPreflight(origins, http.MethodGet, withGzip(compress, this.Permissions)))
 
 		this.wsManager = NewWebSocketManager(vnic)
+		this.wsManager.cookieName = this.bearerCookieName()
+		this.wsManager.allowQueryToken = this.allowQueryToken()
 		http.DefaultServeMux.HandleFunc("/ws", this.wsManager.HandleUpgrade)
 
 		wsNotifySvc := &WsNotifyService{}
@@ -157,21 +177,22 @@ func (this *WebService) Activate(sla *ifs.ServiceLevelAgreement, vnic ifs.IVNic)
 // On successful authentication, it returns a bearer token and sets an HTTP-only
 // cookie for browser-based clients. Also handles TFA status (needTfa, setupTfa).
 // For cross-VNet setups, it also authenticates with adjacent networks and maps tokens.
+// Failures are written as an ErrorResponse JSON envelope; methods other than
+// POST get 405.
 func (this *WebService) Auth(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
 	data, err := io.ReadAll(r.Body)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte("Failed to read user/pass #1"))
-		w.Write([]byte(err.Error()))
+		writeError(w, http.StatusBadRequest, "Failed to read user/pass #1: "+err.Error())
 		fmt.Println("Failed to read user/pass #1")
 		return
 	}
 	user := &l8api.AuthUser{}
 	err = protojson.Unmarshal(data, user)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte("Failed to read user/pass #2"))
-		w.Write([]byte(err.Error()))
+		writeError(w, http.StatusBadRequest, "Failed to read user/pass #2: "+err.Error())
 		fmt.Println("Failed to read user/pass #2")
 		return
 	}
@@ -181,11 +202,7 @@ func (this *WebService) Auth(w http.ResponseWriter, r *http.Request) {
 		this.faTokens.Delete(user.User)
 		faPending := pending.(*faTokenHash)
 		if faPending.authToken.TokenHash != user.TokenHash {
-			w.WriteHeader(http.StatusUnauthorized)
-			authToken := &l8api.AuthToken{}
-			authToken.Error = "Mismatch Hash"
-			jsn, _ := protojson.Marshal(authToken)
-			w.Write(jsn)
+			writeError(w, http.StatusUnauthorized, "Mismatch Hash")
 			fmt.Println("Failed to authenticate hash #4")
 			return
 		}
@@ -196,11 +213,7 @@ func (this *WebService) Auth(w http.ResponseWriter, r *http.Request) {
 
 	token, faHash, needTFA, setupTFA, portal, err := this.vnic.Resources().Security().Authenticate(user.User, user.Pass, this.vnic)
 	if err != nil {
-		w.WriteHeader(http.StatusUnauthorized)
-		authToken := &l8api.AuthToken{}
-		authToken.Error = err.Error()
-		jsn, _ := protojson.Marshal(authToken)
-		w.Write(jsn)
+		writeError(w, http.StatusUnauthorized, err.Error())
 		this.vnic.Resources().Logger().Warning("Failed to authenticate user/pass #3")
 		return
 	}
@@ -228,7 +241,7 @@ func (this *WebService) Auth(w http.ResponseWriter, r *http.Request) {
 
 	jsn, _ := protojson.Marshal(authToken)
 	http.SetCookie(w, &http.Cookie{
-		Name:     BearerCookieName,
+		Name:     this.bearerCookieName(),
 		Value:    token,
 		Path:     "/",
 		MaxAge:   86400,
@@ -236,10 +249,89 @@ func (this *WebService) Auth(w http.ResponseWriter, r *http.Request) {
 		Secure:   true, // false for local dev without HTTPS
 		SameSite: http.SameSiteStrictMode,
 	})
+	if this.csrfEnabled() {
+		setCSRFCookie(w)
+	}
 	w.WriteHeader(http.StatusOK)
 	w.Write(jsn)
 }
 
+// bearerCookieName returns the auth cookie name of the server the service was
+// activated with, DefaultBearerCookieName if it does not set one.
+func (this *WebService) bearerCookieName() string {
+	rs, ok := this.server.(*RestServer)
+	if ok && rs.BearerCookieName != "" {
+		return rs.BearerCookieName
+	}
+	return DefaultBearerCookieName
+}
+
+// allowQueryToken reports whether the server the service was activated with
+// accepts a bearer token in the "token" query parameter.
+func (this *WebService) allowQueryToken() bool {
+	rs, ok := this.server.(*RestServer)
+	return ok && rs.AllowQueryToken
+}
+
+// csrfEnabled reports whether the server the service was activated with
+// enables CSRF protection.
+func (this *WebService) csrfEnabled() bool {
+	rs, ok := this.server.(*RestServer)
+	return ok && rs.EnableCSRF
+}
+
+// allowedOrigins returns the origins the server the service was activated
+// with allows to call the built-in endpoints.
+func (this *WebService) allowedOrigins() []string {
+	rs, ok := this.server.(*RestServer)
+	if !ok {
+		return nil
+	}
+	return rs.AllowedOrigins
+}
+
+// gzipEnabled reports whether the server the service was activated with
+// compresses responses.
+func (this *WebService) gzipEnabled() bool {
+	rs, ok := this.server.(*RestServer)
+	return ok && rs.EnableGzip
+}
+
+// registryEnabled reports whether the server the service was activated with
+// exposes the /registry endpoint.
+func (this *WebService) registryEnabled() bool {
+	rs, ok := this.server.(*RestServer)
+	return ok && rs.EnableRegistry
+}
+
+// servicesEnabled reports whether the server the service was activated with
+// exposes the /services endpoint.
+func (this *WebService) servicesEnabled() bool {
+	rs, ok := this.server.(*RestServer)
+	return ok && rs.EnableServices
+}
+
+// registrationDisabled reports whether the server the service was activated
+// with disables the /register endpoint.
+func (this *WebService) registrationDisabled() bool {
+	rs, ok := this.server.(*RestServer)
+	return ok && rs.DisableRegistration
+}
+
+// tfaDisabled reports whether the server the service was activated with
+// disables the TFA setup and verification endpoints.
+func (this *WebService) tfaDisabled() bool {
+	rs, ok := this.server.(*RestServer)
+	return ok && rs.DisableTFA
+}
+
+// captchaDisabled reports whether the server the service was activated with
+// disables the /captcha endpoint.
+func (this *WebService) captchaDisabled() bool {
+	rs, ok := this.server.(*RestServer)
+	return ok && rs.DisableCaptcha
+}
+
 // DeActivate performs cleanup when the service is being shut down.
 // Currently a no-op as cleanup is handled elsewhere.
 func (this *WebService) DeActivate() error {
@@ -308,19 +400,23 @@ func (this *WebService) WebService() ifs.IWebService {
 }
 
 // Registry handles requests to the /registry endpoint, returning the type
-// registry as JSON. Requires authentication if globally enabled.
+// registry as JSON. The endpoint exposes internal schema details, so it returns
+// 404 unless RestServerConfig.EnableRegistry is set, and when enabled it always
+// requires a valid bearer token, regardless of the server's Authentication setting.
 func (this *WebService) Registry(w http.ResponseWriter, r *http.Request) {
-	if authEnabled {
-		bearer := r.Header.Get("Authorization")
-		if bearer == "" {
-			w.WriteHeader(http.StatusUnauthorized)
-			return
-		}
-		_, ok := this.vnic.Resources().Security().ValidateToken(bearer, this.vnic)
-		if !ok {
-			w.WriteHeader(http.StatusUnauthorized)
-			return
-		}
+	if !this.registryEnabled() {
+		http.NotFound(w, r)
+		return
+	}
+	bearer := r.Header.Get("Authorization")
+	if bearer == "" {
+		writeError(w, http.StatusUnauthorized, "missing bearer token")
+		return
+	}
+	_, ok := this.vnic.Resources().Security().ValidateToken(bearer, this.vnic)
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "invalid bearer token")
+		return
 	}
 	typeList := this.vnic.Resources().Registry().TypeList()
 	byt, _ := protojson.Marshal(typeList)
@@ -328,21 +424,52 @@ func (this *WebService) Registry(w http.ResponseWriter, r *http.Request) {
 	w.Write(byt)
 }
 
+// Services handles requests to the /services endpoint, returning the paths
+// registered on the server with their service name, area and whether they
+// require authentication, as a JSON array sorted by path. Useful for
+// diagnosing why a request returns 404. Like /registry, it exposes internal
+// details, so it returns 404 unless RestServerConfig.EnableServices is set, and
+// when enabled it always requires a valid bearer token.
+func (this *WebService) Services(w http.ResponseWriter, r *http.Request) {
+	if !this.servicesEnabled() {
+		http.NotFound(w, r)
+		return
+	}
+	bearer := r.Header.Get("Authorization")
+	if bearer == "" {
+		writeError(w, http.StatusUnauthorized, "missing bearer token")
+		return
+	}
+	_, ok := this.vnic.Resources().Security().ValidateToken(bearer, this.vnic)
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "invalid bearer token")
+		return
+	}
+	byt, err := json.Marshal(Routes())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(byt)
+}
+
 // Permissions handles requests to the /permissions endpoint, returning the
 // per-type allowed actions for the authenticated user as JSON.
 // Response format: { "TypeName": [1,2,5], ... } where 1=POST,2=PUT,3=PATCH,4=DELETE,5=GET
 func (this *WebService) Permissions(w http.ResponseWriter, r *http.Request) {
 	bearer := r.Header.Get("Authorization")
 	if bearer == "" {
-		bearer = extractToken(r)
+		bearer = extractToken(r, this.bearerCookieName(), this.allowQueryToken())
 	}
 	if bearer == "" {
-		w.WriteHeader(http.StatusUnauthorized)
+		writeError(w, http.StatusUnauthorized, "missing bearer token")
 		return
 	}
 	aaaid, ok := this.vnic.Resources().Security().ValidateToken(bearer, this.vnic)
 	if !ok {
-		w.WriteHeader(http.StatusUnauthorized)
+		writeError(w, http.StatusUnauthorized, "invalid bearer token")
 		return
 	}
 	actions := this.vnic.Resources().Security().AllowedActions(this.vnic, aaaid)
@@ -377,16 +504,22 @@ func (this *WebService) Permissions(w http.ResponseWriter, r *http.Request) {
 // ValidateBearerToken validates the bearer token from an HTTP request.
 // It first checks the Authorization header, then falls back to extractToken
 // (which checks cookies and query parameters). Returns an error if the token
-// is missing or invalid. This method is used by the reverse proxy for
-// protected endpoint validation.
+// is missing or invalid, or ErrCSRFToken if CSRF protection is enabled and a
+// cookie-authenticated, state-changing request fails the check (see checkCSRF).
+// This method is used by the reverse proxy for protected endpoint validation.
 func (this *WebService) ValidateBearerToken(r *http.Request) error {
 	bearer := r.Header.Get("Authorization")
 	if bearer == "" {
-		bearer = extractToken(r)
+		bearer = extractToken(r, this.bearerCookieName(), this.allowQueryToken())
 	}
 	if bearer == "" {
 		return errors.New("unauthorized")
 	}
+	if this.csrfEnabled() {
+		if err := checkCSRF(r, this.bearerCookieName()); err != nil {
+			return err
+		}
+	}
 	_, ok := this.vnic.Resources().Security().ValidateToken(bearer, this.vnic)
 	if !ok {
 		return errors.New("unauthorized")
@@ -0,0 +1,88 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWebService_RegistryOptIn(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		rs := &RestServer{}
+		rs.EnableRegistry = enabled
+		w := httptest.NewRecorder()
+		(&WebService{server: rs}).Registry(w, httptest.NewRequest(http.MethodGet, "/registry", nil))
+		if !enabled && w.Code != http.StatusNotFound {
+			t.Fatalf("expected 404 for disabled registry, got %d", w.Code)
+		}
+		if enabled && w.Code != http.StatusUnauthorized {
+			t.Fatalf("expected 401 without a token for enabled registry, got %d", w.Code)
+		}
+	}
+}
+
+func TestWebService_ServicesOptIn(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		rs := &RestServer{}
+		rs.EnableServices = enabled
+		w := httptest.NewRecorder()
+		(&WebService{server: rs}).Services(w, httptest.NewRequest(http.MethodGet, "/services", nil))
+		if !enabled && w.Code != http.StatusNotFound {
+			t.Fatalf("expected 404 for disabled services, got %d", w.Code)
+		}
+		if enabled && w.Code != http.StatusUnauthorized {
+			t.Fatalf("expected 401 without a token for enabled services, got %d", w.Code)
+		}
+	}
+}
+
+func TestWebService_DisableEndpointGroups(t *testing.T) {
+	disabled := &RestServer{}
+	disabled.DisableRegistration = true
+	disabled.DisableTFA = true
+	disabled.DisableCaptcha = true
+	ws := &WebService{server: disabled}
+	handlers := map[string]http.HandlerFunc{
+		"/register":       ws.Register,
+		"/tfaSetup":       ws.TFASetup,
+		"/tfaSetupVerify": ws.TFAVerify,
+		"/tfaVerify":      ws.TFAVerify,
+		"/captcha":        ws.Captcha,
+	}
+	for path, handler := range handlers {
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest(http.MethodPost, path, nil))
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("expected 404 for disabled %s, got %d", path, w.Code)
+		}
+	}
+
+	// The settings belong to each server, not to the last one created.
+	enabled := &WebService{server: &RestServer{}}
+	w := httptest.NewRecorder()
+	// An invalid body is rejected before the VNic is needed.
+	enabled.Register(w, httptest.NewRequest(http.MethodPost, "/register", strings.NewReader("{")))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected /register to be enabled by default, got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	ws.Register(w, httptest.NewRequest(http.MethodPost, "/register", strings.NewReader("{")))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected /register to stay disabled on the first server, got %d", w.Code)
+	}
+}
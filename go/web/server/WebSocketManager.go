@@ -41,9 +41,11 @@ func (c *wsConn) writeJSON(data []byte) error {
 
 // WebSocketManager manages WebSocket connections keyed by AAAId (authenticated user identity).
 type WebSocketManager struct {
-	mu          sync.RWMutex
-	connections map[string]*wsConn
-	vnic        ifs.IVNic
+	mu              sync.RWMutex
+	connections     map[string]*wsConn
+	vnic            ifs.IVNic
+	cookieName      string // Auth cookie checked before the Authorization header, see extractToken
+	allowQueryToken bool   // Accept the token in the "token" query parameter
 }
 
 func NewWebSocketManager(vnic ifs.IVNic) *WebSocketManager {
@@ -55,7 +57,7 @@ func NewWebSocketManager(vnic ifs.IVNic) *WebSocketManager {
 
 // HandleUpgrade validates the bearer token, resolves the AAAId, and upgrades to a WebSocket connection.
 func (this *WebSocketManager) HandleUpgrade(w http.ResponseWriter, r *http.Request) {
-	token := extractToken(r)
+	token := extractToken(r, this.cookieName, this.allowQueryToken)
 	if token == "" {
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
@@ -0,0 +1,159 @@
+/*
+ * Copyright (c) 2025 Sharon Aicler (saichler@gmail.com)
+ *
+ * Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+ * You may obtain a copy of the License at:
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package webtest provides helpers for integration tests against the REST
+// server, so tests don't need fixed ports, certificate files or sleeps.
+//
+// Example usage:
+//
+//	srv, restClient, cleanup := webtest.NewTestHarness(t, resources)
+//	defer cleanup()
+//	srv.RegisterHandler("echo", echoHandler)
+//	resp, err := restClient.GET("echo", "MyType", "", "", nil)
+package webtest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8web/go/web/client"
+	"github.com/saichler/l8web/go/web/server"
+)
+
+const (
+	// HarnessPrefix is the server and client URL prefix used by NewTestHarness.
+	HarnessPrefix = "/test/"
+	// ReadyTimeout is how long NewTestHarness waits for the server to answer.
+	ReadyTimeout = 10 * time.Second
+)
+
+// HarnessOption adjusts the server and client configuration before they are
+// created, e.g. to enable authentication or ETags.
+type HarnessOption func(*server.RestServerConfig, *client.RestClientConfig)
+
+// NewTestHarness starts a RestServer over HTTPS on an ephemeral localhost
+// port, with an in-memory self-signed certificate, and a RestClient wired to
+// it using resources for its type registry. It returns once the server answers
+// /healthz. Call the returned cleanup func to stop the server.
+//
+// The server registers its handlers on http.DefaultServeMux, so harnesses
+// must not run in parallel. Readiness here means the HTTP server is up; tests
+// that also connect a VNet should wait for the server's Ready.
+func NewTestHarness(t testing.TB, resources ifs.IResources, options ...HarnessOption) (*server.RestServer, *client.RestClient, func()) {
+	t.Helper()
+	cert, err := selfSignedCertificate()
+	if err != nil {
+		t.Fatalf("webtest: failed to create certificate: %v", err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("webtest: failed to listen: %v", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	serverConfig := &server.RestServerConfig{
+		Host:        "127.0.0.1",
+		Port:        port,
+		Prefix:      HarnessPrefix,
+		Certificate: cert,
+	}
+	clientConfig := &client.RestClientConfig{
+		Host:          "127.0.0.1",
+		Port:          port,
+		Https:         true,
+		Prefix:        HarnessPrefix,
+		AllowInsecure: true, // The harness certificate is self-signed
+		AuthInfo:      &client.RestAuthInfo{},
+	}
+	for _, option := range options {
+		option(serverConfig, clientConfig)
+	}
+
+	srv, err := server.NewRestServer(serverConfig)
+	if err != nil {
+		listener.Close()
+		t.Fatalf("webtest: failed to create server: %v", err)
+	}
+	restServer := srv.(*server.RestServer)
+	go restServer.Serve(listener)
+	cleanup := func() {
+		restServer.Stop()
+	}
+
+	if !waitForHealthz(port) {
+		cleanup()
+		t.Fatalf("webtest: server did not answer /healthz within %v", ReadyTimeout)
+	}
+
+	restClient, err := client.NewRestClient(clientConfig, resources)
+	if err != nil {
+		cleanup()
+		t.Fatalf("webtest: failed to create client: %v", err)
+	}
+	return restServer, restClient, cleanup
+}
+
+// waitForHealthz polls the server's /healthz endpoint until it answers 200.
+func waitForHealthz(port int) bool {
+	httpClient := &http.Client{
+		Timeout:   time.Second,
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+	}
+	defer httpClient.CloseIdleConnections()
+	url := "https://127.0.0.1:" + strconv.Itoa(port) + "/healthz"
+	deadline := time.Now().Add(ReadyTimeout)
+	for time.Now().Before(deadline) {
+		response, err := httpClient.Get(url)
+		if err == nil {
+			response.Body.Close()
+			if response.StatusCode == http.StatusOK {
+				return true
+			}
+		}
+		time.Sleep(time.Millisecond * 20)
+	}
+	return false
+}
+
+// selfSignedCertificate creates a short-lived self-signed certificate for 127.0.0.1.
+func selfSignedCertificate() (*tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "l8web-webtest"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}